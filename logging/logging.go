@@ -0,0 +1,61 @@
+// Package logging defines the logger used by the media packages, so that their output can be routed
+// through the structured logger of the application and filtered by level.
+package logging
+
+import (
+	"fmt"
+	"log"
+)
+
+// Level is the severity of a log message.
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+)
+
+// Logger is implemented by the loggers accepted by the media packages.
+type Logger interface {
+	Debugf(format string, args ...interface{})
+	Infof(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+type stdLogger struct {
+	level Level
+}
+
+// NewStdLogger returns a Logger writing the messages of the given level and above with the standard log package.
+func NewStdLogger(level Level) Logger {
+	return &stdLogger{level: level}
+}
+
+// Default writes every message with the standard log package, as the packages did before loggers were injectable.
+var Default = NewStdLogger(LevelDebug)
+
+func (l *stdLogger) logf(level Level, format string, args []interface{}) {
+	if level < l.level {
+		return
+	}
+	log.Output(3, fmt.Sprintf(format, args...))
+}
+
+func (l *stdLogger) Debugf(format string, args ...interface{}) {
+	l.logf(LevelDebug, format, args)
+}
+
+func (l *stdLogger) Infof(format string, args ...interface{}) {
+	l.logf(LevelInfo, format, args)
+}
+
+func (l *stdLogger) Warnf(format string, args ...interface{}) {
+	l.logf(LevelWarn, format, args)
+}
+
+func (l *stdLogger) Errorf(format string, args ...interface{}) {
+	l.logf(LevelError, format, args)
+}
@@ -1,29 +1,57 @@
 package objectstorage
 
 import (
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/s3"
-	"log"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"io"
+	"net/url"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"sync"
 	"time"
 )
 
+// ErrOverlappingPrefixes is returned when copying or moving objects to a prefix containing or contained in the source prefix.
+var ErrOverlappingPrefixes = errors.New("source and destination prefixes overlap")
+
+// ErrChecksumMismatch is returned when the ETag of an uploaded object doesn't match the checksum of the local file.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
 type ObjectStorage interface {
-	UploadMediaFiles(prefix, localPath string) error
+	UploadMediaFiles(prefix, localPath string, opts ...UploadOption) error
+	SyncMediaFiles(prefix, localPath string) error
 	DeleteMediaFiles(prefix string) error
+	DeleteMediaFilesPrefixes(prefixes []string) error
+	UploadReader(key string, r io.Reader, contentType string) error
+	CopyMediaFiles(srcPrefix, dstPrefix string) error
+	MoveMediaFiles(srcPrefix, dstPrefix string) error
+	DeleteObject(key string) error
+	PrefixSize(prefix string) (totalBytes int64, objectCount int64, err error)
+	PresignPutURL(key string, expiry time.Duration, contentType string) (string, error)
 }
 
 type objectStorage struct {
-	sess   *session.Session
-	bucket string
+	sessLock sync.RWMutex
+	sess     *session.Session
+	bucket   string
+	options  *options
 }
 
-func NewObjectStorage(accessKey, secretKey, endpoint, region, bucket string) (ObjectStorage, error) {
+func NewObjectStorage(accessKey, secretKey, endpoint, region, bucket string, opts ...Option) (ObjectStorage, error) {
+	options := newOptions(opts)
+	if err := options.validateStorageClass(); err != nil {
+		return nil, err
+	}
 	bucketSession, err := session.NewSession(&aws.Config{
 		Region:   aws.String(region),
 		Endpoint: aws.String(endpoint),
@@ -32,42 +60,302 @@ func NewObjectStorage(accessKey, secretKey, endpoint, region, bucket string) (Ob
 			secretKey,
 			"",
 		),
+		S3ForcePathStyle: aws.Bool(options.usePathStyle),
 	})
 	if err != nil {
 		return nil, err
 	}
 	return &objectStorage{
-		sess:   bucketSession,
-		bucket: bucket,
+		sess:    bucketSession,
+		bucket:  bucket,
+		options: options,
 	}, nil
 }
 
-func (o *objectStorage) UploadMediaFiles(prefix, localPath string) error {
-	client := s3.New(o.sess)
-	log.Println("Removing existing files on the bucket on path", prefix)
+func (o *objectStorage) UploadMediaFiles(prefix, localPath string, opts ...UploadOption) error {
+	return o.withRegionFallback(func() error {
+		return o.uploadMediaFiles(prefix, localPath, opts...)
+	})
+}
+
+func (o *objectStorage) uploadMediaFiles(prefix, localPath string, opts ...UploadOption) error {
+	client := o.client()
+	o.options.logger.Infof("Removing existing files on the bucket on path %s", prefix)
 	err := o.deleteDirectoryFromS3(client, prefix)
 	if err != nil {
 		return err
 	}
-	log.Println("Uploading files to the bucket on path", prefix)
-	err = o.uploadDirectoryToS3(client, prefix, localPath)
+	o.options.logger.Infof("Uploading files to the bucket on path %s", prefix)
+	err = o.uploadDirectoryToS3(client, prefix, localPath, newUploadOptions(opts))
 	if err != nil {
 		return err
 	}
-	log.Println("Files uploaded successfully")
+	o.options.logger.Infof("Files uploaded successfully")
 	return nil
 }
 
 func (o *objectStorage) DeleteMediaFiles(prefix string) error {
-	client := s3.New(o.sess)
-	log.Println("Removing existing files on the bucket on path", prefix)
+	return o.withRegionFallback(func() error {
+		return o.deleteMediaFiles(prefix)
+	})
+}
+
+func (o *objectStorage) deleteMediaFiles(prefix string) error {
+	client := o.client()
+	o.options.logger.Infof("Removing existing files on the bucket on path %s", prefix)
 	err := o.deleteDirectoryFromS3(client, prefix)
 	if err != nil {
 		return err
 	}
-	log.Println("Files removed successfully")
+	o.options.logger.Infof("Files removed successfully")
+	return nil
+}
+
+// maxDeleteObjects is the maximum number of keys of a single DeleteObjects request.
+const maxDeleteObjects = 1000
+
+// DeleteMediaFilesPrefixes removes every object under each of the prefixes, e.g. the episodes of a whole show.
+// Prefixes are listed concurrently and their objects are deleted in shared batches of up to 1000 keys.
+// The errors of every prefix and batch are joined into the returned error.
+func (o *objectStorage) DeleteMediaFilesPrefixes(prefixes []string) error {
+	return o.withRegionFallback(func() error {
+		return o.deleteMediaFilesPrefixes(prefixes)
+	})
+}
+
+func (o *objectStorage) deleteMediaFilesPrefixes(prefixes []string) error {
+	client := o.client()
+	o.options.logger.Infof("Removing existing files on the bucket on %d path(s)", len(prefixes))
+
+	pages := make(chan []*s3.ObjectIdentifier)
+	var wg sync.WaitGroup
+	var errLock sync.Mutex
+	var errs []error
+	addErr := func(err error) {
+		errLock.Lock()
+		defer errLock.Unlock()
+		errs = append(errs, err)
+	}
+	sem := make(chan bool, 4) // limit to 4 concurrent listings
+
+	for _, prefix := range prefixes {
+		wg.Add(1)
+		go func(prefix string) {
+			defer wg.Done()
+			sem <- true // block until there's room
+			defer func() { <-sem }()
+			var continuationToken *string
+			for {
+				objects, token, err := o.listObjectsForDeletion(client, prefix, continuationToken)
+				if err != nil {
+					addErr(fmt.Errorf("failed to list %s: %w", prefix, err))
+					return
+				}
+				if len(objects) > 0 {
+					pages <- objects
+				}
+				if token == nil {
+					return
+				}
+				continuationToken = token
+			}
+		}(prefix)
+	}
+	go func() {
+		wg.Wait()
+		close(pages)
+	}()
+
+	var batch []*s3.ObjectIdentifier
+	for objects := range pages {
+		for _, object := range objects {
+			batch = append(batch, object)
+			if len(batch) == maxDeleteObjects {
+				if err := o.deleteObjects(client, batch); err != nil {
+					addErr(err)
+				}
+				batch = nil
+			}
+		}
+	}
+	if len(batch) > 0 {
+		if err := o.deleteObjects(client, batch); err != nil {
+			addErr(err)
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("failed to remove files of %d path(s): %w", len(prefixes), errors.Join(errs...))
+	}
+	o.options.logger.Infof("Files removed successfully")
+	return nil
+}
+
+// DeleteObject removes a single object, leaving the other objects of its directory untouched.
+func (o *objectStorage) DeleteObject(key string) error {
+	return o.withRegionFallback(func() error {
+		return o.deleteObject(key)
+	})
+}
+
+func (o *objectStorage) deleteObject(key string) error {
+	client := o.client()
+	o.options.logger.Debugf("Removing object %s", key)
+	return o.options.retryPolicy.retry(o.options.logger, "remove object "+key, func() error {
+		_, err := client.DeleteObject(&s3.DeleteObjectInput{
+			Bucket: aws.String(o.bucket),
+			Key:    aws.String(key),
+		})
+		return err
+	})
+}
+
+// PrefixSize returns the total size in bytes and the number of the objects under the prefix,
+// e.g. the storage used by a movie or a show.
+func (o *objectStorage) PrefixSize(prefix string) (totalBytes int64, objectCount int64, err error) {
+	err = o.withRegionFallback(func() error {
+		totalBytes, objectCount, err = o.prefixSize(prefix)
+		return err
+	})
+	return totalBytes, objectCount, err
+}
+
+func (o *objectStorage) prefixSize(prefix string) (totalBytes int64, objectCount int64, err error) {
+	client := o.client()
+	var continuationToken *string
+
+	for {
+		resp, err := client.ListObjectsV2(&s3.ListObjectsV2Input{
+			Bucket:            aws.String(o.bucket),
+			Prefix:            aws.String(prefix),
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			o.options.logger.Errorf("Error while listing objects for size in %s", prefix)
+			return 0, 0, err
+		}
+
+		for _, item := range resp.Contents {
+			totalBytes += aws.Int64Value(item.Size)
+			objectCount++
+		}
+
+		if resp.NextContinuationToken == nil {
+			break
+		}
+		continuationToken = resp.NextContinuationToken
+	}
+
+	return totalBytes, objectCount, nil
+}
+
+// PresignPutURL returns a URL letting a client (browser, mobile app) upload an object directly to key with
+// a PUT request until expiry. The signature covers the following headers, which the client must send with
+// these exact values or the upload is rejected:
+//   - Content-Type: contentType, when not empty
+//   - x-amz-acl: public-read, like every object uploaded by this package
+//   - x-amz-storage-class: the class set with WithStorageClass, if any
+func (o *objectStorage) PresignPutURL(key string, expiry time.Duration, contentType string) (string, error) {
+	input := &s3.PutObjectInput{
+		Bucket:       aws.String(o.bucket),
+		Key:          aws.String(key),
+		ACL:          aws.String("public-read"),
+		StorageClass: o.options.storageClassValue(),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	req, _ := o.client().PutObjectRequest(input)
+	presignedURL, err := req.Presign(expiry)
+	if err != nil {
+		o.options.logger.Errorf("Failed to presign upload of %s to bucket %s, error: %s", key, o.bucket, err.Error())
+		return "", err
+	}
+	return presignedURL, nil
+}
+
+// UploadReader streams the content of r to the given key.
+// The reader may be of unknown length, it is uploaded in parts if needed.
+// The headers configured for the extension of the key are applied, as for uploaded files.
+// As the reader cannot be replayed, a region redirect is not retried here even with WithRegionDetection.
+func (o *objectStorage) UploadReader(key string, r io.Reader, contentType string) error {
+	uploader := s3manager.NewUploader(o.session())
+	input := &s3manager.UploadInput{
+		Bucket:       aws.String(o.bucket),
+		Key:          aws.String(key),
+		ACL:          aws.String("public-read"),
+		Body:         r,
+		StorageClass: o.options.storageClassValue(),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	input.CacheControl, input.Metadata = o.objectHeaders(path.Ext(key))
+	_, err := uploader.Upload(input)
+	if err != nil {
+		o.options.logger.Errorf("Failed to upload %s to bucket %s, error: %s", key, o.bucket, err.Error())
+		return err
+	}
 	return nil
 }
+
+// CopyMediaFiles copies server-side every object under srcPrefix to dstPrefix.
+// The prefixes must not overlap, otherwise ErrOverlappingPrefixes is returned.
+func (o *objectStorage) CopyMediaFiles(srcPrefix, dstPrefix string) error {
+	if err := checkDistinctPrefixes(srcPrefix, dstPrefix); err != nil {
+		return err
+	}
+	return o.withRegionFallback(func() error {
+		return o.copyMediaFiles(srcPrefix, dstPrefix)
+	})
+}
+
+func (o *objectStorage) copyMediaFiles(srcPrefix, dstPrefix string) error {
+	client := o.client()
+	o.options.logger.Infof("Copying files from %s to %s", srcPrefix, dstPrefix)
+	err := o.copyDirectoryInS3(client, srcPrefix, dstPrefix)
+	if err != nil {
+		return err
+	}
+	o.options.logger.Infof("Files copied successfully")
+	return nil
+}
+
+// MoveMediaFiles copies every object under srcPrefix to dstPrefix then removes the source objects.
+// The prefixes must not overlap, otherwise ErrOverlappingPrefixes is returned: removing the source
+// would also remove the copies.
+func (o *objectStorage) MoveMediaFiles(srcPrefix, dstPrefix string) error {
+	if err := checkDistinctPrefixes(srcPrefix, dstPrefix); err != nil {
+		return err
+	}
+	return o.withRegionFallback(func() error {
+		return o.moveMediaFiles(srcPrefix, dstPrefix)
+	})
+}
+
+func (o *objectStorage) moveMediaFiles(srcPrefix, dstPrefix string) error {
+	client := o.client()
+	o.options.logger.Infof("Moving files from %s to %s", srcPrefix, dstPrefix)
+	err := o.copyDirectoryInS3(client, srcPrefix, dstPrefix)
+	if err != nil {
+		return err
+	}
+	err = o.deleteDirectoryFromS3(client, srcPrefix)
+	if err != nil {
+		return err
+	}
+	o.options.logger.Infof("Files moved successfully")
+	return nil
+}
+
+// checkDistinctPrefixes returns ErrOverlappingPrefixes when one prefix lists the objects of the other.
+func checkDistinctPrefixes(srcPrefix, dstPrefix string) error {
+	if strings.HasPrefix(dstPrefix, srcPrefix) || strings.HasPrefix(srcPrefix, dstPrefix) {
+		return fmt.Errorf("%w: %q and %q", ErrOverlappingPrefixes, srcPrefix, dstPrefix)
+	}
+	return nil
+}
+
 func (o *objectStorage) deleteDirectoryFromS3(client *s3.S3, prefix string) error {
 	var continuationToken *string
 
@@ -101,7 +389,7 @@ func (o *objectStorage) listObjectsForDeletion(client *s3.S3, prefix string, con
 		ContinuationToken: continuationToken,
 	})
 	if err != nil {
-		log.Println("Error while listing objects for deletion in", prefix)
+		o.options.logger.Errorf("Error while listing objects for deletion in %s", prefix)
 		return nil, nil, err
 	}
 
@@ -114,90 +402,224 @@ func (o *objectStorage) listObjectsForDeletion(client *s3.S3, prefix string, con
 }
 
 func (o *objectStorage) deleteObjects(client *s3.S3, objects []*s3.ObjectIdentifier) error {
-	var err error
-	for i := 0; i < 3; i++ {
-		_, err = client.DeleteObjects(&s3.DeleteObjectsInput{
+	return o.options.retryPolicy.retry(o.options.logger, "remove objects", func() error {
+		_, err := client.DeleteObjects(&s3.DeleteObjectsInput{
 			Bucket: aws.String(o.bucket),
 			Delete: &s3.Delete{
 				Objects: objects,
 				Quiet:   aws.Bool(true),
 			},
 		})
+		return err
+	})
+}
 
+func (o *objectStorage) copyDirectoryInS3(client *s3.S3, srcPrefix, dstPrefix string) error {
+	var continuationToken *string
+	var wg sync.WaitGroup
+	var errLock sync.Mutex
+	var copyErr error
+	sem := make(chan bool, 4) // limit to 4 concurrent goroutines
+
+	for {
+		resp, err := client.ListObjectsV2(&s3.ListObjectsV2Input{
+			Bucket:            aws.String(o.bucket),
+			Prefix:            aws.String(srcPrefix),
+			ContinuationToken: continuationToken,
+		})
 		if err != nil {
-			log.Printf("Error while removing objects, error: %s\nRetrying...", err.Error())
-			time.Sleep(1 * time.Second) // wait for 1 second before next attempt
-		} else {
-			return nil
+			o.options.logger.Errorf("Error while listing objects for copy in %s", srcPrefix)
+			wg.Wait()
+			return err
 		}
+
+		for _, item := range resp.Contents {
+			srcKey := aws.StringValue(item.Key)
+			dstKey := dstPrefix + strings.TrimPrefix(srcKey, srcPrefix)
+			wg.Add(1)
+			go func(srcKey, dstKey string) {
+				defer wg.Done()
+				sem <- true // block until there's room
+				defer func() { <-sem }()
+				if err := o.copyObject(client, srcKey, dstKey); err != nil {
+					errLock.Lock()
+					defer errLock.Unlock()
+					copyErr = err
+				}
+			}(srcKey, dstKey)
+		}
+
+		if resp.NextContinuationToken == nil {
+			break
+		}
+		continuationToken = resp.NextContinuationToken
 	}
 
-	log.Println("Failed to delete objects after 3 attempts")
-	return err
+	wg.Wait()
+	return copyErr
 }
 
-func (o *objectStorage) uploadFileToS3(client *s3.S3, prefix, filePath string, wg *sync.WaitGroup, sem chan bool) {
-	defer wg.Done()
+func (o *objectStorage) copyObject(client *s3.S3, srcKey, dstKey string) error {
+	return o.options.retryPolicy.retry(o.options.logger, "copy "+srcKey+" to "+dstKey, func() error {
+		_, err := client.CopyObject(&s3.CopyObjectInput{
+			Bucket:            aws.String(o.bucket),
+			Key:               aws.String(dstKey),
+			CopySource:        aws.String(url.PathEscape(o.bucket + "/" + srcKey)),
+			ACL:               aws.String("public-read"),
+			MetadataDirective: aws.String(s3.MetadataDirectiveCopy),
+			StorageClass:      o.options.storageClassValue(),
+		})
+		return err
+	})
+}
 
+func (o *objectStorage) uploadFileToS3(client *s3.S3, prefix, filePath string, sem chan bool) error {
 	sem <- true // block until there's room
 	defer func() { <-sem }()
 
 	file, err := os.Open(filePath)
 	if err != nil {
-		log.Println("Failed to open file", filePath)
-		return
+		o.options.logger.Errorf("Failed to open file %s", filePath)
+		return fmt.Errorf("failed to open file %s: %w", filePath, err)
 	}
 	defer file.Close()
 
 	_, filename := filepath.Split(filePath)
 	key := filepath.Join(prefix, filename)
 
-	var success bool
-	for i := 0; i < 3; i++ {
-		_, err = client.PutObject(&s3.PutObjectInput{
-			Bucket: aws.String(o.bucket),
-			Key:    aws.String(key),
-			ACL:    aws.String("public-read"),
-			Body:   file,
-		})
+	checksum, err := fileMD5(file)
+	if err != nil {
+		return fmt.Errorf("failed to compute checksum of %s: %w", filePath, err)
+	}
 
-		if err != nil {
-			log.Printf("Failed to upload %s to bucket %s, error: %s\nRetrying...", key, o.bucket, err.Error())
-			time.Sleep(1 * time.Second) // wait for 1 second before next attempt
-		} else {
-			//log.Printf("File %s uploaded successfully", key)
-			success = true
-			break
+	input := &s3.PutObjectInput{
+		Bucket:       aws.String(o.bucket),
+		Key:          aws.String(key),
+		ACL:          aws.String("public-read"),
+		Body:         file,
+		ContentMD5:   aws.String(base64.StdEncoding.EncodeToString(checksum)),
+		StorageClass: o.options.storageClassValue(),
+	}
+	o.applyHeaders(input, filepath.Ext(filename))
+
+	err = o.options.retryPolicy.retry(o.options.logger, "upload "+key+" to bucket "+o.bucket, func() error {
+		if _, err := file.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		output, err := client.PutObject(input)
+		if err == nil && o.options.verifyETag {
+			err = verifyETag(key, output.ETag, checksum)
 		}
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload %s: %w", key, err)
+	}
+	return nil
+}
+
+// fileMD5 computes the MD5 checksum of the whole file.
+func fileMD5(file *os.File) ([]byte, error) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	hash := md5.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
+
+// verifyETag checks that the ETag returned by S3 for a single part upload matches the MD5 checksum of the file.
+func verifyETag(key string, etag *string, checksum []byte) error {
+	expected := hex.EncodeToString(checksum)
+	actual := strings.Trim(aws.StringValue(etag), `"`)
+	if actual != expected {
+		return fmt.Errorf("%w for %s: expected %s, got %s", ErrChecksumMismatch, key, expected, actual)
 	}
+	return nil
+}
 
-	if !success {
-		log.Printf("Failed to upload %s to bucket %s after 3 attempts", key, o.bucket)
+// applyHeaders sets the cache headers and metadata configured for the file extension on the upload input.
+func (o *objectStorage) applyHeaders(input *s3.PutObjectInput, extension string) {
+	input.CacheControl, input.Metadata = o.objectHeaders(extension)
+}
+
+// objectHeaders returns the Cache-Control header and the metadata configured for the file extension,
+// nil when none is set.
+func (o *objectStorage) objectHeaders(extension string) (cacheControl *string, metadata map[string]*string) {
+	if o.options.headersFunc == nil {
+		return nil, nil
+	}
+	headers := o.options.headersFunc(extension)
+	if headers.CacheControl != "" {
+		cacheControl = aws.String(headers.CacheControl)
 	}
+	if len(headers.Metadata) > 0 {
+		metadata = aws.StringMap(headers.Metadata)
+	}
+	return cacheControl, metadata
 }
 
-func (o *objectStorage) uploadDirectoryToS3(client *s3.S3, prefix, localPath string) error {
+func (o *objectStorage) uploadDirectoryToS3(client *s3.S3, prefix, localPath string, uploadOptions *uploadOptions) error {
 	var wg sync.WaitGroup
+	var errLock sync.Mutex
+	var uploadErrs []error
+	var progressLock sync.Mutex
+	var progress UploadProgress
 	sem := make(chan bool, 4) // limit to 4 concurrent goroutines
-	log.Println("Uploading files from", localPath, "to", prefix)
+
+	var files []string
 	err := filepath.WalkDir(localPath, func(path string, d os.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 		if !d.IsDir() {
-			wg.Add(1)
-			go func(path string) {
-				o.uploadFileToS3(client, prefix, path, &wg, sem)
-			}(path)
+			info, err := d.Info()
+			if err != nil {
+				return err
+			}
+			files = append(files, path)
+			progress.TotalBytes += info.Size()
 		}
 		return nil
 	})
-
 	if err != nil {
 		return fmt.Errorf("failed to walk directory %s, error: %s", localPath, err.Error())
 	}
+	progress.TotalFiles = len(files)
+
+	o.options.logger.Debugf("Uploading files from %s to %s", localPath, prefix)
+	for _, path := range files {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			err := o.uploadFileToS3(client, prefix, path, sem)
+			if err != nil {
+				errLock.Lock()
+				uploadErrs = append(uploadErrs, err)
+				errLock.Unlock()
+			}
+			if uploadOptions.progressFunc == nil {
+				return
+			}
+			progressLock.Lock()
+			defer progressLock.Unlock()
+			progress.FilesCompleted++
+			if err == nil {
+				if info, statErr := os.Stat(path); statErr == nil {
+					progress.BytesTransferred += info.Size()
+				}
+			}
+			uploadOptions.progressFunc(progress)
+		}(path)
+	}
 
 	wg.Wait()
 
+	if len(uploadErrs) > 0 {
+		return fmt.Errorf("failed to upload %d file(s) from %s: %w", len(uploadErrs), localPath, errors.Join(uploadErrs...))
+	}
+
 	return nil
 }
@@ -0,0 +1,774 @@
+package objectstorage
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const testBucket = "bucket"
+
+// fakeObject is an object stored by fakeS3, with the headers it was uploaded with.
+type fakeObject struct {
+	data   []byte
+	header http.Header
+}
+
+// fakeS3 is a minimal in-memory S3 server answering the path-style requests made by the object storage.
+// Uploads of the keys in failKeys, and listings of the prefixes in failKeys, always fail. The bodies of the keys in corruptKeys are altered
+// on arrival, and the keys in wrongETagKeys are stored but answered with a wrong ETag.
+// puts records the uploaded keys and deleteBatches the number of keys of every DeleteObjects request. Listings return at most
+// pageSize keys per page when it is set. When region is set, the bucket lives in that region: requests
+// signed for another region are redirected, and counted in redirects.
+type fakeS3 struct {
+	lock          sync.Mutex
+	objects       map[string]*fakeObject
+	failKeys      map[string]bool
+	corruptKeys   map[string]bool
+	wrongETagKeys map[string]bool
+	puts          []string
+	deleteBatches []int
+	pageSize      int
+	region        string
+	redirects     int
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{
+		objects:       make(map[string]*fakeObject),
+		failKeys:      make(map[string]bool),
+		corruptKeys:   make(map[string]bool),
+		wrongETagKeys: make(map[string]bool),
+	}
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/"+testBucket), "/")
+	if f.region != "" && !strings.Contains(r.Header.Get("Authorization"), "/"+f.region+"/s3/") {
+		w.Header().Set("X-Amz-Bucket-Region", f.region)
+		// The bucket region lookup is anonymous.
+		if r.Method == http.MethodHead && key == "" {
+			return
+		}
+		f.redirects++
+		w.WriteHeader(http.StatusMovedPermanently)
+		fmt.Fprint(w, "<Error><Code>PermanentRedirect</Code><Message>The bucket is in another region.</Message></Error>")
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && key == "":
+		f.listObjects(w, r.URL.Query().Get("prefix"), r.URL.Query().Get("continuation-token"))
+	case r.Method == http.MethodPost && r.URL.Query().Has("delete"):
+		f.deleteObjects(w, r)
+	case r.Method == http.MethodPut && r.Header.Get("X-Amz-Copy-Source") != "":
+		f.copyObject(w, r, key)
+	case r.Method == http.MethodPut && key != "":
+		f.putObject(w, r, key)
+	case r.Method == http.MethodDelete && key != "":
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "unsupported request", http.StatusNotImplemented)
+	}
+}
+
+// listedObject is an entry of a ListObjectsV2 response.
+type listedObject struct {
+	Key  string
+	Size int64
+	ETag string
+}
+
+// listObjects lists the objects under prefix, sorted by key, starting after the key given as continuation token.
+func (f *fakeS3) listObjects(w http.ResponseWriter, prefix, continuationToken string) {
+	if f.failKeys[prefix] {
+		http.Error(w, "injected failure", http.StatusInternalServerError)
+		return
+	}
+	result := struct {
+		XMLName               xml.Name `xml:"ListBucketResult"`
+		Contents              []listedObject
+		IsTruncated           bool
+		NextContinuationToken string `xml:",omitempty"`
+	}{}
+	for key, object := range f.objects {
+		if strings.HasPrefix(key, prefix) && key > continuationToken {
+			result.Contents = append(result.Contents, listedObject{Key: key, Size: int64(len(object.data)), ETag: etag(object.data)})
+		}
+	}
+	sort.Slice(result.Contents, func(i, j int) bool { return result.Contents[i].Key < result.Contents[j].Key })
+	if f.pageSize > 0 && len(result.Contents) > f.pageSize {
+		result.Contents = result.Contents[:f.pageSize]
+		result.IsTruncated = true
+		result.NextContinuationToken = result.Contents[f.pageSize-1].Key
+	}
+	xml.NewEncoder(w).Encode(result)
+}
+
+func (f *fakeS3) deleteObjects(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Objects []struct {
+			Key string
+		} `xml:"Object"`
+	}
+	if err := xml.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for _, object := range request.Objects {
+		delete(f.objects, object.Key)
+	}
+	f.deleteBatches = append(f.deleteBatches, len(request.Objects))
+	fmt.Fprint(w, "<DeleteResult></DeleteResult>")
+}
+
+func (f *fakeS3) putObject(w http.ResponseWriter, r *http.Request, key string) {
+	if f.failKeys[key] {
+		http.Error(w, "injected failure", http.StatusInternalServerError)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if f.corruptKeys[key] && len(data) > 0 {
+		data[0] ^= 0xFF
+	}
+	if contentMD5 := r.Header.Get("Content-MD5"); contentMD5 != "" {
+		checksum := md5.Sum(data)
+		if contentMD5 != base64.StdEncoding.EncodeToString(checksum[:]) {
+			http.Error(w, "BadDigest", http.StatusBadRequest)
+			return
+		}
+	}
+	f.objects[key] = &fakeObject{data: data, header: r.Header.Clone()}
+	f.puts = append(f.puts, key)
+	if f.wrongETagKeys[key] {
+		w.Header().Set("ETag", etag(append([]byte("wrong"), data...)))
+		return
+	}
+	w.Header().Set("ETag", etag(data))
+}
+
+// copyObject copies an object. Its content and headers are kept, the ACL and the storage class are the ones
+// of the request.
+func (f *fakeS3) copyObject(w http.ResponseWriter, r *http.Request, key string) {
+	source, err := url.PathUnescape(r.Header.Get("X-Amz-Copy-Source"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	object, ok := f.objects[strings.TrimPrefix(strings.TrimPrefix(source, "/"), testBucket+"/")]
+	if !ok {
+		http.Error(w, "no such key", http.StatusNotFound)
+		return
+	}
+	header := object.header.Clone()
+	header.Set("X-Amz-Acl", r.Header.Get("X-Amz-Acl"))
+	header.Set("X-Amz-Storage-Class", r.Header.Get("X-Amz-Storage-Class"))
+	f.objects[key] = &fakeObject{data: object.data, header: header}
+	fmt.Fprintf(w, "<CopyObjectResult><ETag>%s</ETag></CopyObjectResult>", etag(object.data))
+}
+
+// object returns the object stored under key, or nil.
+func (f *fakeS3) object(key string) *fakeObject {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.objects[key]
+}
+
+// keys returns the keys of the stored objects, sorted.
+func (f *fakeS3) keys() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	var keys []string
+	for key := range f.objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// uploadedKeys returns the keys uploaded so far, sorted.
+func (f *fakeS3) uploadedKeys() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	keys := append([]string(nil), f.puts...)
+	sort.Strings(keys)
+	return keys
+}
+
+func etag(data []byte) string {
+	checksum := md5.Sum(data)
+	return `"` + hex.EncodeToString(checksum[:]) + `"`
+}
+
+// newTestObjectStorage starts a fake S3 server and returns an object storage using it, retrying only once.
+func newTestObjectStorage(t *testing.T, opts ...Option) (*objectStorage, *fakeS3) {
+	t.Helper()
+	fake := newFakeS3()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	opts = append([]Option{
+		WithPathStyle(true),
+		WithRetryPolicy(RetryPolicy{MaxAttempts: 2, BaseDelay: time.Millisecond}),
+	}, opts...)
+	storage, err := NewObjectStorage("access", "secret", server.URL, "us-east-1", testBucket, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return storage.(*objectStorage), fake
+}
+
+func TestUploadReader(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	var manifest bytes.Buffer
+	manifest.WriteString(`{"video_index":"index.m3u8"}`)
+
+	if err := storage.UploadReader("42/response.json", &manifest, "application/json"); err != nil {
+		t.Fatalf("UploadReader() = %v", err)
+	}
+
+	object := fake.object("42/response.json")
+	if object == nil {
+		t.Fatalf("object not uploaded, bucket holds %v", fake.keys())
+	}
+	if string(object.data) != `{"video_index":"index.m3u8"}` {
+		t.Errorf("uploaded content = %q", object.data)
+	}
+	if got := object.header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	if got := object.header.Get("X-Amz-Acl"); got != "public-read" {
+		t.Errorf("ACL = %q, want public-read", got)
+	}
+	if got := object.header.Get("Cache-Control"); got != "no-cache" {
+		t.Errorf("Cache-Control = %q, want the default headers of .json", got)
+	}
+}
+
+// writeFiles creates the named files in a new directory, each containing its own name.
+func writeFiles(t *testing.T, names ...string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestUploadMediaFilesHeadersPerExtension(t *testing.T) {
+	dir := writeFiles(t, "index.m3u8", "segment_000.ts", "subtitle_2.vtt", "poster.jpg")
+
+	t.Run("default", func(t *testing.T) {
+		storage, fake := newTestObjectStorage(t)
+		if err := storage.UploadMediaFiles("42", dir); err != nil {
+			t.Fatalf("UploadMediaFiles() = %v", err)
+		}
+		want := map[string]string{
+			"42/index.m3u8":     "no-cache",
+			"42/segment_000.ts": "public, max-age=31536000, immutable",
+			"42/subtitle_2.vtt": "no-cache",
+			"42/poster.jpg":     "",
+		}
+		for key, cacheControl := range want {
+			object := fake.object(key)
+			if object == nil {
+				t.Fatalf("%s not uploaded, bucket holds %v", key, fake.keys())
+			}
+			if got := object.header.Get("Cache-Control"); got != cacheControl {
+				t.Errorf("%s Cache-Control = %q, want %q", key, got, cacheControl)
+			}
+		}
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		storage, fake := newTestObjectStorage(t, WithHeadersFunc(func(extension string) ObjectHeaders {
+			return ObjectHeaders{CacheControl: "max-age=60", Metadata: map[string]string{"extension": extension}}
+		}))
+		if err := storage.UploadMediaFiles("42", dir); err != nil {
+			t.Fatalf("UploadMediaFiles() = %v", err)
+		}
+		if err := storage.UploadReader("42/manifest.json", strings.NewReader("{}"), "application/json"); err != nil {
+			t.Fatalf("UploadReader() = %v", err)
+		}
+		for key, extension := range map[string]string{"42/segment_000.ts": ".ts", "42/poster.jpg": ".jpg", "42/manifest.json": ".json"} {
+			object := fake.object(key)
+			if object == nil {
+				t.Fatalf("%s not uploaded, bucket holds %v", key, fake.keys())
+			}
+			if got := object.header.Get("Cache-Control"); got != "max-age=60" {
+				t.Errorf("%s Cache-Control = %q, want max-age=60", key, got)
+			}
+			if got := object.header.Get("X-Amz-Meta-Extension"); got != extension {
+				t.Errorf("%s extension metadata = %q, want %q", key, got, extension)
+			}
+		}
+	})
+}
+
+// putTestObject stores an object directly in the fake bucket.
+func (f *fakeS3) putTestObject(key, content, contentType, acl string) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	header := http.Header{}
+	header.Set("Content-Type", contentType)
+	header.Set("X-Amz-Acl", acl)
+	f.objects[key] = &fakeObject{data: []byte(content), header: header}
+}
+
+func TestCopyAndMoveMediaFiles(t *testing.T) {
+	for _, move := range []bool{false, true} {
+		t.Run(fmt.Sprintf("move=%v", move), func(t *testing.T) {
+			storage, fake := newTestObjectStorage(t)
+			fake.putTestObject("staging/42/index.m3u8", "playlist", "application/vnd.apple.mpegurl", "private")
+			fake.putTestObject("staging/42/segment_000.ts", "segment", "video/mp2t", "private")
+			fake.putTestObject("staging/43/index.m3u8", "other", "application/vnd.apple.mpegurl", "private")
+
+			var err error
+			if move {
+				err = storage.MoveMediaFiles("staging/42/", "prod/42/")
+			} else {
+				err = storage.CopyMediaFiles("staging/42/", "prod/42/")
+			}
+			if err != nil {
+				t.Fatalf("copy = %v", err)
+			}
+
+			for key, want := range map[string]string{"index.m3u8": "application/vnd.apple.mpegurl", "segment_000.ts": "video/mp2t"} {
+				object := fake.object("prod/42/" + key)
+				if object == nil {
+					t.Fatalf("%s not copied, bucket holds %v", key, fake.keys())
+				}
+				if got := object.header.Get("Content-Type"); got != want {
+					t.Errorf("%s Content-Type = %q, want %q", key, got, want)
+				}
+				if got := object.header.Get("X-Amz-Acl"); got != "public-read" {
+					t.Errorf("%s ACL = %q, want public-read", key, got)
+				}
+				if source := fake.object("staging/42/" + key); (source == nil) != move {
+					t.Errorf("%s source present = %v after move=%v", key, source != nil, move)
+				}
+			}
+			if fake.object("staging/43/index.m3u8") == nil {
+				t.Error("object outside the source prefix removed")
+			}
+		})
+	}
+}
+
+func TestCopyMediaFilesOverlappingPrefixes(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	fake.putTestObject("a/index.m3u8", "playlist", "application/vnd.apple.mpegurl", "public-read")
+
+	for _, prefixes := range [][2]string{{"a/", "a/prod/"}, {"a/prod/", "a/"}, {"a/", "a/"}} {
+		if err := storage.MoveMediaFiles(prefixes[0], prefixes[1]); !errors.Is(err, ErrOverlappingPrefixes) {
+			t.Errorf("MoveMediaFiles(%q, %q) = %v, want ErrOverlappingPrefixes", prefixes[0], prefixes[1], err)
+		}
+		if err := storage.CopyMediaFiles(prefixes[0], prefixes[1]); !errors.Is(err, ErrOverlappingPrefixes) {
+			t.Errorf("CopyMediaFiles(%q, %q) = %v, want ErrOverlappingPrefixes", prefixes[0], prefixes[1], err)
+		}
+	}
+	if keys := fake.keys(); !reflect.DeepEqual(keys, []string{"a/index.m3u8"}) {
+		t.Errorf("bucket holds %v, want it untouched", keys)
+	}
+}
+
+func TestUploadMediaFilesReportsFailedUploads(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	fake.failKeys["42/segment_001.ts"] = true
+	dir := writeFiles(t, "index.m3u8", "segment_000.ts", "segment_001.ts", "segment_002.ts")
+
+	err := storage.UploadMediaFiles("42", dir)
+	if err == nil || !strings.Contains(err.Error(), "42/segment_001.ts") {
+		t.Fatalf("UploadMediaFiles() = %v, want an error naming the failed segment", err)
+	}
+	want := []string{"42/index.m3u8", "42/segment_000.ts", "42/segment_002.ts"}
+	if keys := fake.keys(); !reflect.DeepEqual(keys, want) {
+		t.Errorf("bucket holds %v, want %v", keys, want)
+	}
+}
+
+func TestUploadMediaFilesSendsContentMD5(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	dir := writeFiles(t, "index.m3u8", "segment_000.ts")
+	if err := storage.UploadMediaFiles("42", dir); err != nil {
+		t.Fatalf("UploadMediaFiles() = %v", err)
+	}
+	checksum := md5.Sum([]byte("segment_000.ts"))
+	if got := fake.object("42/segment_000.ts").header.Get("Content-MD5"); got != base64.StdEncoding.EncodeToString(checksum[:]) {
+		t.Errorf("Content-MD5 = %q, want the MD5 of the file", got)
+	}
+
+	fake.corruptKeys["43/segment_000.ts"] = true
+	err := storage.UploadMediaFiles("43", dir)
+	if err == nil || !strings.Contains(err.Error(), "43/segment_000.ts") {
+		t.Fatalf("UploadMediaFiles() = %v, want the altered segment rejected", err)
+	}
+	if fake.object("43/segment_000.ts") != nil {
+		t.Error("altered segment stored")
+	}
+}
+
+func TestUploadMediaFilesETagVerification(t *testing.T) {
+	dir := writeFiles(t, "index.m3u8", "segment_000.ts")
+
+	storage, fake := newTestObjectStorage(t, WithETagVerification())
+	fake.wrongETagKeys["42/segment_000.ts"] = true
+	if err := storage.UploadMediaFiles("42", dir); !errors.Is(err, ErrChecksumMismatch) {
+		t.Errorf("UploadMediaFiles() = %v, want ErrChecksumMismatch", err)
+	}
+
+	storage, fake = newTestObjectStorage(t)
+	fake.wrongETagKeys["42/segment_000.ts"] = true
+	if err := storage.UploadMediaFiles("42", dir); err != nil {
+		t.Errorf("UploadMediaFiles() without verification = %v", err)
+	}
+}
+
+func TestWithPathStyle(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []Option
+		wantPathStyle bool
+		wantHost      string
+		wantPath      string
+	}{
+		{name: "default", wantHost: "bucket.minio.local:9000", wantPath: "/42/index.m3u8"},
+		{name: "path style", opts: []Option{WithPathStyle(true)}, wantPathStyle: true, wantHost: "minio.local:9000", wantPath: "/bucket/42/index.m3u8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, err := NewObjectStorage("access", "secret", "http://minio.local:9000", "us-east-1", testBucket, tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sess := storage.(*objectStorage).sess
+			if got := aws.BoolValue(sess.Config.S3ForcePathStyle); got != tt.wantPathStyle {
+				t.Errorf("S3ForcePathStyle = %v, want %v", got, tt.wantPathStyle)
+			}
+			request, _ := s3.New(sess).PutObjectRequest(&s3.PutObjectInput{Bucket: aws.String(testBucket), Key: aws.String("42/index.m3u8")})
+			if err := request.Build(); err != nil {
+				t.Fatal(err)
+			}
+			if url := request.HTTPRequest.URL; url.Host != tt.wantHost || url.Path != tt.wantPath {
+				t.Errorf("request URL = %s, want host %s and path %s", url, tt.wantHost, tt.wantPath)
+			}
+		})
+	}
+}
+
+func segmentNames(count int) []string {
+	names := []string{"index.m3u8"}
+	for i := 0; i < count; i++ {
+		names = append(names, fmt.Sprintf("segment_%03d.ts", i))
+	}
+	return names
+}
+
+func TestUploadMediaFilesProgress(t *testing.T) {
+	storage, _ := newTestObjectStorage(t)
+	names := segmentNames(30)
+	var totalBytes int64
+	for _, name := range names {
+		totalBytes += int64(len(name))
+	}
+
+	var calls []UploadProgress
+	var lock sync.Mutex
+	err := storage.UploadMediaFiles("movie", writeFiles(t, names...), WithProgress(func(progress UploadProgress) {
+		lock.Lock()
+		defer lock.Unlock()
+		calls = append(calls, progress)
+	}))
+	if err != nil {
+		t.Fatalf("UploadMediaFiles() = %v", err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+	if len(calls) != len(names) {
+		t.Fatalf("progress reported %d times before UploadMediaFiles returned, want %d", len(calls), len(names))
+	}
+	for i, progress := range calls {
+		if progress.FilesCompleted != i+1 {
+			t.Errorf("call %d reports %d files completed, want %d", i, progress.FilesCompleted, i+1)
+		}
+	}
+	last := calls[len(calls)-1]
+	want := UploadProgress{FilesCompleted: len(names), TotalFiles: len(names), BytesTransferred: totalBytes, TotalBytes: totalBytes}
+	if last != want {
+		t.Errorf("last progress = %+v, want %+v", last, want)
+	}
+}
+
+func TestDeleteObject(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	fake.putTestObject("42/index.m3u8", "playlist", "application/vnd.apple.mpegurl", "public-read")
+	fake.putTestObject("42/response.json", "{}", "application/json", "public-read")
+	fake.putTestObject("42/segment_000.ts", "segment", "video/mp2t", "public-read")
+
+	if err := storage.DeleteObject("42/response.json"); err != nil {
+		t.Fatalf("DeleteObject() = %v", err)
+	}
+
+	want := []string{"42/index.m3u8", "42/segment_000.ts"}
+	if keys := fake.keys(); !reflect.DeepEqual(keys, want) {
+		t.Errorf("bucket holds %v, want %v", keys, want)
+	}
+}
+
+func TestDeleteMediaFilesPrefixes(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	for _, prefix := range []string{"tv/1/", "tv/2/", "tv/3/"} {
+		for i := 0; i < 400; i++ {
+			fake.putTestObject(fmt.Sprintf("%ssegment_%03d.ts", prefix, i), "segment", "video/mp2t", "public-read")
+		}
+	}
+	fake.putTestObject("tv/10/index.m3u8", "playlist", "application/vnd.apple.mpegurl", "public-read")
+
+	if err := storage.DeleteMediaFilesPrefixes([]string{"tv/1/", "tv/2/", "tv/3/", "tv/4/"}); err != nil {
+		t.Fatalf("DeleteMediaFilesPrefixes() = %v", err)
+	}
+
+	if want := []string{"tv/10/index.m3u8"}; !reflect.DeepEqual(fake.keys(), want) {
+		t.Errorf("bucket holds %v, want %v", fake.keys(), want)
+	}
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+	// The 1200 keys share batches across prefixes.
+	if want := []int{maxDeleteObjects, 200}; !reflect.DeepEqual(fake.deleteBatches, want) {
+		t.Errorf("DeleteObjects batches = %v, want %v", fake.deleteBatches, want)
+	}
+}
+
+func TestDeleteMediaFilesPrefixesJoinsErrors(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	fake.putTestObject("tv/1/index.m3u8", "playlist", "application/vnd.apple.mpegurl", "public-read")
+	fake.putTestObject("tv/2/index.m3u8", "playlist", "application/vnd.apple.mpegurl", "public-read")
+	fake.putTestObject("tv/3/index.m3u8", "playlist", "application/vnd.apple.mpegurl", "public-read")
+	fake.failKeys["tv/1/"] = true
+	fake.failKeys["tv/3/"] = true
+
+	err := storage.DeleteMediaFilesPrefixes([]string{"tv/1/", "tv/2/", "tv/3/"})
+	if err == nil || !strings.Contains(err.Error(), "tv/1/") || !strings.Contains(err.Error(), "tv/3/") {
+		t.Fatalf("DeleteMediaFilesPrefixes() = %v, want the listing errors of tv/1/ and tv/3/", err)
+	}
+	// The prefixes listed successfully are still removed.
+	if want := []string{"tv/1/index.m3u8", "tv/3/index.m3u8"}; !reflect.DeepEqual(fake.keys(), want) {
+		t.Errorf("bucket holds %v, want %v", fake.keys(), want)
+	}
+}
+
+func TestPrefixSize(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	fake.pageSize = 2
+	fake.putTestObject("42/index.m3u8", "playlist", "application/vnd.apple.mpegurl", "public-read")
+	fake.putTestObject("42/segment_000.ts", "segment 0", "video/mp2t", "public-read")
+	fake.putTestObject("42/segment_001.ts", "segment 1", "video/mp2t", "public-read")
+	fake.putTestObject("42/subtitle_3.vtt", "WEBVTT", "text/vtt", "public-read")
+	fake.putTestObject("420/index.m3u8", "other playlist", "application/vnd.apple.mpegurl", "public-read")
+
+	tests := []struct {
+		prefix    string
+		wantBytes int64
+		wantCount int64
+	}{
+		{prefix: "42/", wantBytes: 32, wantCount: 4},
+		{prefix: "42/segment_", wantBytes: 18, wantCount: 2},
+		{prefix: "43/", wantBytes: 0, wantCount: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.prefix, func(t *testing.T) {
+			totalBytes, objectCount, err := storage.PrefixSize(tt.prefix)
+			if err != nil {
+				t.Fatalf("PrefixSize() = %v", err)
+			}
+			if totalBytes != tt.wantBytes || objectCount != tt.wantCount {
+				t.Errorf("PrefixSize(%q) = %d bytes, %d objects, want %d bytes, %d objects", tt.prefix, totalBytes, objectCount, tt.wantBytes, tt.wantCount)
+			}
+		})
+	}
+}
+
+func TestPrefixSizeError(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	fake.failKeys["42/"] = true
+
+	if _, _, err := storage.PrefixSize("42/"); err == nil {
+		t.Error("PrefixSize() = nil error, want the listing failure")
+	}
+}
+
+func TestWithStorageClass(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{name: "default", want: ""},
+		{name: "infrequent access", opts: []Option{WithStorageClass(s3.StorageClassStandardIa)}, want: "STANDARD_IA"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, fake := newTestObjectStorage(t, tt.opts...)
+			if err := storage.UploadMediaFiles("42", writeFiles(t, "index.m3u8")); err != nil {
+				t.Fatalf("UploadMediaFiles() = %v", err)
+			}
+			if err := storage.UploadReader("42/response.json", strings.NewReader("{}"), "application/json"); err != nil {
+				t.Fatalf("UploadReader() = %v", err)
+			}
+			fake.putTestObject("staging/43/index.m3u8", "playlist", "application/vnd.apple.mpegurl", "private")
+			if err := storage.CopyMediaFiles("staging/43/", "43/"); err != nil {
+				t.Fatalf("CopyMediaFiles() = %v", err)
+			}
+
+			for _, key := range []string{"42/index.m3u8", "42/response.json", "43/index.m3u8"} {
+				object := fake.object(key)
+				if object == nil {
+					t.Fatalf("%s not stored, bucket holds %v", key, fake.keys())
+				}
+				if got := object.header.Get("X-Amz-Storage-Class"); got != tt.want {
+					t.Errorf("%s storage class = %q, want %q", key, got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestWithStorageClassInvalid(t *testing.T) {
+	_, err := NewObjectStorage("access", "secret", "http://localhost", "us-east-1", testBucket, WithStorageClass("COLD"))
+	if !errors.Is(err, ErrInvalidStorageClass) {
+		t.Errorf("NewObjectStorage() = %v, want ErrInvalidStorageClass", err)
+	}
+}
+
+func TestWithRegionDetection(t *testing.T) {
+	storage, fake := newTestObjectStorage(t, WithRegionDetection())
+	fake.putTestObject("42/index.m3u8", "playlist", "application/vnd.apple.mpegurl", "public-read")
+	fake.region = "eu-west-3"
+
+	totalBytes, objectCount, err := storage.PrefixSize("42/")
+	if err != nil {
+		t.Fatalf("PrefixSize() = %v", err)
+	}
+	if totalBytes != 8 || objectCount != 1 {
+		t.Errorf("PrefixSize() = %d bytes, %d objects, want 8 bytes, 1 object", totalBytes, objectCount)
+	}
+	if err := storage.DeleteMediaFiles("42/"); err != nil {
+		t.Fatalf("DeleteMediaFiles() = %v", err)
+	}
+
+	if keys := fake.keys(); len(keys) != 0 {
+		t.Errorf("bucket holds %v after DeleteMediaFiles(), want it empty", keys)
+	}
+	if region := aws.StringValue(storage.session().Config.Region); region != "eu-west-3" {
+		t.Errorf("session region = %s, want the detected eu-west-3", region)
+	}
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+	// Only the first call is redirected, the detected region is kept.
+	if fake.redirects != 1 {
+		t.Errorf("%d redirected requests, want 1", fake.redirects)
+	}
+}
+
+func TestWithoutRegionDetection(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	fake.region = "eu-west-3"
+
+	if _, _, err := storage.PrefixSize("42/"); !isRegionRedirect(err) {
+		t.Errorf("PrefixSize() = %v, want the region redirect", err)
+	}
+	if region := aws.StringValue(storage.session().Config.Region); region != "us-east-1" {
+		t.Errorf("session region = %s, want the configured us-east-1", region)
+	}
+}
+
+func TestPresignPutURL(t *testing.T) {
+	tests := []struct {
+		name              string
+		opts              []Option
+		contentType       string
+		wantSignedHeaders string
+	}{
+		{name: "default", contentType: "video/mp4", wantSignedHeaders: "content-type;host;x-amz-acl"},
+		{name: "no content type", wantSignedHeaders: "host;x-amz-acl"},
+		{name: "storage class", opts: []Option{WithStorageClass(s3.StorageClassStandardIa)}, contentType: "video/mp4", wantSignedHeaders: "content-type;host;x-amz-acl;x-amz-storage-class"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			storage, fake := newTestObjectStorage(t, tt.opts...)
+
+			presignedURL, err := storage.PresignPutURL("uploads/42/movie.mp4", 15*time.Minute, tt.contentType)
+			if err != nil {
+				t.Fatalf("PresignPutURL() = %v", err)
+			}
+			parsed, err := url.Parse(presignedURL)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if parsed.Path != "/"+testBucket+"/uploads/42/movie.mp4" {
+				t.Errorf("presigned path = %s, want the path-style key", parsed.Path)
+			}
+			query := parsed.Query()
+			for param, want := range map[string]string{
+				"X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+				"X-Amz-Expires":       "900",
+				"X-Amz-SignedHeaders": tt.wantSignedHeaders,
+			} {
+				if got := query.Get(param); got != want {
+					t.Errorf("%s = %q, want %q", param, got, want)
+				}
+			}
+			if credential := query.Get("X-Amz-Credential"); !strings.HasPrefix(credential, "access/") || !strings.HasSuffix(credential, "/us-east-1/s3/aws4_request") {
+				t.Errorf("X-Amz-Credential = %q, want the access key scoped to us-east-1", credential)
+			}
+			if query.Get("X-Amz-Signature") == "" {
+				t.Error("presigned URL is not signed")
+			}
+
+			// A client uploads with the URL and the signed headers.
+			request, err := http.NewRequest(http.MethodPut, presignedURL, strings.NewReader("movie"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.contentType != "" {
+				request.Header.Set("Content-Type", tt.contentType)
+			}
+			request.Header.Set("X-Amz-Acl", "public-read")
+			response, err := http.DefaultClient.Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+			if object := fake.object("uploads/42/movie.mp4"); response.StatusCode != http.StatusOK || object == nil || string(object.data) != "movie" {
+				t.Errorf("upload with the presigned URL = %s, bucket holds %v", response.Status, fake.keys())
+			}
+		})
+	}
+}
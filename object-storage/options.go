@@ -0,0 +1,176 @@
+package objectstorage
+
+import (
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/bingemate/media-go-pkg/logging"
+)
+
+// ErrInvalidStorageClass is returned by NewObjectStorage when WithStorageClass is given an unknown storage class.
+var ErrInvalidStorageClass = errors.New("invalid storage class")
+
+// ObjectHeaders holds the cache headers and metadata applied to an uploaded object.
+type ObjectHeaders struct {
+	CacheControl string
+	Metadata     map[string]string
+}
+
+// HeadersFunc returns the headers applied to an object uploaded from a file with the given extension (e.g. ".ts").
+type HeadersFunc func(extension string) ObjectHeaders
+
+// DefaultHeaders caches HLS segments forever, as they never change once written,
+// and prevents caching of the playlists and subtitles which are rewritten on re-transcode.
+func DefaultHeaders(extension string) ObjectHeaders {
+	switch extension {
+	case ".ts":
+		return ObjectHeaders{CacheControl: "public, max-age=31536000, immutable"}
+	case ".m3u8", ".vtt", ".json":
+		return ObjectHeaders{CacheControl: "no-cache"}
+	}
+	return ObjectHeaders{}
+}
+
+type options struct {
+	headersFunc  HeadersFunc
+	verifyETag   bool
+	retryPolicy  RetryPolicy
+	usePathStyle bool
+	logger       logging.Logger
+	storageClass string
+	detectRegion bool
+}
+
+// Option customizes the ObjectStorage created by NewObjectStorage.
+type Option func(*options)
+
+func newOptions(opts []Option) *options {
+	o := &options{
+		headersFunc: DefaultHeaders,
+		retryPolicy: DefaultRetryPolicy,
+		logger:      logging.Default,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithHeadersFunc sets the function computing the headers of each uploaded file.
+func WithHeadersFunc(f HeadersFunc) Option {
+	return func(o *options) {
+		o.headersFunc = f
+	}
+}
+
+// WithETagVerification checks after each file upload that the returned ETag matches the MD5 of the file.
+// Only enable it when the bucket returns MD5 ETags, which is not the case with SSE-KMS encryption.
+func WithETagVerification() Option {
+	return func(o *options) {
+		o.verifyETag = true
+	}
+}
+
+// WithRetryPolicy sets how uploads, copies and deletions are retried.
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(o *options) {
+		o.retryPolicy = policy
+	}
+}
+
+// WithLogger sets the logger of the object storage, e.g. logging.NewStdLogger(logging.LevelWarn)
+// to silence the per-operation progress messages.
+func WithLogger(logger logging.Logger) Option {
+	return func(o *options) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithPathStyle addresses the bucket as endpoint/bucket/key instead of bucket.endpoint/key.
+// Enable it for self-hosted S3 compatible storages (MinIO, Ceph...) reached through a custom
+// endpoint whose bucket subdomains don't resolve. AWS S3 works with the default virtual-hosted style.
+func WithPathStyle(usePathStyle bool) Option {
+	return func(o *options) {
+		o.usePathStyle = usePathStyle
+	}
+}
+
+// WithStorageClass stores uploaded and copied objects in the given S3 storage class (STANDARD, STANDARD_IA,
+// GLACIER...), e.g. for the cold archival of rarely watched content. NewObjectStorage rejects unknown
+// classes with ErrInvalidStorageClass. Without it, the provider default class is used; note that some
+// S3 compatible storages such as MinIO ignore the storage class.
+func WithStorageClass(storageClass string) Option {
+	return func(o *options) {
+		o.storageClass = storageClass
+	}
+}
+
+// storageClassValue returns the configured storage class, or nil to use the provider default.
+func (o *options) storageClassValue() *string {
+	if o.storageClass == "" {
+		return nil
+	}
+	return aws.String(o.storageClass)
+}
+
+// validateStorageClass checks that the configured storage class is one known by S3.
+func (o *options) validateStorageClass() error {
+	if o.storageClass == "" {
+		return nil
+	}
+	for _, storageClass := range s3.StorageClass_Values() {
+		if o.storageClass == storageClass {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %q", ErrInvalidStorageClass, o.storageClass)
+}
+
+// WithRegionDetection recovers from a bucket region mismatch: when S3 redirects a request because the bucket
+// lives in another region than the configured one, the bucket region is looked up, the request is retried
+// against it and the region is kept for the next requests. It is off by default, as custom endpoints
+// (MinIO, Ceph...) have no regions to detect.
+func WithRegionDetection() Option {
+	return func(o *options) {
+		o.detectRegion = true
+	}
+}
+
+// UploadProgress reports the progress of a directory upload.
+// FilesCompleted counts failed files too, BytesTransferred only counts successfully uploaded files.
+type UploadProgress struct {
+	FilesCompleted   int
+	TotalFiles       int
+	BytesTransferred int64
+	TotalBytes       int64
+}
+
+// ProgressFunc receives the upload progress each time a file is done.
+// Calls are serialized, the function doesn't need to be safe for concurrent use,
+// and they all complete before UploadMediaFiles returns.
+type ProgressFunc func(progress UploadProgress)
+
+type uploadOptions struct {
+	progressFunc ProgressFunc
+}
+
+// UploadOption customizes an UploadMediaFiles call.
+type UploadOption func(*uploadOptions)
+
+func newUploadOptions(opts []UploadOption) *uploadOptions {
+	o := &uploadOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithProgress sets a function called with the upload progress each time a file is done.
+func WithProgress(f ProgressFunc) UploadOption {
+	return func(o *uploadOptions) {
+		o.progressFunc = f
+	}
+}
@@ -0,0 +1,67 @@
+package objectstorage
+
+import (
+	"errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"net/http"
+)
+
+// session returns the current session, whose region may have been corrected by withRegionFallback.
+func (o *objectStorage) session() *session.Session {
+	o.sessLock.RLock()
+	defer o.sessLock.RUnlock()
+	return o.sess
+}
+
+// client returns an S3 client for the current session.
+func (o *objectStorage) client() *s3.S3 {
+	return s3.New(o.session())
+}
+
+// withRegionFallback runs fn and, when WithRegionDetection is enabled and fn failed because the bucket
+// lives in another region than the configured one, switches the session to the bucket region and runs
+// fn once more. The detected region is kept for the next operations.
+func (o *objectStorage) withRegionFallback(fn func() error) error {
+	err := fn()
+	if err == nil || !o.options.detectRegion || !isRegionRedirect(err) {
+		return err
+	}
+	if detectErr := o.detectBucketRegion(); detectErr != nil {
+		return errors.Join(err, detectErr)
+	}
+	return fn()
+}
+
+// detectBucketRegion looks up the region of the bucket and switches the session to it.
+func (o *objectStorage) detectBucketRegion() error {
+	sess := o.session()
+	configuredRegion := aws.StringValue(sess.Config.Region)
+	region, err := s3manager.GetBucketRegion(aws.BackgroundContext(), sess, o.bucket, configuredRegion)
+	if err != nil {
+		return err
+	}
+	if region == configuredRegion {
+		return nil
+	}
+	o.options.logger.Warnf("Bucket %s is in region %s, not %s: using %s from now on", o.bucket, region, configuredRegion, region)
+
+	o.sessLock.Lock()
+	defer o.sessLock.Unlock()
+	o.sess = sess.Copy(&aws.Config{Region: aws.String(region)})
+	return nil
+}
+
+// isRegionRedirect reports whether err is S3 redirecting a request to the region of the bucket.
+func isRegionRedirect(err error) bool {
+	var requestErr awserr.RequestFailure
+	if !errors.As(err, &requestErr) {
+		return false
+	}
+	return requestErr.StatusCode() == http.StatusMovedPermanently ||
+		requestErr.Code() == "PermanentRedirect" ||
+		requestErr.Code() == "AuthorizationHeaderMalformed"
+}
@@ -0,0 +1,64 @@
+package objectstorage
+
+import (
+	"github.com/bingemate/media-go-pkg/logging"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// RetryPolicy defines how S3 operations are retried.
+// The delay before the n-th retry is BaseDelay * Multiplier^(n-1), capped to MaxDelay when set,
+// then randomized by +/- Jitter (a fraction between 0 and 1).
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	Multiplier  float64
+	Jitter      float64
+}
+
+// DefaultRetryPolicy makes 3 attempts, 1 second apart.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	BaseDelay:   1 * time.Second,
+	Multiplier:  1,
+}
+
+// delay returns the time to wait before the given retry (1 for the first retry).
+func (p RetryPolicy) delay(retry int) time.Duration {
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	delay := float64(p.BaseDelay) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxDelay > 0 && delay > float64(p.MaxDelay) {
+		delay = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delay += delay * p.Jitter * (2*rand.Float64() - 1)
+	}
+	return time.Duration(delay)
+}
+
+// retry runs fn until it succeeds or the policy's attempts are exhausted, returning the last error.
+// description is used in logs, e.g. "upload movie/index.m3u8".
+func (p RetryPolicy) retry(logger logging.Logger, description string, fn func() error) error {
+	attempts := p.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		if attempt < attempts {
+			delay := p.delay(attempt)
+			logger.Warnf("Failed to %s, error: %s\nRetrying in %s...", description, err.Error(), delay)
+			time.Sleep(delay)
+		}
+	}
+	logger.Errorf("Failed to %s after %d attempts, error: %s", description, attempts, err.Error())
+	return err
+}
@@ -0,0 +1,90 @@
+package objectstorage
+
+import (
+	"errors"
+	"github.com/bingemate/media-go-pkg/logging"
+	"testing"
+	"time"
+)
+
+func TestRetryPolicyAttempts(t *testing.T) {
+	logger := logging.NewStdLogger(logging.LevelError)
+	errFailed := errors.New("failed")
+	tests := []struct {
+		name        string
+		maxAttempts int
+		failures    int
+		wantCalls   int
+		wantErr     bool
+	}{
+		{name: "success at first attempt", maxAttempts: 3, failures: 0, wantCalls: 1},
+		{name: "success after retries", maxAttempts: 3, failures: 2, wantCalls: 3},
+		{name: "attempts exhausted", maxAttempts: 3, failures: 5, wantCalls: 3, wantErr: true},
+		{name: "at least one attempt", maxAttempts: 0, failures: 5, wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := RetryPolicy{MaxAttempts: tt.maxAttempts, BaseDelay: time.Microsecond}
+			calls := 0
+			err := policy.retry(logger, "test", func() error {
+				calls++
+				if calls <= tt.failures {
+					return errFailed
+				}
+				return nil
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("fn called %d times, want %d", calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("retry() = %v, want error: %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy RetryPolicy
+		want   []time.Duration
+	}{
+		{
+			name:   "default policy",
+			policy: DefaultRetryPolicy,
+			want:   []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name:   "exponential backoff",
+			policy: RetryPolicy{BaseDelay: 100 * time.Millisecond, Multiplier: 2},
+			want:   []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond},
+		},
+		{
+			name:   "capped backoff",
+			policy: RetryPolicy{BaseDelay: 100 * time.Millisecond, Multiplier: 3, MaxDelay: 500 * time.Millisecond},
+			want:   []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				if got := tt.policy.delay(i + 1); got != want {
+					t.Errorf("delay(%d) = %s, want %s", i+1, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelayJitter(t *testing.T) {
+	policy := RetryPolicy{BaseDelay: time.Second, Multiplier: 2, Jitter: 0.2}
+	for retry := 1; retry <= 4; retry++ {
+		base := time.Duration(1<<(retry-1)) * time.Second
+		for i := 0; i < 100; i++ {
+			got := policy.delay(retry)
+			if got < base*8/10 || got > base*12/10 {
+				t.Fatalf("delay(%d) = %s, want within 20%% of %s", retry, got, base)
+			}
+		}
+	}
+}
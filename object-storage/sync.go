@@ -0,0 +1,151 @@
+package objectstorage
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// SyncMediaFiles makes the objects under prefix match the files of localPath: new files and files whose
+// size or MD5 differs from the existing object are uploaded, and objects without a local file are removed.
+// Unchanged files are not transferred again. Like UploadMediaFiles, files are uploaded under their name only.
+// Objects uploaded in several parts or encrypted with SSE-KMS have no MD5 ETag and are always uploaded again.
+func (o *objectStorage) SyncMediaFiles(prefix, localPath string) error {
+	return o.withRegionFallback(func() error {
+		return o.syncMediaFiles(prefix, localPath)
+	})
+}
+
+func (o *objectStorage) syncMediaFiles(prefix, localPath string) error {
+	client := o.client()
+	o.options.logger.Infof("Synchronizing files from %s to the bucket on path %s", localPath, prefix)
+
+	remote, err := o.listObjects(client, strings.TrimSuffix(prefix, "/")+"/")
+	if err != nil {
+		return err
+	}
+
+	var changed []string
+	local := make(map[string]bool)
+	err = filepath.WalkDir(localPath, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		key := filepath.Join(prefix, d.Name())
+		local[key] = true
+		unchanged, err := isUnchanged(path, remote[key])
+		if err != nil {
+			return err
+		}
+		if !unchanged {
+			changed = append(changed, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to walk directory %s, error: %s", localPath, err.Error())
+	}
+
+	var wg sync.WaitGroup
+	var errLock sync.Mutex
+	var syncErrs []error
+	sem := make(chan bool, 4) // limit to 4 concurrent goroutines
+
+	o.options.logger.Debugf("Uploading %d changed file(s) out of %d", len(changed), len(local))
+	for _, path := range changed {
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			if err := o.uploadFileToS3(client, prefix, path, sem); err != nil {
+				errLock.Lock()
+				syncErrs = append(syncErrs, err)
+				errLock.Unlock()
+			}
+		}(path)
+	}
+	wg.Wait()
+
+	var stale []*s3.ObjectIdentifier
+	for key := range remote {
+		if !local[key] {
+			stale = append(stale, &s3.ObjectIdentifier{Key: aws.String(key)})
+		}
+	}
+	o.options.logger.Debugf("Removing %d object(s) without local file", len(stale))
+	for start := 0; start < len(stale); start += maxDeleteObjects {
+		end := start + maxDeleteObjects
+		if end > len(stale) {
+			end = len(stale)
+		}
+		if err := o.deleteObjects(client, stale[start:end]); err != nil {
+			syncErrs = append(syncErrs, err)
+		}
+	}
+
+	if len(syncErrs) > 0 {
+		return fmt.Errorf("failed to synchronize %s: %w", localPath, errors.Join(syncErrs...))
+	}
+	o.options.logger.Infof("Files synchronized successfully")
+	return nil
+}
+
+// listObjects returns the objects under the prefix, by key.
+func (o *objectStorage) listObjects(client *s3.S3, prefix string) (map[string]*s3.Object, error) {
+	objects := make(map[string]*s3.Object)
+	var continuationToken *string
+
+	for {
+		resp, err := client.ListObjectsV2(&s3.ListObjectsV2Input{
+			Bucket:            aws.String(o.bucket),
+			Prefix:            aws.String(prefix),
+			ContinuationToken: continuationToken,
+		})
+		if err != nil {
+			o.options.logger.Errorf("Error while listing objects in %s", prefix)
+			return nil, err
+		}
+
+		for _, item := range resp.Contents {
+			objects[aws.StringValue(item.Key)] = item
+		}
+
+		if resp.NextContinuationToken == nil {
+			return objects, nil
+		}
+		continuationToken = resp.NextContinuationToken
+	}
+}
+
+// isUnchanged reports whether the local file has the same size and MD5 as the remote object.
+// The MD5 is only computed when the sizes match.
+func isUnchanged(path string, object *s3.Object) (bool, error) {
+	if object == nil {
+		return false, nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	if info.Size() != aws.Int64Value(object.Size) {
+		return false, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+	checksum, err := fileMD5(file)
+	if err != nil {
+		return false, err
+	}
+	return strings.Trim(aws.StringValue(object.ETag), `"`) == hex.EncodeToString(checksum), nil
+}
@@ -0,0 +1,84 @@
+package objectstorage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSyncMediaFilesUploadsOnlyChangedFiles(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	names := segmentNames(20)
+	dir := writeFiles(t, names...)
+	if err := storage.UploadMediaFiles("movie", dir); err != nil {
+		t.Fatalf("UploadMediaFiles() = %v", err)
+	}
+	fake.lock.Lock()
+	fake.puts = nil
+	fake.lock.Unlock()
+
+	// same size as the original content, so only the checksum tells them apart
+	if err := os.WriteFile(filepath.Join(dir, "segment_007.ts"), []byte("SEGMENT_007.ts"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.SyncMediaFiles("movie", dir); err != nil {
+		t.Fatalf("SyncMediaFiles() = %v", err)
+	}
+
+	if got, want := fake.uploadedKeys(), []string{"movie/segment_007.ts"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("uploaded %v, want only %v", got, want)
+	}
+	if got := string(fake.object("movie/segment_007.ts").data); got != "SEGMENT_007.ts" {
+		t.Errorf("remote segment_007.ts = %q, want the changed content", got)
+	}
+}
+
+func TestSyncMediaFilesRemovesDeletedFiles(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	dir := writeFiles(t, segmentNames(5)...)
+	if err := storage.UploadMediaFiles("movie", dir); err != nil {
+		t.Fatalf("UploadMediaFiles() = %v", err)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "segment_004.ts")); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.SyncMediaFiles("movie", dir); err != nil {
+		t.Fatalf("SyncMediaFiles() = %v", err)
+	}
+
+	keys := fake.keys()
+	for _, key := range keys {
+		if strings.HasSuffix(key, "segment_004.ts") {
+			t.Errorf("remote objects %v still contain the deleted segment", keys)
+		}
+	}
+	if len(keys) != 5 {
+		t.Errorf("%d remote objects, want 5", len(keys))
+	}
+}
+
+func TestSyncMediaFilesReportsFailedUploads(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	fake.failKeys["movie/segment_003.ts"] = true
+
+	err := storage.SyncMediaFiles("movie", writeFiles(t, segmentNames(10)...))
+	if err == nil || !strings.Contains(err.Error(), "movie/segment_003.ts") {
+		t.Errorf("SyncMediaFiles() = %v, want an error for the failed segment", err)
+	}
+}
+
+func TestSyncMediaFilesKeepsSiblingPrefixes(t *testing.T) {
+	storage, fake := newTestObjectStorage(t)
+	fake.putTestObject("123/index.m3u8", "playlist", "application/vnd.apple.mpegurl", "public-read")
+
+	if err := storage.SyncMediaFiles("12", writeFiles(t, "index.m3u8")); err != nil {
+		t.Fatalf("SyncMediaFiles() = %v", err)
+	}
+
+	if want := []string{"12/index.m3u8", "123/index.m3u8"}; !reflect.DeepEqual(fake.keys(), want) {
+		t.Errorf("bucket holds %v, want %v", fake.keys(), want)
+	}
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// CountMoviesByCategory returns the number of movies in each category, keyed by category name.
+// Categories without any movie are omitted.
+func CountMoviesByCategory(db *gorm.DB) (map[string]int64, error) {
+	var rows []struct {
+		Name  string
+		Count int64
+	}
+	err := db.Model(&CategoryMovie{}).
+		Select("categories.name AS name, COUNT(*) AS count").
+		Joins("JOIN categories ON categories.id = category_movie.category_id").
+		Group("categories.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Name] = row.Count
+	}
+	return counts, nil
+}
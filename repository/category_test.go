@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountMoviesByCategory(t *testing.T) {
+	db := newTestDB(t)
+	action := Category{Name: "Action"}
+	drama := Category{Name: "Drame"}
+	documentary := Category{Name: "Documentaire"}
+	for _, category := range []*Category{&action, &drama, &documentary} {
+		if err := db.Create(category).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	movies := []Movie{
+		{ID: 1, Name: "Inception", Categories: []Category{action, drama}},
+		{ID: 2, Name: "Heat", Categories: []Category{action}},
+		{ID: 3, Name: "Le Parrain", Categories: []Category{drama}},
+		{ID: 4, Name: "Sans catégorie"},
+	}
+	if err := db.Create(&movies).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := CountMoviesByCategory(db)
+	if err != nil {
+		t.Fatalf("CountMoviesByCategory() = %v", err)
+	}
+	if want := map[string]int64{"Action": 2, "Drame": 2}; !reflect.DeepEqual(counts, want) {
+		t.Errorf("CountMoviesByCategory() = %v, want %v", counts, want)
+	}
+}
@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const episodeBatchSize = 100
+
+// SaveEpisodes links the given episodes to the TV show and inserts them in batches within a single
+// transaction, updating the episodes that already exist.
+func SaveEpisodes(db *gorm.DB, tvShowID int, episodes []Episode) error {
+	if len(episodes) == 0 {
+		return nil
+	}
+	for i := range episodes {
+		episodes[i].TvShowID = tvShowID
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		return tx.Omit(clause.Associations).
+			Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "id"}},
+				UpdateAll: true,
+			}).
+			CreateInBatches(&episodes, episodeBatchSize).Error
+	})
+}
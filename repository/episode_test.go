@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"fmt"
+	"testing"
+)
+
+// testSeasonEpisodes returns the episodes of a season, named with the given prefix.
+func testSeasonEpisodes(season, count int, prefix string) []Episode {
+	var episodes = make([]Episode, count)
+	for i := range episodes {
+		episodes[i] = Episode{
+			ID:        season*1000 + i + 1,
+			Name:      fmt.Sprintf("%s %d", prefix, i+1),
+			NbEpisode: i + 1,
+			NbSeason:  season,
+		}
+	}
+	return episodes
+}
+
+func TestSaveEpisodes(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.Create(&TvShow{ID: 1396, Name: "Breaking Bad"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SaveEpisodes(db, 1396, testSeasonEpisodes(1, 24, "Épisode")); err != nil {
+		t.Fatalf("SaveEpisodes() = %v", err)
+	}
+	// Saving the season again updates the episodes instead of failing on their IDs
+	if err := SaveEpisodes(db, 1396, testSeasonEpisodes(1, 24, "Chapitre")); err != nil {
+		t.Fatalf("SaveEpisodes(again) = %v", err)
+	}
+
+	var episodes []Episode
+	if err := db.Where("tv_show_id = ?", 1396).Order("nb_episode").Find(&episodes).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(episodes) != 24 {
+		t.Fatalf("%d episodes stored, want 24", len(episodes))
+	}
+	for i, episode := range episodes {
+		if want := fmt.Sprintf("Chapitre %d", i+1); episode.Name != want || episode.NbEpisode != i+1 {
+			t.Errorf("episode %d = %q (#%d), want %q", i, episode.Name, episode.NbEpisode, want)
+		}
+	}
+
+	if err := SaveEpisodes(db, 1396, nil); err != nil {
+		t.Errorf("SaveEpisodes(nil) = %v", err)
+	}
+}
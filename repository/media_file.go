@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fmt"
+	"github.com/bingemate/media-go-pkg/transcoder"
+	"gorm.io/gorm"
+	"os"
+	"path/filepath"
+)
+
+// NewMediaFile builds a MediaFile from the file at filePath and its probe result (see transcoder.ProbeMedia).
+// Size comes from the file itself and Duration, in seconds, from the probed container. Audios and
+// Subtitles list the audio and text subtitle streams with their language normalized by
+// transcoder.NormalizeLanguage, named after the playlists and WebVTT files ProcessFileTranscode
+// writes for them. The MediaFile is not saved.
+func NewMediaFile(filePath string, info *transcoder.MediaInfo) (*MediaFile, error) {
+	if info == nil {
+		return nil, fmt.Errorf("repository: no probe result for %s", filePath)
+	}
+	stat, err := os.Stat(filePath)
+	if err != nil {
+		return nil, err
+	}
+	mediaFile := &MediaFile{
+		Filename: filepath.Base(filePath),
+		Duration: info.Duration.Seconds(),
+		Size:     stat.Size(),
+	}
+	for _, stream := range info.Streams {
+		language, _ := transcoder.NormalizeLanguage(stream.Language)
+		switch {
+		case stream.Type == "audio":
+			mediaFile.Audios = append(mediaFile.Audios, Audio{
+				Filename: fmt.Sprintf("audio_%d.m3u8", stream.Index),
+				Language: language,
+			})
+		case stream.IsTextSubtitle():
+			mediaFile.Subtitles = append(mediaFile.Subtitles, Subtitle{
+				Filename: fmt.Sprintf("subtitle_%d.vtt", stream.Index),
+				Language: language,
+			})
+		}
+	}
+	return mediaFile, nil
+}
+
+// GetMediaFileLanguages returns the distinct languages of the audio and subtitle streams of a media file,
+// sorted alphabetically, without loading the stream rows.
+func GetMediaFileLanguages(db *gorm.DB, mediaFileID string) (audio []string, subs []string, err error) {
+	err = db.Model(&Audio{}).
+		Where("media_file_id = ?", mediaFileID).
+		Distinct().
+		Order("language").
+		Pluck("language", &audio).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	err = db.Model(&Subtitle{}).
+		Where("media_file_id = ?", mediaFileID).
+		Distinct().
+		Order("language").
+		Pluck("language", &subs).Error
+	if err != nil {
+		return nil, nil, err
+	}
+	return audio, subs, nil
+}
@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"github.com/bingemate/media-go-pkg/transcoder"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetMediaFileLanguages(t *testing.T) {
+	db := newTestDB(t)
+	mediaFile := MediaFile{
+		Filename: "movie.mkv",
+		Audios: []Audio{
+			{Filename: "audio_0.m3u8", Language: "fre"},
+			{Filename: "audio_1.m3u8", Language: "eng"},
+			{Filename: "audio_2.m3u8", Language: "fre"},
+		},
+		Subtitles: []Subtitle{
+			{Filename: "subtitle_0.vtt", Language: "fre"},
+			{Filename: "subtitle_1.vtt", Language: "fre"},
+		},
+	}
+	other := MediaFile{Filename: "other.mkv", Audios: []Audio{{Filename: "audio_0.m3u8", Language: "ger"}}}
+	for _, file := range []*MediaFile{&mediaFile, &other} {
+		if err := db.Create(file).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	audio, subs, err := GetMediaFileLanguages(db, mediaFile.ID)
+	if err != nil {
+		t.Fatalf("GetMediaFileLanguages() = %v", err)
+	}
+	if want := []string{"eng", "fre"}; !reflect.DeepEqual(audio, want) {
+		t.Errorf("audio languages = %v, want %v", audio, want)
+	}
+	if want := []string{"fre"}; !reflect.DeepEqual(subs, want) {
+		t.Errorf("subtitle languages = %v, want %v", subs, want)
+	}
+}
+
+func TestNewMediaFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "movie.mkv")
+	if err := os.WriteFile(filePath, make([]byte, 2048), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	info := &transcoder.MediaInfo{
+		Container: "matroska,webm",
+		Duration:  5423500 * time.Millisecond,
+		Streams: []transcoder.StreamInfo{
+			{Index: 0, Type: "video", Codec: "h264"},
+			{Index: 1, Type: "audio", Codec: "aac", Language: "fre"},
+			{Index: 2, Type: "audio", Codec: "ac3", Language: "en-US"},
+			{Index: 3, Type: "subtitle", Codec: "subrip", Language: "fre"},
+			{Index: 4, Type: "subtitle", Codec: "hdmv_pgs_subtitle", Language: "eng"},
+			{Index: 5, Type: "subtitle", Codec: "ass", Language: "eng"},
+		},
+	}
+
+	mediaFile, err := NewMediaFile(filePath, info)
+	if err != nil {
+		t.Fatalf("NewMediaFile() = %v", err)
+	}
+	want := &MediaFile{
+		Filename: "movie.mkv",
+		Duration: 5423.5,
+		Size:     2048,
+		Audios: []Audio{
+			{Filename: "audio_1.m3u8", Language: "fr"},
+			{Filename: "audio_2.m3u8", Language: "en"},
+		},
+		Subtitles: []Subtitle{
+			{Filename: "subtitle_3.vtt", Language: "fr"},
+			{Filename: "subtitle_5.vtt", Language: "en"},
+		},
+	}
+	if !reflect.DeepEqual(mediaFile, want) {
+		t.Errorf("NewMediaFile() = %+v, want %+v", mediaFile, want)
+	}
+}
+
+func TestNewMediaFileErrors(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "movie.mkv")
+	if err := os.WriteFile(filePath, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewMediaFile(filePath, nil); err == nil {
+		t.Error("NewMediaFile() without probe result = nil error")
+	}
+	if _, err := NewMediaFile(filepath.Join(t.TempDir(), "missing.mkv"), &transcoder.MediaInfo{}); !os.IsNotExist(err) {
+		t.Errorf("NewMediaFile() of a missing file = %v, want a not exist error", err)
+	}
+}
@@ -1,11 +1,21 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"reflect"
 	"time"
 )
 
 type (
 	WatchListStatus string
+	MediaType       string
+)
+
+const (
+	MediaTypeMovie   MediaType = "MOVIE"
+	MediaTypeEpisode MediaType = "EPISODE"
 )
 
 const (
@@ -15,6 +25,57 @@ const (
 	WatchListStatusAbandoned   WatchListStatus = "ABANDONED"
 )
 
+// ErrInvalidWatchListStatus is returned when saving a watch list item whose status is not a WatchListStatus constant.
+var ErrInvalidWatchListStatus = errors.New("invalid watch list status")
+
+// IsValidWatchListStatus reports whether s is one of the WatchListStatus constants.
+func IsValidWatchListStatus(s WatchListStatus) bool {
+	switch s {
+	case WatchListStatusPlanToWatch, WatchListStatusWatching, WatchListStatusFinished, WatchListStatusAbandoned:
+		return true
+	}
+	return false
+}
+
+func validateWatchListStatus(s WatchListStatus) error {
+	if !IsValidWatchListStatus(s) {
+		return fmt.Errorf("%w: %q", ErrInvalidWatchListStatus, s)
+	}
+	return nil
+}
+
+// validateUpdatedWatchListStatus validates the status written by an update, if any.
+// The hook receiver may hold the status before the update (Model(&item).Update("status", ...)),
+// so the new value is read from the statement destination: a map for Update and Updates with a map,
+// or a struct for Save and Updates with a struct, whose zero status is only written when selected.
+func validateUpdatedWatchListStatus(tx *gorm.DB) error {
+	if values, ok := tx.Statement.Dest.(map[string]interface{}); ok {
+		for _, key := range []string{"Status", "status"} {
+			if value, ok := values[key]; ok {
+				switch status := value.(type) {
+				case WatchListStatus:
+					return validateWatchListStatus(status)
+				case string:
+					return validateWatchListStatus(WatchListStatus(status))
+				}
+				return nil
+			}
+		}
+		return nil
+	}
+	field := tx.Statement.Schema.LookUpField("Status")
+	dest := reflect.Indirect(reflect.ValueOf(tx.Statement.Dest))
+	if field == nil || dest.Kind() != reflect.Struct {
+		return nil
+	}
+	value, zero := field.ValueOf(tx.Statement.Context, dest)
+	if selected, _ := tx.Statement.SelectAndOmitColumns(false, true); zero && !selected[field.DBName] {
+		return nil
+	}
+	status, _ := value.(WatchListStatus)
+	return validateWatchListStatus(status)
+}
+
 type Model struct {
 	ID        string `gorm:"type:uuid;primaryKey;default:uuid_generate_v4()"`
 	CreatedAt time.Time
@@ -197,6 +258,14 @@ func (MovieWatchListItem) TableName() string {
 	return "movie_watch_list_item"
 }
 
+func (i *MovieWatchListItem) BeforeCreate(*gorm.DB) error {
+	return validateWatchListStatus(i.Status)
+}
+
+func (i *MovieWatchListItem) BeforeUpdate(tx *gorm.DB) error {
+	return validateUpdatedWatchListStatus(tx)
+}
+
 type TvShowWatchListItem struct {
 	UserID   string          `gorm:"type:uuid;primaryKey"`
 	TvShowID int             `gorm:"primaryKey"`
@@ -206,3 +275,24 @@ type TvShowWatchListItem struct {
 func (TvShowWatchListItem) TableName() string {
 	return "tv_show_watch_list_item"
 }
+
+func (i *TvShowWatchListItem) BeforeCreate(*gorm.DB) error {
+	return validateWatchListStatus(i.Status)
+}
+
+func (i *TvShowWatchListItem) BeforeUpdate(tx *gorm.DB) error {
+	return validateUpdatedWatchListStatus(tx)
+}
+
+// PlaybackProgress is the position a user stopped at in a movie or an episode, used to resume playback.
+type PlaybackProgress struct {
+	UserID          string    `gorm:"type:uuid;primaryKey"`
+	MediaType       MediaType `gorm:"primaryKey;type:varchar"`
+	MediaID         int       `gorm:"primaryKey"`
+	PositionSeconds float64
+	UpdatedAt       time.Time `gorm:"autoUpdateTime;index"`
+}
+
+func (PlaybackProgress) TableName() string {
+	return "playback_progress"
+}
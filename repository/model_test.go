@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIsValidWatchListStatus(t *testing.T) {
+	tests := []struct {
+		status WatchListStatus
+		want   bool
+	}{
+		{status: WatchListStatusPlanToWatch, want: true},
+		{status: WatchListStatusWatching, want: true},
+		{status: WatchListStatusFinished, want: true},
+		{status: WatchListStatusAbandoned, want: true},
+		{status: "", want: false},
+		{status: "watching", want: false},
+		{status: "DROPPED", want: false},
+	}
+	for _, tt := range tests {
+		if got := IsValidWatchListStatus(tt.status); got != tt.want {
+			t.Errorf("IsValidWatchListStatus(%q) = %v, want %v", tt.status, got, tt.want)
+		}
+	}
+}
+
+func TestWatchListItemRejectsInvalidStatus(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.Create(&Movie{ID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&TvShow{ID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	err := db.Create(&MovieWatchListItem{UserID: "user", MovieID: 1, Status: "DROPPED"}).Error
+	if !errors.Is(err, ErrInvalidWatchListStatus) {
+		t.Errorf("creating a movie watch list item with an invalid status = %v, want ErrInvalidWatchListStatus", err)
+	}
+	err = db.Create(&TvShowWatchListItem{UserID: "user", TvShowID: 1, Status: ""}).Error
+	if !errors.Is(err, ErrInvalidWatchListStatus) {
+		t.Errorf("creating a TV show watch list item without status = %v, want ErrInvalidWatchListStatus", err)
+	}
+
+	item := MovieWatchListItem{UserID: "user", MovieID: 1, Status: WatchListStatusWatching}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("creating a valid watch list item = %v", err)
+	}
+	item.Status = "watching"
+	if err := db.Save(&item).Error; !errors.Is(err, ErrInvalidWatchListStatus) {
+		t.Errorf("updating a watch list item to an invalid status = %v, want ErrInvalidWatchListStatus", err)
+	}
+
+	var count int64
+	db.Model(&MovieWatchListItem{}).Where("status <> ?", WatchListStatusWatching).Count(&count)
+	if count != 0 {
+		t.Errorf("%d watch list items stored with an invalid status", count)
+	}
+}
+
+func TestWatchListItemUpdateStatus(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.Create(&Movie{ID: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	item := MovieWatchListItem{UserID: "user", MovieID: 1, Status: WatchListStatusPlanToWatch}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.Model(&item).Update("status", WatchListStatusWatching).Error; err != nil {
+		t.Errorf("Update(status, WATCHING) = %v", err)
+	}
+	if err := db.Model(&MovieWatchListItem{}).Where("user_id = ?", "user").Update("status", "FINISHED").Error; err != nil {
+		t.Errorf("Update(status, FINISHED) without the item loaded = %v", err)
+	}
+	if err := db.Model(&item).Update("status", "BOGUS").Error; !errors.Is(err, ErrInvalidWatchListStatus) {
+		t.Errorf("Update(status, BOGUS) = %v, want ErrInvalidWatchListStatus", err)
+	}
+	if err := db.Model(&item).Updates(MovieWatchListItem{Status: "BOGUS"}).Error; !errors.Is(err, ErrInvalidWatchListStatus) {
+		t.Errorf("Updates(status BOGUS) = %v, want ErrInvalidWatchListStatus", err)
+	}
+	if err := db.Model(&item).Updates(map[string]interface{}{"status": WatchListStatusAbandoned}).Error; err != nil {
+		t.Errorf("Updates(status ABANDONED) = %v", err)
+	}
+
+	var stored MovieWatchListItem
+	if err := db.First(&stored, "user_id = ? AND movie_id = ?", "user", 1).Error; err != nil {
+		t.Fatal(err)
+	}
+	if stored.Status != WatchListStatusAbandoned {
+		t.Errorf("stored status = %q, want ABANDONED", stored.Status)
+	}
+}
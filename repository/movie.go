@@ -0,0 +1,27 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+	"time"
+)
+
+// GetTrendingMovies returns the limit movies with the most ratings and comments created since the given date,
+// the most active first.
+func GetTrendingMovies(db *gorm.DB, since time.Time, limit int) ([]Movie, error) {
+	activity := db.Raw(
+		"SELECT movie_id FROM movie_ratings WHERE created_at >= ? "+
+			"UNION ALL SELECT movie_id FROM movie_comments WHERE created_at >= ?",
+		since, since,
+	)
+	var movies []Movie
+	err := db.Model(&Movie{}).
+		Joins("JOIN (?) AS activity ON activity.movie_id = movies.id", activity).
+		Group("movies.id").
+		Order("COUNT(*) DESC").
+		Limit(limit).
+		Find(&movies).Error
+	if err != nil {
+		return nil, err
+	}
+	return movies, nil
+}
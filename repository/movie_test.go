@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetTrendingMovies(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Date(2023, 6, 15, 12, 0, 0, 0, time.UTC)
+	since := now.AddDate(0, 0, -7)
+	for id := 1; id <= 4; id++ {
+		if err := db.Create(&Movie{ID: id}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	// activity lists the ages in days of the ratings and comments of each movie
+	activity := []struct {
+		movieID  int
+		ratings  []int
+		comments []int
+	}{
+		// the most active movie of all time, but not this week
+		{movieID: 1, ratings: []int{30, 31, 32, 33}, comments: []int{30, 40, 50}},
+		{movieID: 2, ratings: []int{1, 2}, comments: []int{1, 6}},
+		// the comment of exactly a week ago is counted
+		{movieID: 3, ratings: []int{1, 8}, comments: []int{7}},
+		{movieID: 4, comments: []int{0, 3, 5, 10}},
+	}
+	for _, movie := range activity {
+		for i, age := range movie.ratings {
+			rating := MovieRating{UserID: fmt.Sprint("user-", i), MovieID: movie.movieID, Rating: 4, CreatedAt: now.AddDate(0, 0, -age)}
+			if err := db.Create(&rating).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, age := range movie.comments {
+			comment := MovieComment{UserID: "user", MovieID: movie.movieID, Content: "…", Model: Model{CreatedAt: now.AddDate(0, 0, -age)}}
+			if err := db.Create(&comment).Error; err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	tests := []struct {
+		name  string
+		limit int
+		want  []int
+	}{
+		{name: "all", limit: 10, want: []int{2, 4, 3}},
+		{name: "limited", limit: 2, want: []int{2, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movies, err := GetTrendingMovies(db, since, tt.limit)
+			if err != nil {
+				t.Fatalf("GetTrendingMovies() = %v", err)
+			}
+			var ids []int
+			for _, movie := range movies {
+				ids = append(ids, movie.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("GetTrendingMovies() = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
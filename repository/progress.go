@@ -0,0 +1,56 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// UpsertProgress records the playback position of a user in a movie or an episode,
+// replacing the previous position.
+func UpsertProgress(db *gorm.DB, userID string, mediaType MediaType, mediaID int, positionSeconds float64) error {
+	progress := PlaybackProgress{
+		UserID:          userID,
+		MediaType:       mediaType,
+		MediaID:         mediaID,
+		PositionSeconds: positionSeconds,
+		UpdatedAt:       time.Now(),
+	}
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "media_type"}, {Name: "media_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"position_seconds", "updated_at"}),
+	}).Create(&progress).Error
+}
+
+// GetProgress returns the playback position of a user in a movie or an episode.
+// It returns ErrNotFound if the user never played it.
+func GetProgress(db *gorm.DB, userID string, mediaType MediaType, mediaID int) (*PlaybackProgress, error) {
+	var progress PlaybackProgress
+	err := db.
+		Where("user_id = ? AND media_type = ? AND media_id = ?", userID, mediaType, mediaID).
+		First(&progress).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: progress of %s %d", ErrNotFound, mediaType, mediaID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// ListRecentlyWatched returns the limit last movies and episodes played by a user, the most recent first.
+func ListRecentlyWatched(db *gorm.DB, userID string, limit int) ([]PlaybackProgress, error) {
+	var progresses []PlaybackProgress
+	err := db.
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Limit(limit).
+		Find(&progresses).Error
+	if err != nil {
+		return nil, err
+	}
+	return progresses, nil
+}
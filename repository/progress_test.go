@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const testUserID = "6f1c7a52-3b8e-4d0e-9a5e-1f2b3c4d5e6f"
+
+func TestUpsertProgress(t *testing.T) {
+	db := newTestDB(t)
+
+	if err := UpsertProgress(db, testUserID, MediaTypeMovie, 550, 120); err != nil {
+		t.Fatalf("UpsertProgress() = %v", err)
+	}
+	if err := UpsertProgress(db, testUserID, MediaTypeMovie, 550, 360); err != nil {
+		t.Fatalf("UpsertProgress() = %v", err)
+	}
+
+	var count int64
+	if err := db.Model(&PlaybackProgress{}).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("%d progress rows after two upserts, want 1", count)
+	}
+	progress, err := GetProgress(db, testUserID, MediaTypeMovie, 550)
+	if err != nil {
+		t.Fatalf("GetProgress() = %v", err)
+	}
+	if progress.PositionSeconds != 360 {
+		t.Errorf("position = %v, want the last one, 360", progress.PositionSeconds)
+	}
+}
+
+func TestGetProgressNotFound(t *testing.T) {
+	db := newTestDB(t)
+	if err := UpsertProgress(db, testUserID, MediaTypeEpisode, 550, 120); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := GetProgress(db, testUserID, MediaTypeMovie, 550)
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetProgress() = %v, want ErrNotFound", err)
+	}
+}
+
+func TestListRecentlyWatched(t *testing.T) {
+	db := newTestDB(t)
+	for _, mediaID := range []int{1, 2, 3, 4} {
+		if err := UpsertProgress(db, testUserID, MediaTypeMovie, mediaID, 60); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+	// Playing the first movie again makes it the most recent.
+	if err := UpsertProgress(db, testUserID, MediaTypeMovie, 1, 90); err != nil {
+		t.Fatal(err)
+	}
+	if err := UpsertProgress(db, "0b9d4a3e-8c7f-4e2a-b1d0-9f8e7d6c5b4a", MediaTypeMovie, 5, 60); err != nil {
+		t.Fatal(err)
+	}
+
+	progresses, err := ListRecentlyWatched(db, testUserID, 3)
+	if err != nil {
+		t.Fatalf("ListRecentlyWatched() = %v", err)
+	}
+	var got []int
+	for _, progress := range progresses {
+		got = append(got, progress.MediaID)
+	}
+	if want := []int{1, 4, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("recently watched = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SetMovieRating records the rating of a user for a movie, replacing the previous one.
+// The conflict target is the (user_id, movie_id) primary key and only rating and updated_at are
+// rewritten, which PostgreSQL and SQLite both support with the same ON CONFLICT ... DO UPDATE
+// statement; the creation date of the first rating is kept.
+func SetMovieRating(db *gorm.DB, userID string, movieID int, rating int) error {
+	movieRating := MovieRating{
+		UserID:  userID,
+		MovieID: movieID,
+		Rating:  rating,
+	}
+	return db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "movie_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rating", "updated_at"}),
+	}).Create(&movieRating).Error
+}
+
+// SetTvShowRating records the rating of a user for a TV show, replacing the previous one.
+// It behaves like SetMovieRating.
+func SetTvShowRating(db *gorm.DB, userID string, tvShowID int, rating int) error {
+	tvShowRating := TvShowRating{
+		UserID:   userID,
+		TvShowID: tvShowID,
+		Rating:   rating,
+	}
+	return db.Omit(clause.Associations).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "tv_show_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rating", "updated_at"}),
+	}).Create(&tvShowRating).Error
+}
+
+// GetUserMovieRatings returns the page (starting at 1) of the movie ratings of a user, pageSize ratings
+// per page, the most recently updated first, along with the total number of ratings of the user.
+// Each rating has its Movie preloaded with the locally stored fields only (name, release date);
+// callers wanting the full metadata (poster, overview, ...) enrich it with tmdb.MediaClient.
+// ErrInvalidPage is returned if page or pageSize is lower than 1.
+func GetUserMovieRatings(db *gorm.DB, userID string, page, pageSize int) ([]MovieRating, int64, error) {
+	if page < 1 || pageSize < 1 {
+		return nil, 0, fmt.Errorf("%w: page %d of size %d", ErrInvalidPage, page, pageSize)
+	}
+	var total int64
+	err := db.Model(&MovieRating{}).
+		Where("user_id = ?", userID).
+		Count(&total).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	var ratings []MovieRating
+	err = db.
+		Preload("Movie").
+		Where("user_id = ?", userID).
+		Order("updated_at DESC").
+		Offset((page - 1) * pageSize).
+		Limit(pageSize).
+		Find(&ratings).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return ratings, total, nil
+}
@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSetMovieRating(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.Create(&Movie{ID: 550, Name: "Fight Club"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := SetMovieRating(db, testUserID, 550, 3); err != nil {
+		t.Fatalf("SetMovieRating() = %v", err)
+	}
+	var first MovieRating
+	if err := db.First(&first).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := SetMovieRating(db, testUserID, 550, 5); err != nil {
+		t.Fatalf("SetMovieRating() = %v", err)
+	}
+
+	var ratings []MovieRating
+	if err := db.Find(&ratings).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(ratings) != 1 {
+		t.Fatalf("%d ratings after rating twice, want 1", len(ratings))
+	}
+	if ratings[0].Rating != 5 {
+		t.Errorf("rating = %d, want the last one, 5", ratings[0].Rating)
+	}
+	if !ratings[0].CreatedAt.Equal(first.CreatedAt) {
+		t.Errorf("creation date changed from %v to %v", first.CreatedAt, ratings[0].CreatedAt)
+	}
+}
+
+func TestSetTvShowRating(t *testing.T) {
+	db := newTestDB(t)
+	if err := db.Create(&TvShow{ID: 1399, Name: "Game of Thrones"}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	for _, rating := range []int{4, 2} {
+		if err := SetTvShowRating(db, testUserID, 1399, rating); err != nil {
+			t.Fatalf("SetTvShowRating() = %v", err)
+		}
+	}
+
+	var ratings []TvShowRating
+	if err := db.Find(&ratings).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(ratings) != 1 || ratings[0].Rating != 2 {
+		t.Errorf("ratings = %+v, want a single rating of 2", ratings)
+	}
+}
+
+func TestGetUserMovieRatings(t *testing.T) {
+	db := newTestDB(t)
+	ratedAt := time.Date(2023, 5, 1, 12, 0, 0, 0, time.UTC)
+	// Movies rated in the order 3, 1, 4, 2, 5.
+	for movieID, hoursLater := range map[int]int{1: 1, 2: 3, 3: 0, 4: 2, 5: 4} {
+		if err := db.Create(&Movie{ID: movieID, Name: fmt.Sprintf("Movie %d", movieID)}).Error; err != nil {
+			t.Fatal(err)
+		}
+		rating := MovieRating{UserID: testUserID, MovieID: movieID, Rating: 4, UpdatedAt: ratedAt.Add(time.Duration(hoursLater) * time.Hour)}
+		if err := db.Create(&rating).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := db.Create(&MovieRating{UserID: "0b9d4a3e-8c7f-4e2a-b1d0-9f8e7d6c5b4a", MovieID: 1, Rating: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		page int
+		want []int
+	}{
+		{1, []int{5, 2}},
+		{2, []int{4, 1}},
+		{3, []int{3}},
+		{4, nil},
+	}
+	for _, tt := range tests {
+		ratings, total, err := GetUserMovieRatings(db, testUserID, tt.page, 2)
+		if err != nil {
+			t.Fatalf("GetUserMovieRatings(page %d) = %v", tt.page, err)
+		}
+		if total != 5 {
+			t.Errorf("page %d: total = %d, want 5", tt.page, total)
+		}
+		var got []int
+		for _, rating := range ratings {
+			got = append(got, rating.MovieID)
+			if rating.Movie.ID != rating.MovieID || rating.Movie.Name == "" {
+				t.Errorf("page %d: rating of movie %d has movie %+v, want it preloaded", tt.page, rating.MovieID, rating.Movie)
+			}
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("page %d: movies = %v, want %v", tt.page, got, tt.want)
+		}
+	}
+}
+
+func TestGetUserMovieRatingsInvalidPage(t *testing.T) {
+	db := newTestDB(t)
+	tests := []struct {
+		page, pageSize int
+	}{
+		{0, 2},
+		{-1, 2},
+		{1, 0},
+		{1, -5},
+	}
+	for _, tt := range tests {
+		_, _, err := GetUserMovieRatings(db, testUserID, tt.page, tt.pageSize)
+		if !errors.Is(err, ErrInvalidPage) {
+			t.Errorf("GetUserMovieRatings(page %d, size %d) = %v, want ErrInvalidPage", tt.page, tt.pageSize, err)
+		}
+	}
+}
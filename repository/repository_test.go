@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"strings"
+	"testing"
+)
+
+// sqliteDialector runs the repository against SQLite in tests.
+// Its migrator replaces the PostgreSQL uuid_generate_v4() column default, which SQLite cannot parse,
+// with a random hexadecimal identifier.
+type sqliteDialector struct {
+	sqlite.Dialector
+}
+
+func (d sqliteDialector) Migrator(db *gorm.DB) gorm.Migrator {
+	return sqliteMigrator{d.Dialector.Migrator(db).(sqlite.Migrator)}
+}
+
+type sqliteMigrator struct {
+	sqlite.Migrator
+}
+
+func (m sqliteMigrator) FullDataTypeOf(field *schema.Field) clause.Expr {
+	expr := m.Migrator.FullDataTypeOf(field)
+	expr.SQL = strings.Replace(expr.SQL, "uuid_generate_v4()", "(lower(hex(randomblob(16))))", 1)
+	return expr
+}
+
+// newTestDB returns a migrated in-memory SQLite database, private to the test.
+func newTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dialector := sqliteDialector{*sqlite.Open("file:" + t.Name() + "?mode=memory&cache=shared&_foreign_keys=1").(*sqlite.Dialector)}
+	db, err := gorm.Open(dialector, &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := Migrate(db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestMigrate(t *testing.T) {
+	db := newTestDB(t)
+	mediaFile := MediaFile{Filename: "movie.mkv"}
+	if err := db.Create(&mediaFile).Error; err != nil {
+		t.Fatalf("failed to create a media file: %v", err)
+	}
+	if mediaFile.ID == "" {
+		t.Error("media file created without an ID")
+	}
+}
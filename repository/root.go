@@ -1,9 +1,17 @@
 package repository
 
 import (
+	"errors"
+
 	"gorm.io/gorm"
 )
 
+// ErrNotFound is returned when the requested record does not exist.
+var ErrNotFound = errors.New("repository: record not found")
+
+// ErrInvalidPage is returned when a page or a page size is lower than 1.
+var ErrInvalidPage = errors.New("repository: invalid page")
+
 func Migrate(db *gorm.DB) error {
 	return db.AutoMigrate(
 		&MediaFile{},
@@ -21,5 +29,6 @@ func Migrate(db *gorm.DB) error {
 		&TvShowComment{},
 		&MovieWatchListItem{},
 		&TvShowWatchListItem{},
+		&PlaybackProgress{},
 	)
 }
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	objectstorage "github.com/bingemate/media-go-pkg/object-storage"
+	"gorm.io/gorm"
+)
+
+// GetTvShowWithEpisodes returns the TV show with its episodes, ordered by season then episode number,
+// each with its media file and the file's audio and subtitle streams.
+// It returns ErrNotFound if the TV show does not exist.
+func GetTvShowWithEpisodes(db *gorm.DB, tvShowID int) (*TvShow, error) {
+	var tvShow TvShow
+	err := db.
+		Preload("Episodes", func(db *gorm.DB) *gorm.DB {
+			return db.Order("nb_season, nb_episode")
+		}).
+		Preload("Episodes.MediaFile.Audios").
+		Preload("Episodes.MediaFile.Subtitles").
+		First(&tvShow, tvShowID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("%w: tv show %d", ErrNotFound, tvShowID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tvShow, nil
+}
+
+// DeleteTvShowCompletely deletes the TV show with its episodes, ratings and comments, the media files of its
+// episodes that no other episode or movie uses, and the storage of each episode, whose prefix is given by
+// storagePrefixFor.
+// The database rows are deleted in a single transaction before the storage, so that a storage failure never
+// leaves rows pointing to missing files; the storage errors of every episode are joined into the returned error.
+// It returns ErrNotFound if the TV show does not exist.
+func DeleteTvShowCompletely(db *gorm.DB, storage objectstorage.ObjectStorage, tvShowID int, storagePrefixFor func(episodeID int) string) error {
+	var episodes []Episode
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Select("id").First(&TvShow{}, tvShowID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("%w: tv show %d", ErrNotFound, tvShowID)
+			}
+			return err
+		}
+		if err := tx.Select("id", "media_file_id").Where("tv_show_id = ?", tvShowID).Find(&episodes).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("tv_show_id = ?", tvShowID).Delete(&Episode{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&TvShow{}, tvShowID).Error; err != nil {
+			return err
+		}
+		return deleteOrphanMediaFiles(tx, episodeMediaFileIDs(episodes))
+	})
+	if err != nil {
+		return err
+	}
+	if len(episodes) == 0 {
+		return nil
+	}
+	prefixes := make([]string, 0, len(episodes))
+	for _, episode := range episodes {
+		prefixes = append(prefixes, storagePrefixFor(episode.ID))
+	}
+	if err := storage.DeleteMediaFilesPrefixes(prefixes); err != nil {
+		return fmt.Errorf("failed to delete the storage of tv show %d: %w", tvShowID, err)
+	}
+	return nil
+}
+
+// episodeMediaFileIDs returns the IDs of the media files linked to the episodes.
+func episodeMediaFileIDs(episodes []Episode) []string {
+	var ids []string
+	for _, episode := range episodes {
+		if episode.MediaFileID != nil {
+			ids = append(ids, *episode.MediaFileID)
+		}
+	}
+	return ids
+}
+
+// deleteOrphanMediaFiles deletes the media files among ids that no episode or movie references anymore.
+func deleteOrphanMediaFiles(db *gorm.DB, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return db.
+		Where("id IN ?", ids).
+		Where("id NOT IN (?)", db.Model(&Episode{}).Select("media_file_id").Where("media_file_id IS NOT NULL")).
+		Where("id NOT IN (?)", db.Model(&Movie{}).Select("media_file_id").Where("media_file_id IS NOT NULL")).
+		Delete(&MediaFile{}).Error
+}
@@ -0,0 +1,174 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	objectstorage "github.com/bingemate/media-go-pkg/object-storage"
+	"gorm.io/gorm"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestGetTvShowWithEpisodes(t *testing.T) {
+	db := newTestDB(t)
+	mediaFile := MediaFile{
+		Filename:  "pilot.mkv",
+		Audios:    []Audio{{Filename: "audio_fre.m3u8", Language: "fre"}, {Filename: "audio_eng.m3u8", Language: "eng"}},
+		Subtitles: []Subtitle{{Filename: "subtitle_fre.vtt", Language: "fre"}},
+	}
+	if err := db.Create(&mediaFile).Error; err != nil {
+		t.Fatal(err)
+	}
+	tvShow := TvShow{ID: 1396, Name: "Breaking Bad", Episodes: []Episode{
+		{ID: 2, Name: "Seven Thirty-Seven", NbSeason: 2, NbEpisode: 1},
+		{ID: 1, Name: "Cat's in the Bag...", NbSeason: 1, NbEpisode: 2},
+		{ID: 3, Name: "Pilot", NbSeason: 1, NbEpisode: 1, MediaFileID: &mediaFile.ID},
+	}}
+	if err := db.Omit("Episodes.MediaFile").Create(&tvShow).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := GetTvShowWithEpisodes(db, 1396)
+	if err != nil {
+		t.Fatalf("GetTvShowWithEpisodes() = %v", err)
+	}
+	if len(got.Episodes) != 3 {
+		t.Fatalf("%d episodes, want 3", len(got.Episodes))
+	}
+	for i, want := range []string{"Pilot", "Cat's in the Bag...", "Seven Thirty-Seven"} {
+		if got.Episodes[i].Name != want {
+			t.Errorf("episode %d = %q, want %q", i, got.Episodes[i].Name, want)
+		}
+	}
+	pilot := got.Episodes[0].MediaFile
+	if pilot == nil || pilot.Filename != "pilot.mkv" {
+		t.Fatalf("pilot media file = %+v, want pilot.mkv", pilot)
+	}
+	if len(pilot.Audios) != 2 || len(pilot.Subtitles) != 1 || pilot.Subtitles[0].Language != "fre" {
+		t.Errorf("pilot streams = %+v / %+v, want 2 audios and the French subtitle", pilot.Audios, pilot.Subtitles)
+	}
+	if got.Episodes[1].MediaFile != nil {
+		t.Errorf("episode without a file has media file %+v", got.Episodes[1].MediaFile)
+	}
+
+	if _, err := GetTvShowWithEpisodes(db, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTvShowWithEpisodes(unknown) = %v, want ErrNotFound", err)
+	}
+}
+
+// fakeStorage records the prefixes given to DeleteMediaFilesPrefixes, failing with err when set.
+// Its other methods are not implemented.
+type fakeStorage struct {
+	objectstorage.ObjectStorage
+	deletedPrefixes []string
+	err             error
+}
+
+func (s *fakeStorage) DeleteMediaFilesPrefixes(prefixes []string) error {
+	s.deletedPrefixes = append(s.deletedPrefixes, prefixes...)
+	return s.err
+}
+
+func episodePrefix(episodeID int) string {
+	return fmt.Sprintf("tv-shows/episode-%d", episodeID)
+}
+
+// createTvShows creates TV show 1, whose episode 10 has its own media file, episode 11 a media file shared
+// with a movie and episode 12 none, and TV show 2, whose episode 20 has its own media file.
+func createTvShows(t *testing.T) (db *gorm.DB, ownFile, sharedFile, otherFile MediaFile) {
+	t.Helper()
+	db = newTestDB(t)
+	ownFile, sharedFile, otherFile = MediaFile{Filename: "s01e01.mkv"}, MediaFile{Filename: "s01e02.mkv"}, MediaFile{Filename: "other.mkv"}
+	for _, mediaFile := range []*MediaFile{&ownFile, &sharedFile, &otherFile} {
+		if err := db.Create(mediaFile).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	records := []interface{}{
+		&TvShow{ID: 1, Name: "Show", Episodes: []Episode{
+			{ID: 10, NbSeason: 1, NbEpisode: 1, MediaFileID: &ownFile.ID},
+			{ID: 11, NbSeason: 1, NbEpisode: 2, MediaFileID: &sharedFile.ID},
+			{ID: 12, NbSeason: 1, NbEpisode: 3},
+		}},
+		&TvShow{ID: 2, Name: "Other show", Episodes: []Episode{
+			{ID: 20, NbSeason: 1, NbEpisode: 1, MediaFileID: &otherFile.ID},
+		}},
+		&Movie{ID: 550, Name: "Movie", MediaFileID: &sharedFile.ID},
+		&TvShowRating{UserID: testUserID, TvShowID: 1, Rating: 4},
+	}
+	for _, record := range records {
+		if err := db.Omit("Episodes.TvShow").Create(record).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db, ownFile, sharedFile, otherFile
+}
+
+func TestDeleteTvShowCompletely(t *testing.T) {
+	db, ownFile, sharedFile, otherFile := createTvShows(t)
+	storage := &fakeStorage{}
+
+	if err := DeleteTvShowCompletely(db, storage, 1, episodePrefix); err != nil {
+		t.Fatalf("DeleteTvShowCompletely() = %v", err)
+	}
+
+	counts := []struct {
+		name  string
+		query *gorm.DB
+		want  int64
+	}{
+		{"deleted tv show", db.Model(&TvShow{}).Where("id = ?", 1), 0},
+		{"episodes of the deleted tv show", db.Model(&Episode{}).Where("tv_show_id = ?", 1), 0},
+		{"ratings of the deleted tv show", db.Model(&TvShowRating{}).Where("tv_show_id = ?", 1), 0},
+		{"orphan media file", db.Model(&MediaFile{}).Where("id = ?", ownFile.ID), 0},
+		{"media file shared with a movie", db.Model(&MediaFile{}).Where("id = ?", sharedFile.ID), 1},
+		{"other tv show", db.Model(&TvShow{}).Where("id = ?", 2), 1},
+		{"episodes of the other tv show", db.Model(&Episode{}).Where("tv_show_id = ?", 2), 1},
+		{"media file of the other tv show", db.Model(&MediaFile{}).Where("id = ?", otherFile.ID), 1},
+	}
+	for _, c := range counts {
+		var count int64
+		if err := c.query.Count(&count).Error; err != nil {
+			t.Fatal(err)
+		}
+		if count != c.want {
+			t.Errorf("%s: %d rows, want %d", c.name, count, c.want)
+		}
+	}
+
+	sort.Strings(storage.deletedPrefixes)
+	want := []string{episodePrefix(10), episodePrefix(11), episodePrefix(12)}
+	if !reflect.DeepEqual(storage.deletedPrefixes, want) {
+		t.Errorf("deleted prefixes = %v, want %v", storage.deletedPrefixes, want)
+	}
+}
+
+func TestDeleteTvShowCompletelyStorageError(t *testing.T) {
+	db, _, _, _ := createTvShows(t)
+	storageErr := errors.New("storage unavailable")
+
+	err := DeleteTvShowCompletely(db, &fakeStorage{err: storageErr}, 1, episodePrefix)
+	if !errors.Is(err, storageErr) {
+		t.Fatalf("DeleteTvShowCompletely() = %v, want the storage error", err)
+	}
+	var count int64
+	if err := db.Model(&Episode{}).Where("tv_show_id = ?", 1).Count(&count).Error; err != nil {
+		t.Fatal(err)
+	}
+	if count != 0 {
+		t.Errorf("%d episodes left, want the rows deleted before the storage", count)
+	}
+}
+
+func TestDeleteTvShowCompletelyNotFound(t *testing.T) {
+	db := newTestDB(t)
+	storage := &fakeStorage{}
+
+	if err := DeleteTvShowCompletely(db, storage, 1, episodePrefix); !errors.Is(err, ErrNotFound) {
+		t.Errorf("DeleteTvShowCompletely() = %v, want ErrNotFound", err)
+	}
+	if len(storage.deletedPrefixes) != 0 {
+		t.Errorf("deleted prefixes = %v for a missing tv show, want none", storage.deletedPrefixes)
+	}
+}
@@ -0,0 +1,42 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// CountMovieWatchListByStatus returns the number of movies in the watch list of a user for each status.
+// Every status is present in the map, with a zero count when the user has no movie with it.
+func CountMovieWatchListByStatus(db *gorm.DB, userID string) (map[WatchListStatus]int64, error) {
+	return countWatchListByStatus(db.Model(&MovieWatchListItem{}), userID)
+}
+
+// CountTvShowWatchListByStatus returns the number of TV shows in the watch list of a user for each status.
+// Every status is present in the map, with a zero count when the user has no TV show with it.
+func CountTvShowWatchListByStatus(db *gorm.DB, userID string) (map[WatchListStatus]int64, error) {
+	return countWatchListByStatus(db.Model(&TvShowWatchListItem{}), userID)
+}
+
+func countWatchListByStatus(db *gorm.DB, userID string) (map[WatchListStatus]int64, error) {
+	var rows []struct {
+		Status WatchListStatus
+		Count  int64
+	}
+	err := db.
+		Select("status, COUNT(*) AS count").
+		Where("user_id = ?", userID).
+		Group("status").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := map[WatchListStatus]int64{
+		WatchListStatusPlanToWatch: 0,
+		WatchListStatusWatching:    0,
+		WatchListStatusFinished:    0,
+		WatchListStatusAbandoned:   0,
+	}
+	for _, row := range rows {
+		counts[row.Status] = row.Count
+	}
+	return counts, nil
+}
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCountMovieWatchListByStatus(t *testing.T) {
+	db := newTestDB(t)
+	items := []MovieWatchListItem{
+		{UserID: testUserID, MovieID: 1, Status: WatchListStatusWatching},
+		{UserID: testUserID, MovieID: 2, Status: WatchListStatusFinished},
+		{UserID: testUserID, MovieID: 3, Status: WatchListStatusFinished},
+		{UserID: "0b9d4a3e-8c7f-4e2a-b1d0-9f8e7d6c5b4a", MovieID: 1, Status: WatchListStatusAbandoned},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := CountMovieWatchListByStatus(db, testUserID)
+	if err != nil {
+		t.Fatalf("CountMovieWatchListByStatus() = %v", err)
+	}
+	want := map[WatchListStatus]int64{
+		WatchListStatusPlanToWatch: 0,
+		WatchListStatusWatching:    1,
+		WatchListStatusFinished:    2,
+		WatchListStatusAbandoned:   0,
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+}
+
+func TestCountTvShowWatchListByStatus(t *testing.T) {
+	db := newTestDB(t)
+	items := []TvShowWatchListItem{
+		{UserID: testUserID, TvShowID: 1, Status: WatchListStatusPlanToWatch},
+		{UserID: testUserID, TvShowID: 2, Status: WatchListStatusAbandoned},
+	}
+	if err := db.Create(&items).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	counts, err := CountTvShowWatchListByStatus(db, testUserID)
+	if err != nil {
+		t.Fatalf("CountTvShowWatchListByStatus() = %v", err)
+	}
+	want := map[WatchListStatus]int64{
+		WatchListStatusPlanToWatch: 1,
+		WatchListStatusWatching:    0,
+		WatchListStatusFinished:    0,
+		WatchListStatusAbandoned:   1,
+	}
+	if !reflect.DeepEqual(counts, want) {
+		t.Errorf("counts = %v, want %v", counts, want)
+	}
+
+	counts, err = CountTvShowWatchListByStatus(db, "0b9d4a3e-8c7f-4e2a-b1d0-9f8e7d6c5b4a")
+	if err != nil {
+		t.Fatalf("CountTvShowWatchListByStatus() = %v", err)
+	}
+	if len(counts) != 4 {
+		t.Errorf("counts for a user without a watch list = %v, want every status at zero", counts)
+	}
+	for status, count := range counts {
+		if count != 0 {
+			t.Errorf("count of %s = %d for a user without a watch list, want 0", status, count)
+		}
+	}
+}
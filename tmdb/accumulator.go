@@ -0,0 +1,70 @@
+package tmdb
+
+// MovieResultAccumulator collects pages of movie results, dropping the movies already seen
+// on a previous page (TMDB may return a title on adjacent pages when popularity shifts).
+// It is not safe for concurrent use.
+type MovieResultAccumulator struct {
+	seen    map[int]bool
+	results []*Movie
+}
+
+// NewMovieResultAccumulator returns an empty MovieResultAccumulator.
+func NewMovieResultAccumulator() *MovieResultAccumulator {
+	return &MovieResultAccumulator{seen: make(map[int]bool)}
+}
+
+// AddPage adds the movies of a page and returns the ones not seen before, in page order.
+func (a *MovieResultAccumulator) AddPage(page *PaginatedMovieResults) []*Movie {
+	if page == nil {
+		return nil
+	}
+	var added []*Movie
+	for _, movie := range page.Results {
+		if movie == nil || a.seen[movie.ID] {
+			continue
+		}
+		a.seen[movie.ID] = true
+		added = append(added, movie)
+	}
+	a.results = append(a.results, added...)
+	return added
+}
+
+// Results returns every unique movie added so far, in order of first appearance.
+func (a *MovieResultAccumulator) Results() []*Movie {
+	return a.results
+}
+
+// TVShowResultAccumulator collects pages of TV show results, dropping the shows already seen
+// on a previous page. It is not safe for concurrent use.
+type TVShowResultAccumulator struct {
+	seen    map[int]bool
+	results []*TVShow
+}
+
+// NewTVShowResultAccumulator returns an empty TVShowResultAccumulator.
+func NewTVShowResultAccumulator() *TVShowResultAccumulator {
+	return &TVShowResultAccumulator{seen: make(map[int]bool)}
+}
+
+// AddPage adds the TV shows of a page and returns the ones not seen before, in page order.
+func (a *TVShowResultAccumulator) AddPage(page *PaginatedTVShowResults) []*TVShow {
+	if page == nil {
+		return nil
+	}
+	var added []*TVShow
+	for _, tvShow := range page.Results {
+		if tvShow == nil || a.seen[tvShow.ID] {
+			continue
+		}
+		a.seen[tvShow.ID] = true
+		added = append(added, tvShow)
+	}
+	a.results = append(a.results, added...)
+	return added
+}
+
+// Results returns every unique TV show added so far, in order of first appearance.
+func (a *TVShowResultAccumulator) Results() []*TVShow {
+	return a.results
+}
@@ -0,0 +1,43 @@
+package tmdb
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMovieResultAccumulator(t *testing.T) {
+	accumulator := NewMovieResultAccumulator()
+
+	added := accumulator.AddPage(&PaginatedMovieResults{Results: []*Movie{{ID: 1}, {ID: 2}, {ID: 3}}})
+	if got := movieIDs(added); !reflect.DeepEqual(got, []int{1, 2, 3}) {
+		t.Errorf("AddPage(page 1) = %v, want [1 2 3]", got)
+	}
+	// Movie 3 moved down to page 2 between the requests
+	added = accumulator.AddPage(&PaginatedMovieResults{Results: []*Movie{{ID: 3}, nil, {ID: 4}, {ID: 4}}})
+	if got := movieIDs(added); !reflect.DeepEqual(got, []int{4}) {
+		t.Errorf("AddPage(page 2) = %v, want [4]", got)
+	}
+	if added := accumulator.AddPage(nil); added != nil {
+		t.Errorf("AddPage(nil) = %v, want nil", added)
+	}
+	if got := movieIDs(accumulator.Results()); !reflect.DeepEqual(got, []int{1, 2, 3, 4}) {
+		t.Errorf("Results() = %v, want [1 2 3 4]", got)
+	}
+}
+
+func TestTVShowResultAccumulator(t *testing.T) {
+	accumulator := NewTVShowResultAccumulator()
+
+	accumulator.AddPage(&PaginatedTVShowResults{Results: []*TVShow{{ID: 10}, {ID: 20}}})
+	added := accumulator.AddPage(&PaginatedTVShowResults{Results: []*TVShow{{ID: 20}, {ID: 30}, {ID: 10}}})
+	if len(added) != 1 || added[0].ID != 30 {
+		t.Errorf("AddPage(page 2) = %v, want only show 30", added)
+	}
+	var ids []int
+	for _, tvShow := range accumulator.Results() {
+		ids = append(ids, tvShow.ID)
+	}
+	if !reflect.DeepEqual(ids, []int{10, 20, 30}) {
+		t.Errorf("Results() = %v, want [10 20 30]", ids)
+	}
+}
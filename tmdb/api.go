@@ -0,0 +1,85 @@
+package tmdb
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// apiBaseURL is the root of the TMDB API called by getJSON.
+var apiBaseURL = "https://api.themoviedb.org/3"
+
+// apiStatus is the body returned by TMDB along with an error status.
+type apiStatus struct {
+	Code    int    `json:"status_code"`
+	Message string `json:"status_message"`
+}
+
+// getJSON calls a TMDB endpoint not covered by go-tmdb and decodes its response into v.
+// Errors are reported the way go-tmdb does, and wrapped with wrapError.
+func (m *mediaClient) getJSON(path string, query url.Values, v interface{}) error {
+	if query == nil {
+		query = url.Values{}
+	}
+	query.Set("api_key", m.apiKey)
+	client := http.Client{Timeout: m.clientOptions.callTimeout}
+	res, err := client.Get(apiBaseURL + path + "?" + query.Encode())
+	if err != nil {
+		return timeoutError(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return timeoutError(err)
+	}
+	if res.StatusCode >= 200 && res.StatusCode < 300 {
+		return json.Unmarshal(body, v)
+	}
+	var status apiStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("unexpected status %d from TMDB", res.StatusCode)
+	}
+	return wrapError(fmt.Errorf("Code (%d): %s", status.Code, status.Message))
+}
+
+// timeoutError returns ErrTimeout if err reports that the request timed out, err otherwise.
+func timeoutError(err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %v", ErrTimeout, err)
+	}
+	return err
+}
+
+// callWithTimeout runs a go-tmdb call and returns ErrTimeout if it does not complete within the timeout
+// set with WithCallTimeout. As go-tmdb calls cannot be cancelled, a timed out call keeps running in
+// the background until its HTTP request completes, and its result is dropped.
+func callWithTimeout[T any](m *mediaClient, call func() (T, error)) (T, error) {
+	timeout := m.clientOptions.callTimeout
+	if timeout <= 0 {
+		return call()
+	}
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := call()
+		done <- result{value, err}
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-timer.C:
+		var zero T
+		return zero, ErrTimeout
+	}
+}
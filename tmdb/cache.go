@@ -1,10 +1,13 @@
 package tmdb
 
 import (
+	"bytes"
+	"compress/gzip"
+	"github.com/bingemate/media-go-pkg/logging"
 	"github.com/go-redis/redis"
 	jsoniter "github.com/json-iterator/go"
 	"github.com/patrickmn/go-cache"
-	"log"
+	"io"
 	"strconv"
 	"time"
 )
@@ -14,8 +17,14 @@ var json = jsoniter.ConfigCompatibleWithStandardLibrary
 type mediaCache interface {
 	AddActor(actor *Actor)
 	AddActorSearchResults(query string, page int, adult bool, results *PaginatedActorResults)
+	AddCollectionMovies(collectionID int, movies []*Movie)
 	AddEpisode(e *TVEpisode)
+	AddFindResult(imdbID string, result *FindResult)
 	AddMovie(m *Movie)
+	AddMovieAvailability(movieID int, region string, available bool)
+	AddMovieCredits(movieID int, c *credits)
+	AddMovieFull(m *Movie)
+	AddMovieExists(id int, exists bool)
 	AddMovieGenre(genre *Genre)
 	AddMovieRecommendations(movieID int, results []*Movie)
 	AddMoviesByActor(actorID int, page int, results *PaginatedMovieResults)
@@ -24,9 +33,16 @@ type mediaCache interface {
 	AddMovieSearchResults(query string, page int, adult bool, results *PaginatedMovieResults)
 	AddMovieSearchResultsYear(query string, page int, year string, results *PaginatedMovieResults)
 	AddMovieShort(m *Movie)
+	AddNetwork(n *Studio)
+	AddNowPlayingMovies(page int, results *PaginatedMovieResults)
+	AddPersonImages(personID int, images []string)
 	AddSeason(tvID int, seasonNumber int, s []*TVEpisode)
+	AddStudio(s *Studio)
 	AddTV(t *TVShow)
+	AddTVCredits(tvID int, c *credits)
+	AddTVExists(id int, exists bool)
 	AddTVGenre(genre *Genre)
+	AddTVSeasonCredits(tvID int, seasonNumber int, c *credits)
 	AddTVRecommendations(tvID int, results []*TVShow)
 	AddTVsByActor(actorID int, page int, results *PaginatedTVShowResults)
 	AddTVsByGenre(genreID int, page int, results *PaginatedTVShowResults)
@@ -35,19 +51,37 @@ type mediaCache interface {
 	AddTVShort(t *TVShow)
 	GetActor(id int) *Actor
 	GetActorSearchResults(query string, page int, adult bool) *PaginatedActorResults
+	GetCollectionMovies(collectionID int) []*Movie
 	GetEpisode(tvID int, seasonNumber int, episodeNumber int) *TVEpisode
+	GetFindResult(imdbID string) *FindResult
 	GetMovie(id int) *Movie
+	GetMovieAvailability(movieID int, region string) (available bool, ok bool)
+	GetMovieCredits(movieID int) *credits
+	GetMovieFull(id int) *Movie
+	GetMovieExists(id int) (exists bool, ok bool)
 	GetMovieGenre(id int) *Genre
 	GetMovieRecommendations(movieID int) []*Movie
+	GetMovies(ids []int) map[int]*Movie
 	GetMoviesByActor(actorID int, page int) *PaginatedMovieResults
 	GetMoviesByGenre(genreID int, page int) *PaginatedMovieResults
 	GetMoviesByStudio(studioID int, page int) *PaginatedMovieResults
 	GetMovieSearchResults(query string, page int, adult bool) *PaginatedMovieResults
 	GetMovieSearchResultsYear(query string, page int, year string) *PaginatedMovieResults
 	GetMovieShort(id int) *Movie
+	GetNetwork(id int) *Studio
+	GetNowPlayingMovies(page int) *PaginatedMovieResults
+	GetPersonImages(personID int) []string
 	GetSeason(tvID int, seasonNumber int) []*TVEpisode
+	GetStaleMovie(id int) *Movie
+	GetStaleMovieShort(id int) *Movie
+	GetStaleTV(id int) *TVShow
+	GetStaleTVShort(id int) *TVShow
+	GetStudio(id int) *Studio
 	GetTV(id int) *TVShow
+	GetTVCredits(tvID int) *credits
+	GetTVExists(id int) (exists bool, ok bool)
 	GetTVGenre(id int) *Genre
+	GetTVSeasonCredits(tvID int, seasonNumber int) *credits
 	GetTVRecommendations(tvID int) []*TVShow
 	GetTVsByActor(actorID int, page int) *PaginatedTVShowResults
 	GetTVsByGenre(genreID int, page int) *PaginatedTVShowResults
@@ -79,6 +113,28 @@ func (c *inMemoryMediaCache) GetMovie(id int) *Movie {
 	return m.(*Movie)
 }
 
+func (c *inMemoryMediaCache) GetMovies(ids []int) map[int]*Movie {
+	movies := make(map[int]*Movie, len(ids))
+	for _, id := range ids {
+		if m := c.GetMovie(id); m != nil {
+			movies[id] = m
+		}
+	}
+	return movies
+}
+
+func (c *inMemoryMediaCache) AddMovieFull(m *Movie) {
+	c.cache.SetDefault("movie_full:"+strconv.Itoa(m.ID), m)
+}
+
+func (c *inMemoryMediaCache) GetMovieFull(id int) *Movie {
+	m, ok := c.cache.Get("movie_full:" + strconv.Itoa(id))
+	if !ok {
+		return nil
+	}
+	return m.(*Movie)
+}
+
 func (c *inMemoryMediaCache) AddMovieShort(m *Movie) {
 	c.cache.SetDefault("movie_short:"+strconv.Itoa(m.ID), m)
 }
@@ -343,24 +399,199 @@ func (c *inMemoryMediaCache) GetActorSearchResults(query string, page int, adult
 	return r.(*PaginatedActorResults)
 }
 
+func (c *inMemoryMediaCache) AddFindResult(imdbID string, result *FindResult) {
+	c.cache.SetDefault("find:"+imdbID, result)
+}
+
+func (c *inMemoryMediaCache) GetFindResult(imdbID string) *FindResult {
+	r, ok := c.cache.Get("find:" + imdbID)
+	if !ok {
+		return nil
+	}
+	return r.(*FindResult)
+}
+
+func (c *inMemoryMediaCache) AddMovieCredits(movieID int, cr *credits) {
+	c.cache.SetDefault("movie_credits:"+strconv.Itoa(movieID), cr)
+}
+
+func (c *inMemoryMediaCache) GetMovieCredits(movieID int) *credits {
+	r, ok := c.cache.Get("movie_credits:" + strconv.Itoa(movieID))
+	if !ok {
+		return nil
+	}
+	return r.(*credits)
+}
+
+func (c *inMemoryMediaCache) AddTVCredits(tvID int, cr *credits) {
+	c.cache.SetDefault("tv_credits:"+strconv.Itoa(tvID), cr)
+}
+
+func (c *inMemoryMediaCache) GetTVCredits(tvID int) *credits {
+	r, ok := c.cache.Get("tv_credits:" + strconv.Itoa(tvID))
+	if !ok {
+		return nil
+	}
+	return r.(*credits)
+}
+
+func (c *inMemoryMediaCache) AddPersonImages(personID int, images []string) {
+	c.cache.SetDefault("person_images:"+strconv.Itoa(personID), images)
+}
+
+func (c *inMemoryMediaCache) GetPersonImages(personID int) []string {
+	r, ok := c.cache.Get("person_images:" + strconv.Itoa(personID))
+	if !ok {
+		return nil
+	}
+	return r.([]string)
+}
+
+func (c *inMemoryMediaCache) AddNowPlayingMovies(page int, results *PaginatedMovieResults) {
+	c.cache.SetDefault("movie_now_playing:"+strconv.Itoa(page), results)
+}
+
+func (c *inMemoryMediaCache) GetNowPlayingMovies(page int) *PaginatedMovieResults {
+	r, ok := c.cache.Get("movie_now_playing:" + strconv.Itoa(page))
+	if !ok {
+		return nil
+	}
+	return r.(*PaginatedMovieResults)
+}
+
+func (c *inMemoryMediaCache) AddMovieExists(id int, exists bool) {
+	c.cache.SetDefault("movie_exists:"+strconv.Itoa(id), exists)
+}
+
+func (c *inMemoryMediaCache) GetMovieExists(id int) (bool, bool) {
+	e, ok := c.cache.Get("movie_exists:" + strconv.Itoa(id))
+	if !ok {
+		return false, false
+	}
+	return e.(bool), true
+}
+
+func (c *inMemoryMediaCache) AddTVExists(id int, exists bool) {
+	c.cache.SetDefault("tv_exists:"+strconv.Itoa(id), exists)
+}
+
+func (c *inMemoryMediaCache) GetTVExists(id int) (bool, bool) {
+	e, ok := c.cache.Get("tv_exists:" + strconv.Itoa(id))
+	if !ok {
+		return false, false
+	}
+	return e.(bool), true
+}
+
+func (c *inMemoryMediaCache) AddCollectionMovies(collectionID int, movies []*Movie) {
+	c.cache.SetDefault("collection:"+strconv.Itoa(collectionID), movies)
+}
+
+func (c *inMemoryMediaCache) GetCollectionMovies(collectionID int) []*Movie {
+	m, ok := c.cache.Get("collection:" + strconv.Itoa(collectionID))
+	if !ok {
+		return nil
+	}
+	return m.([]*Movie)
+}
+
+func (c *inMemoryMediaCache) AddMovieAvailability(movieID int, region string, available bool) {
+	c.cache.SetDefault("movie_availability:"+strconv.Itoa(movieID)+":"+region, available)
+}
+
+func (c *inMemoryMediaCache) GetMovieAvailability(movieID int, region string) (bool, bool) {
+	a, ok := c.cache.Get("movie_availability:" + strconv.Itoa(movieID) + ":" + region)
+	if !ok {
+		return false, false
+	}
+	return a.(bool), true
+}
+
+func (c *inMemoryMediaCache) AddTVSeasonCredits(tvID int, seasonNumber int, cr *credits) {
+	c.cache.SetDefault("tv_season_credits:"+strconv.Itoa(tvID)+":"+strconv.Itoa(seasonNumber), cr)
+}
+
+func (c *inMemoryMediaCache) GetTVSeasonCredits(tvID int, seasonNumber int) *credits {
+	r, ok := c.cache.Get("tv_season_credits:" + strconv.Itoa(tvID) + ":" + strconv.Itoa(seasonNumber))
+	if !ok {
+		return nil
+	}
+	return r.(*credits)
+}
+
+func (c *inMemoryMediaCache) AddStudio(s *Studio) {
+	c.cache.SetDefault("studio:"+strconv.Itoa(s.ID), s)
+}
+
+func (c *inMemoryMediaCache) GetStudio(id int) *Studio {
+	s, ok := c.cache.Get("studio:" + strconv.Itoa(id))
+	if !ok {
+		return nil
+	}
+	return s.(*Studio)
+}
+
+func (c *inMemoryMediaCache) AddNetwork(n *Studio) {
+	c.cache.SetDefault("network:"+strconv.Itoa(n.ID), n)
+}
+
+func (c *inMemoryMediaCache) GetNetwork(id int) *Studio {
+	n, ok := c.cache.Get("network:" + strconv.Itoa(id))
+	if !ok {
+		return nil
+	}
+	return n.(*Studio)
+}
+
+// The in-memory cache drops the expired entries, it has no stale values to serve.
+
+func (c *inMemoryMediaCache) GetStaleMovie(int) *Movie {
+	return nil
+}
+
+func (c *inMemoryMediaCache) GetStaleMovieShort(int) *Movie {
+	return nil
+}
+
+func (c *inMemoryMediaCache) GetStaleTV(int) *TVShow {
+	return nil
+}
+
+func (c *inMemoryMediaCache) GetStaleTVShort(int) *TVShow {
+	return nil
+}
+
+// redisMediaCache stores the cached values in Redis, under keys starting with prefix.
+// When staleGrace is set, the entries are kept staleGrace longer than their expiration so that
+// they can still be served by the GetStale methods when TMDB fails.
 type redisMediaCache struct {
-	client *redis.Client
+	client     *redis.Client
+	logger     logging.Logger
+	compress   bool
+	prefix     string
+	staleGrace time.Duration
 }
 
-func newRedisMediaCache(redisURL string, redisPassword string) mediaCache {
+func newRedisMediaCache(redisURL string, redisPassword string, options *clientOptions) mediaCache {
 	client := redis.NewClient(&redis.Options{
 		Addr:     redisURL,
 		Password: redisPassword,
 		DB:       0,
 	})
 	return &redisMediaCache{
-		client: client,
+		client:     client,
+		logger:     options.logger,
+		compress:   options.cacheCompression,
+		prefix:     options.cacheKeyPrefix,
+		staleGrace: options.staleGrace,
 	}
 }
 
 var (
 	defaultExpiration = 30 * 24 * time.Hour // 1 mois
 	oneWeekExpiration = 7 * 24 * time.Hour  // 1 semaine
+	oneDayExpiration  = 24 * time.Hour      // 1 jour
+	oneHourExpiration = time.Hour           // 1 heure
 )
 
 /*
@@ -369,6 +600,11 @@ var (
 - Saison -> Rétention 1 semaine
 - Résultat de recherche film / série -> 1 semaine de rétention
 - Genre et Acteur -> 1 mois rétention
+- Studio et chaîne -> 1 mois rétention
+- Films à l'affiche -> 1 jour de rétention
+- Existence d'un film / d'une série -> 1 heure de rétention
+- Disponibilité d'un film dans une région -> 1 jour de rétention
+- Avec WithStaleOnError, toutes les entrées sont conservées en plus pendant la période de grâce
 */
 
 func calculateExpirationDate(releaseDate string, defaultExpiration, recentExpiration time.Duration) time.Duration {
@@ -387,28 +623,213 @@ func calculateExpirationDate(releaseDate string, defaultExpiration, recentExpira
 	return defaultExpiration
 }
 
+// cacheSchemaVersion is the version of the shape of the values stored in Redis. Bump it whenever a cached type
+// gains, loses or changes a field: the entries written by an older version are then treated as misses and
+// refreshed from TMDB instead of being decoded into partially populated structs.
+const cacheSchemaVersion = 1
+
+// cacheEnvelope wraps the JSON of a value stored in Redis with the schema version it was written with.
+// ExpiresAt, the Unix time after which the value is stale, is only set when the entry outlives its
+// expiration (see WithStaleOnError).
+type cacheEnvelope struct {
+	Version   int                 `json:"v"`
+	ExpiresAt int64               `json:"e,omitempty"`
+	Data      jsoniter.RawMessage `json:"d"`
+}
+
+// expired reports whether the value of the envelope is stale.
+func (e *cacheEnvelope) expired() bool {
+	return e.ExpiresAt != 0 && time.Now().Unix() >= e.ExpiresAt
+}
+
+// set stores the JSON data at key, wrapped in an envelope carrying the current schema version.
+func (r *redisMediaCache) set(key string, data []byte, expiration time.Duration) {
+	envelope := cacheEnvelope{Version: cacheSchemaVersion, Data: data}
+	if r.staleGrace > 0 {
+		envelope.ExpiresAt = time.Now().Add(expiration).Unix()
+		expiration += r.staleGrace
+	}
+	value, err := json.Marshal(envelope)
+	if err != nil {
+		r.logger.Errorf("Error while marshalling cache envelope: %v", err)
+		return
+	}
+	if r.compress {
+		value, err = compressCacheValue(value)
+		if err != nil {
+			r.logger.Errorf("Error while compressing cache value: %v", err)
+			return
+		}
+	}
+	r.client.Set(r.prefix+key, value, expiration)
+}
+
+// get returns the JSON data stored at key by set.
+// It returns redis.Nil when there is no entry, when it is stale or when it was written with an older
+// schema version (including the entries written before versioning, which have no envelope).
+func (r *redisMediaCache) get(key string) ([]byte, error) {
+	value, err := r.client.Get(r.prefix + key).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	envelope, err := decodeCacheEnvelope(value)
+	if err != nil {
+		return nil, err
+	}
+	if envelope.expired() {
+		return nil, redis.Nil
+	}
+	return envelope.Data, nil
+}
+
+// getStale unmarshals into v the JSON data stored at key by set, even if it is stale,
+// and reports whether there was one.
+func (r *redisMediaCache) getStale(key string, v interface{}) bool {
+	value, err := r.client.Get(r.prefix + key).Bytes()
+	if err != nil {
+		return false
+	}
+	envelope, err := decodeCacheEnvelope(value)
+	if err != nil {
+		return false
+	}
+	if err := json.Unmarshal(envelope.Data, v); err != nil {
+		r.logger.Errorf("Error while unmarshalling stale cache value %s: %v", key, err)
+		return false
+	}
+	return true
+}
+
+// decodeCacheEnvelope returns the envelope of a value written by set, compressed or not,
+// or redis.Nil when it is outdated or cannot be decoded.
+func decodeCacheEnvelope(value []byte) (*cacheEnvelope, error) {
+	if len(value) > 0 && value[0] == cacheGzipHeader {
+		var err error
+		value, err = decompressCacheValue(value)
+		if err != nil {
+			return nil, redis.Nil
+		}
+	}
+	var envelope cacheEnvelope
+	if err := json.Unmarshal(value, &envelope); err != nil || envelope.Version < cacheSchemaVersion {
+		return nil, redis.Nil
+	}
+	return &envelope, nil
+}
+
+// cacheGzipHeader is the first byte of the compressed values. Uncompressed values are JSON objects starting
+// with '{', so compressed and uncompressed entries can be read whatever the current setting.
+const cacheGzipHeader byte = 0x01
+
+// compressCacheValue gzips the value behind cacheGzipHeader.
+func compressCacheValue(value []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte(cacheGzipHeader)
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(value); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decompressCacheValue returns the original value of a value compressed by compressCacheValue.
+func decompressCacheValue(value []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(value[1:]))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
 func (r *redisMediaCache) AddMovie(m *Movie) {
 	key := "movie:" + strconv.Itoa(m.ID)
 	expiration := calculateExpirationDate(m.ReleaseDate, defaultExpiration, oneWeekExpiration)
 
 	data, err := json.Marshal(m)
 	if err != nil {
-		log.Println("Error while marshalling movie", err)
+		r.logger.Errorf("Error while marshalling movie: %v", err)
 		return
 	}
-	r.client.Set(key, data, expiration)
+	r.set(key, data, expiration)
 }
 
 func (r *redisMediaCache) GetMovie(id int) *Movie {
 	key := "movie:" + strconv.Itoa(id)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var m Movie
 	err = json.Unmarshal(data, &m)
 	if err != nil {
-		log.Println("Error while unmarshalling movie", err)
+		r.logger.Errorf("Error while unmarshalling movie: %v", err)
+		return nil
+	}
+	return &m
+}
+
+// GetMovies returns the cached movies among ids, keyed by ID, fetched with a single MGET.
+// Unlike GetStaleMovie, it never returns stale entries (see WithStaleOnError): they are left out like missing
+// ones, so that MediaClient.GetMovies refreshes them through GetMovie, which serves them stale if TMDB fails.
+func (r *redisMediaCache) GetMovies(ids []int) map[int]*Movie {
+	movies := make(map[int]*Movie, len(ids))
+	if len(ids) == 0 {
+		return movies
+	}
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = r.prefix + "movie:" + strconv.Itoa(id)
+	}
+	values, err := r.client.MGet(keys...).Result()
+	if err != nil {
+		r.logger.Errorf("Error while retrieving movies: %v", err)
+		return movies
+	}
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+		envelope, err := decodeCacheEnvelope([]byte(raw))
+		if err != nil || envelope.expired() {
+			continue
+		}
+		var m Movie
+		if err := json.Unmarshal(envelope.Data, &m); err != nil {
+			r.logger.Errorf("Error while unmarshalling movie: %v", err)
+			continue
+		}
+		movies[ids[i]] = &m
+	}
+	return movies
+}
+
+func (r *redisMediaCache) AddMovieFull(m *Movie) {
+	key := "movie_full:" + strconv.Itoa(m.ID)
+	expiration := calculateExpirationDate(m.ReleaseDate, defaultExpiration, oneWeekExpiration)
+
+	data, err := json.Marshal(m)
+	if err != nil {
+		r.logger.Errorf("Error while marshalling movie full: %v", err)
+		return
+	}
+	r.set(key, data, expiration)
+}
+
+func (r *redisMediaCache) GetMovieFull(id int) *Movie {
+	key := "movie_full:" + strconv.Itoa(id)
+	data, err := r.get(key)
+	if err != nil {
+		return nil
+	}
+	var m Movie
+	err = json.Unmarshal(data, &m)
+	if err != nil {
+		r.logger.Errorf("Error while unmarshalling movie full: %v", err)
 		return nil
 	}
 	return &m
@@ -420,22 +841,22 @@ func (r *redisMediaCache) AddMovieShort(m *Movie) {
 
 	data, err := json.Marshal(m)
 	if err != nil {
-		log.Println("Error while marshalling movie short", err)
+		r.logger.Errorf("Error while marshalling movie short: %v", err)
 		return
 	}
-	r.client.Set(key, data, expiration)
+	r.set(key, data, expiration)
 }
 
 func (r *redisMediaCache) GetMovieShort(id int) *Movie {
 	key := "movie_short:" + strconv.Itoa(id)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var m Movie
 	err = json.Unmarshal(data, &m)
 	if err != nil {
-		log.Println("Error while unmarshalling movie short", err)
+		r.logger.Errorf("Error while unmarshalling movie short: %v", err)
 		return nil
 	}
 	return &m
@@ -447,22 +868,22 @@ func (r *redisMediaCache) AddTV(t *TVShow) {
 
 	data, err := json.Marshal(t)
 	if err != nil {
-		log.Println("Error while marshalling tv show", err)
+		r.logger.Errorf("Error while marshalling tv show: %v", err)
 		return
 	}
-	r.client.Set(key, data, expiration)
+	r.set(key, data, expiration)
 }
 
 func (r *redisMediaCache) GetTV(id int) *TVShow {
 	key := "tv:" + strconv.Itoa(id)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var t TVShow
 	err = json.Unmarshal(data, &t)
 	if err != nil {
-		log.Println("Error while unmarshalling tv show", err)
+		r.logger.Errorf("Error while unmarshalling tv show: %v", err)
 		return nil
 	}
 	return &t
@@ -474,22 +895,22 @@ func (r *redisMediaCache) AddTVShort(t *TVShow) {
 
 	data, err := json.Marshal(t)
 	if err != nil {
-		log.Println("Error while marshalling tv show short", err)
+		r.logger.Errorf("Error while marshalling tv show short: %v", err)
 		return
 	}
-	r.client.Set(key, data, expiration)
+	r.set(key, data, expiration)
 }
 
 func (r *redisMediaCache) GetTVShort(id int) *TVShow {
 	key := "tv_short:" + strconv.Itoa(id)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var t TVShow
 	err = json.Unmarshal(data, &t)
 	if err != nil {
-		log.Println("Error while unmarshalling tv show short", err)
+		r.logger.Errorf("Error while unmarshalling tv show short: %v", err)
 		return nil
 	}
 	return &t
@@ -501,22 +922,22 @@ func (r *redisMediaCache) AddEpisode(e *TVEpisode) {
 
 	data, err := json.Marshal(e)
 	if err != nil {
-		log.Println("Error while marshalling episode", err)
+		r.logger.Errorf("Error while marshalling episode: %v", err)
 		return
 	}
-	r.client.Set(key, data, expiration)
+	r.set(key, data, expiration)
 }
 
 func (r *redisMediaCache) GetEpisode(tvID int, seasonNumber int, episodeNumber int) *TVEpisode {
 	key := "episode:" + strconv.Itoa(tvID) + ":" + strconv.Itoa(seasonNumber) + ":" + strconv.Itoa(episodeNumber)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var e TVEpisode
 	err = json.Unmarshal(data, &e)
 	if err != nil {
-		log.Println("Error while unmarshalling episode", err)
+		r.logger.Errorf("Error while unmarshalling episode: %v", err)
 		return nil
 	}
 	return &e
@@ -526,10 +947,10 @@ func (r *redisMediaCache) AddSeason(tvID int, seasonNumber int, s []*TVEpisode)
 	key := "season:" + strconv.Itoa(tvID) + ":" + strconv.Itoa(seasonNumber)
 	data, err := json.Marshal(s)
 	if err != nil {
-		log.Println("Error while marshalling season", err)
+		r.logger.Errorf("Error while marshalling season: %v", err)
 		return
 	}
-	r.client.Set(key, data, defaultExpiration)
+	r.set(key, data, defaultExpiration)
 	for _, e := range s {
 		r.AddEpisode(e)
 	}
@@ -537,14 +958,14 @@ func (r *redisMediaCache) AddSeason(tvID int, seasonNumber int, s []*TVEpisode)
 
 func (r *redisMediaCache) GetSeason(tvID int, seasonNumber int) []*TVEpisode {
 	key := "season:" + strconv.Itoa(tvID) + ":" + strconv.Itoa(seasonNumber)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var s []*TVEpisode
 	err = json.Unmarshal(data, &s)
 	if err != nil {
-		log.Println("Error while unmarshalling season", err)
+		r.logger.Errorf("Error while unmarshalling season: %v", err)
 		return nil
 	}
 	return s
@@ -557,10 +978,10 @@ func (r *redisMediaCache) AddMovieSearchResults(query string, page int, adult bo
 	}
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling movie search results", err)
+		r.logger.Errorf("Error while marshalling movie search results: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) GetMovieSearchResults(query string, page int, adult bool) *PaginatedMovieResults {
@@ -568,31 +989,33 @@ func (r *redisMediaCache) GetMovieSearchResults(query string, page int, adult bo
 	if adult {
 		key += ":adult"
 	}
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results PaginatedMovieResults
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling movie search results", err)
+		r.logger.Errorf("Error while unmarshalling movie search results: %v", err)
 		return nil
 	}
+	results.Page = page
 	return &results
 }
 
 func (r *redisMediaCache) GetMovieSearchResultsYear(query string, page int, year string) *PaginatedMovieResults {
 	key := "movie_search:" + query + ":" + strconv.Itoa(page) + ":" + year
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results PaginatedMovieResults
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling movie search results", err)
+		r.logger.Errorf("Error while unmarshalling movie search results: %v", err)
 		return nil
 	}
+	results.Page = page
 	return &results
 }
 
@@ -600,10 +1023,10 @@ func (r *redisMediaCache) AddMovieSearchResultsYear(query string, page int, year
 	key := "movie_search:" + query + ":" + strconv.Itoa(page) + ":" + year
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling movie search results", err)
+		r.logger.Errorf("Error while marshalling movie search results: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) AddTVSearchResults(query string, page int, adult bool, results *PaginatedTVShowResults) {
@@ -613,10 +1036,10 @@ func (r *redisMediaCache) AddTVSearchResults(query string, page int, adult bool,
 	}
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling tv search results", err)
+		r.logger.Errorf("Error while marshalling tv search results: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) GetTVSearchResults(query string, page int, adult bool) *PaginatedTVShowResults {
@@ -624,16 +1047,17 @@ func (r *redisMediaCache) GetTVSearchResults(query string, page int, adult bool)
 	if adult {
 		key += ":adult"
 	}
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results PaginatedTVShowResults
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling tv search results", err)
+		r.logger.Errorf("Error while unmarshalling tv search results: %v", err)
 		return nil
 	}
+	results.Page = page
 	return &results
 }
 
@@ -641,22 +1065,22 @@ func (r *redisMediaCache) AddMovieGenre(genre *Genre) {
 	key := "movie_genre:" + strconv.Itoa(genre.ID)
 	data, err := json.Marshal(genre)
 	if err != nil {
-		log.Println("Error while marshalling movie genre", err)
+		r.logger.Errorf("Error while marshalling movie genre: %v", err)
 		return
 	}
-	r.client.Set(key, data, defaultExpiration)
+	r.set(key, data, defaultExpiration)
 }
 
 func (r *redisMediaCache) GetMovieGenre(id int) *Genre {
 	key := "movie_genre:" + strconv.Itoa(id)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var g Genre
 	err = json.Unmarshal(data, &g)
 	if err != nil {
-		log.Println("Error while unmarshalling movie genre", err)
+		r.logger.Errorf("Error while unmarshalling movie genre: %v", err)
 		return nil
 	}
 	return &g
@@ -666,22 +1090,22 @@ func (r *redisMediaCache) AddTVGenre(genre *Genre) {
 	key := "tv_genre:" + strconv.Itoa(genre.ID)
 	data, err := json.Marshal(genre)
 	if err != nil {
-		log.Println("Error while marshalling tv genre", err)
+		r.logger.Errorf("Error while marshalling tv genre: %v", err)
 		return
 	}
-	r.client.Set(key, data, defaultExpiration)
+	r.set(key, data, defaultExpiration)
 }
 
 func (r *redisMediaCache) GetTVGenre(id int) *Genre {
 	key := "tv_genre:" + strconv.Itoa(id)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var g Genre
 	err = json.Unmarshal(data, &g)
 	if err != nil {
-		log.Println("Error while unmarshalling tv genre", err)
+		r.logger.Errorf("Error while unmarshalling tv genre: %v", err)
 		return nil
 	}
 	return &g
@@ -691,22 +1115,22 @@ func (r *redisMediaCache) AddActor(actor *Actor) {
 	key := "actor:" + strconv.Itoa(actor.ID)
 	data, err := json.Marshal(actor)
 	if err != nil {
-		log.Println("Error while marshalling actor", err)
+		r.logger.Errorf("Error while marshalling actor: %v", err)
 		return
 	}
-	r.client.Set(key, data, defaultExpiration)
+	r.set(key, data, defaultExpiration)
 }
 
 func (r *redisMediaCache) GetActor(id int) *Actor {
 	key := "actor:" + strconv.Itoa(id)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var a Actor
 	err = json.Unmarshal(data, &a)
 	if err != nil {
-		log.Println("Error while unmarshalling actor", err)
+		r.logger.Errorf("Error while unmarshalling actor: %v", err)
 		return nil
 	}
 	return &a
@@ -716,24 +1140,25 @@ func (r *redisMediaCache) AddMoviesByGenre(genreID int, page int, results *Pagin
 	key := "movie_genre:" + strconv.Itoa(genreID) + ":" + strconv.Itoa(page)
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling movie genre results", err)
+		r.logger.Errorf("Error while marshalling movie genre results: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) GetMoviesByGenre(genreID int, page int) *PaginatedMovieResults {
 	key := "movie_genre:" + strconv.Itoa(genreID) + ":" + strconv.Itoa(page)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results PaginatedMovieResults
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling movie genre results", err)
+		r.logger.Errorf("Error while unmarshalling movie genre results: %v", err)
 		return nil
 	}
+	results.Page = page
 	return &results
 }
 
@@ -741,24 +1166,25 @@ func (r *redisMediaCache) AddTVsByGenre(genreID int, page int, results *Paginate
 	key := "tv_genre:" + strconv.Itoa(genreID) + ":" + strconv.Itoa(page)
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling tv genre results", err)
+		r.logger.Errorf("Error while marshalling tv genre results: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) GetTVsByGenre(genreID int, page int) *PaginatedTVShowResults {
 	key := "tv_genre:" + strconv.Itoa(genreID) + ":" + strconv.Itoa(page)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results PaginatedTVShowResults
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling tv genre results", err)
+		r.logger.Errorf("Error while unmarshalling tv genre results: %v", err)
 		return nil
 	}
+	results.Page = page
 	return &results
 }
 
@@ -766,24 +1192,25 @@ func (r *redisMediaCache) AddMoviesByActor(actorID int, page int, results *Pagin
 	key := "movie_actor:" + strconv.Itoa(actorID) + ":" + strconv.Itoa(page)
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling movie actor results", err)
+		r.logger.Errorf("Error while marshalling movie actor results: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) GetMoviesByActor(actorID int, page int) *PaginatedMovieResults {
 	key := "movie_actor:" + strconv.Itoa(actorID) + ":" + strconv.Itoa(page)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results PaginatedMovieResults
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling movie actor results", err)
+		r.logger.Errorf("Error while unmarshalling movie actor results: %v", err)
 		return nil
 	}
+	results.Page = page
 	return &results
 }
 
@@ -791,24 +1218,25 @@ func (r *redisMediaCache) AddTVsByActor(actorID int, page int, results *Paginate
 	key := "tv_actor:" + strconv.Itoa(actorID) + ":" + strconv.Itoa(page)
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling tv actor results", err)
+		r.logger.Errorf("Error while marshalling tv actor results: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) GetTVsByActor(actorID int, page int) *PaginatedTVShowResults {
 	key := "tv_actor:" + strconv.Itoa(actorID) + ":" + strconv.Itoa(page)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results PaginatedTVShowResults
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling tv actor results", err)
+		r.logger.Errorf("Error while unmarshalling tv actor results: %v", err)
 		return nil
 	}
+	results.Page = page
 	return &results
 }
 
@@ -816,24 +1244,25 @@ func (r *redisMediaCache) AddMoviesByStudio(studioID int, page int, results *Pag
 	key := "movie_studio:" + strconv.Itoa(studioID) + ":" + strconv.Itoa(page)
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling movie studio results", err)
+		r.logger.Errorf("Error while marshalling movie studio results: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) GetMoviesByStudio(studioID int, page int) *PaginatedMovieResults {
 	key := "movie_studio:" + strconv.Itoa(studioID) + ":" + strconv.Itoa(page)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results PaginatedMovieResults
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling movie studio results", err)
+		r.logger.Errorf("Error while unmarshalling movie studio results: %v", err)
 		return nil
 	}
+	results.Page = page
 	return &results
 }
 
@@ -841,24 +1270,25 @@ func (r *redisMediaCache) AddTVsByNetwork(networkID int, page int, results *Pagi
 	key := "tv_network:" + strconv.Itoa(networkID) + ":" + strconv.Itoa(page)
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling tv network results", err)
+		r.logger.Errorf("Error while marshalling tv network results: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) GetTVsByNetwork(networkID int, page int) *PaginatedTVShowResults {
 	key := "tv_network:" + strconv.Itoa(networkID) + ":" + strconv.Itoa(page)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results PaginatedTVShowResults
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling tv network results", err)
+		r.logger.Errorf("Error while unmarshalling tv network results: %v", err)
 		return nil
 	}
+	results.Page = page
 	return &results
 }
 
@@ -866,22 +1296,22 @@ func (r *redisMediaCache) AddMovieRecommendations(movieID int, results []*Movie)
 	key := "movie_recommendations:" + strconv.Itoa(movieID)
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling movie recommendations", err)
+		r.logger.Errorf("Error while marshalling movie recommendations: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) GetMovieRecommendations(movieID int) []*Movie {
 	key := "movie_recommendations:" + strconv.Itoa(movieID)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results []*Movie
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling movie recommendations", err)
+		r.logger.Errorf("Error while unmarshalling movie recommendations: %v", err)
 		return nil
 	}
 	return results
@@ -891,22 +1321,22 @@ func (r *redisMediaCache) AddTVRecommendations(tvID int, results []*TVShow) {
 	key := "tv_recommendations:" + strconv.Itoa(tvID)
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling tv recommendations", err)
+		r.logger.Errorf("Error while marshalling tv recommendations: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) GetTVRecommendations(tvID int) []*TVShow {
 	key := "tv_recommendations:" + strconv.Itoa(tvID)
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results []*TVShow
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling tv recommendations", err)
+		r.logger.Errorf("Error while unmarshalling tv recommendations: %v", err)
 		return nil
 	}
 	return results
@@ -919,10 +1349,10 @@ func (r *redisMediaCache) AddActorSearchResults(query string, page int, adult bo
 	}
 	data, err := json.Marshal(results)
 	if err != nil {
-		log.Println("Error while marshalling actor search results", err)
+		r.logger.Errorf("Error while marshalling actor search results: %v", err)
 		return
 	}
-	r.client.Set(key, data, oneWeekExpiration)
+	r.set(key, data, oneWeekExpiration)
 }
 
 func (r *redisMediaCache) GetActorSearchResults(query string, page int, adult bool) *PaginatedActorResults {
@@ -930,15 +1360,331 @@ func (r *redisMediaCache) GetActorSearchResults(query string, page int, adult bo
 	if adult {
 		key += ":adult"
 	}
-	data, err := r.client.Get(key).Bytes()
+	data, err := r.get(key)
 	if err != nil {
 		return nil
 	}
 	var results PaginatedActorResults
 	err = json.Unmarshal(data, &results)
 	if err != nil {
-		log.Println("Error while unmarshalling actor search results", err)
+		r.logger.Errorf("Error while unmarshalling actor search results: %v", err)
 		return nil
 	}
+	results.Page = page
 	return &results
 }
+
+func (r *redisMediaCache) AddFindResult(imdbID string, result *FindResult) {
+	key := "find:" + imdbID
+	data, err := json.Marshal(result)
+	if err != nil {
+		r.logger.Errorf("Error while marshalling find result: %v", err)
+		return
+	}
+	r.set(key, data, defaultExpiration)
+}
+
+func (r *redisMediaCache) GetFindResult(imdbID string) *FindResult {
+	key := "find:" + imdbID
+	data, err := r.get(key)
+	if err != nil {
+		return nil
+	}
+	var result FindResult
+	err = json.Unmarshal(data, &result)
+	if err != nil {
+		r.logger.Errorf("Error while unmarshalling find result: %v", err)
+		return nil
+	}
+	return &result
+}
+
+func (r *redisMediaCache) AddMovieCredits(movieID int, c *credits) {
+	key := "movie_credits:" + strconv.Itoa(movieID)
+	data, err := json.Marshal(c)
+	if err != nil {
+		r.logger.Errorf("Error while marshalling movie credits: %v", err)
+		return
+	}
+	r.set(key, data, oneWeekExpiration)
+}
+
+func (r *redisMediaCache) GetMovieCredits(movieID int) *credits {
+	key := "movie_credits:" + strconv.Itoa(movieID)
+	data, err := r.get(key)
+	if err != nil {
+		return nil
+	}
+	var c credits
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		r.logger.Errorf("Error while unmarshalling movie credits: %v", err)
+		return nil
+	}
+	return &c
+}
+
+func (r *redisMediaCache) AddTVCredits(tvID int, c *credits) {
+	key := "tv_credits:" + strconv.Itoa(tvID)
+	data, err := json.Marshal(c)
+	if err != nil {
+		r.logger.Errorf("Error while marshalling tv credits: %v", err)
+		return
+	}
+	r.set(key, data, oneWeekExpiration)
+}
+
+func (r *redisMediaCache) GetTVCredits(tvID int) *credits {
+	key := "tv_credits:" + strconv.Itoa(tvID)
+	data, err := r.get(key)
+	if err != nil {
+		return nil
+	}
+	var c credits
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		r.logger.Errorf("Error while unmarshalling tv credits: %v", err)
+		return nil
+	}
+	return &c
+}
+
+func (r *redisMediaCache) AddPersonImages(personID int, images []string) {
+	key := "person_images:" + strconv.Itoa(personID)
+	data, err := json.Marshal(images)
+	if err != nil {
+		r.logger.Errorf("Error while marshalling person images: %v", err)
+		return
+	}
+	r.set(key, data, defaultExpiration)
+}
+
+func (r *redisMediaCache) GetPersonImages(personID int) []string {
+	key := "person_images:" + strconv.Itoa(personID)
+	data, err := r.get(key)
+	if err != nil {
+		return nil
+	}
+	var images []string
+	err = json.Unmarshal(data, &images)
+	if err != nil {
+		r.logger.Errorf("Error while unmarshalling person images: %v", err)
+		return nil
+	}
+	return images
+}
+
+func (r *redisMediaCache) AddNowPlayingMovies(page int, results *PaginatedMovieResults) {
+	key := "movie_now_playing:" + strconv.Itoa(page)
+	data, err := json.Marshal(results)
+	if err != nil {
+		r.logger.Errorf("Error while marshalling now playing movies: %v", err)
+		return
+	}
+	r.set(key, data, oneDayExpiration)
+}
+
+func (r *redisMediaCache) GetNowPlayingMovies(page int) *PaginatedMovieResults {
+	key := "movie_now_playing:" + strconv.Itoa(page)
+	data, err := r.get(key)
+	if err != nil {
+		return nil
+	}
+	var results PaginatedMovieResults
+	err = json.Unmarshal(data, &results)
+	if err != nil {
+		r.logger.Errorf("Error while unmarshalling now playing movies: %v", err)
+		return nil
+	}
+	results.Page = page
+	return &results
+}
+
+func (r *redisMediaCache) AddMovieExists(id int, exists bool) {
+	key := "movie_exists:" + strconv.Itoa(id)
+	r.client.Set(r.prefix+key, strconv.FormatBool(exists), oneHourExpiration)
+}
+
+func (r *redisMediaCache) GetMovieExists(id int) (bool, bool) {
+	key := "movie_exists:" + strconv.Itoa(id)
+	data, err := r.client.Get(r.prefix + key).Result()
+	if err != nil {
+		return false, false
+	}
+	exists, err := strconv.ParseBool(data)
+	if err != nil {
+		r.logger.Errorf("Error while parsing movie exists: %v", err)
+		return false, false
+	}
+	return exists, true
+}
+
+func (r *redisMediaCache) AddTVExists(id int, exists bool) {
+	key := "tv_exists:" + strconv.Itoa(id)
+	r.client.Set(r.prefix+key, strconv.FormatBool(exists), oneHourExpiration)
+}
+
+func (r *redisMediaCache) GetTVExists(id int) (bool, bool) {
+	key := "tv_exists:" + strconv.Itoa(id)
+	data, err := r.client.Get(r.prefix + key).Result()
+	if err != nil {
+		return false, false
+	}
+	exists, err := strconv.ParseBool(data)
+	if err != nil {
+		r.logger.Errorf("Error while parsing tv exists: %v", err)
+		return false, false
+	}
+	return exists, true
+}
+
+func (r *redisMediaCache) AddCollectionMovies(collectionID int, movies []*Movie) {
+	key := "collection:" + strconv.Itoa(collectionID)
+	data, err := json.Marshal(movies)
+	if err != nil {
+		r.logger.Errorf("Error while marshalling collection movies: %v", err)
+		return
+	}
+	r.set(key, data, oneWeekExpiration)
+}
+
+func (r *redisMediaCache) GetCollectionMovies(collectionID int) []*Movie {
+	key := "collection:" + strconv.Itoa(collectionID)
+	data, err := r.get(key)
+	if err != nil {
+		return nil
+	}
+	var movies []*Movie
+	err = json.Unmarshal(data, &movies)
+	if err != nil {
+		r.logger.Errorf("Error while unmarshalling collection movies: %v", err)
+		return nil
+	}
+	return movies
+}
+
+func (r *redisMediaCache) AddMovieAvailability(movieID int, region string, available bool) {
+	key := "movie_availability:" + strconv.Itoa(movieID) + ":" + region
+	r.client.Set(r.prefix+key, strconv.FormatBool(available), oneDayExpiration)
+}
+
+func (r *redisMediaCache) GetMovieAvailability(movieID int, region string) (bool, bool) {
+	key := "movie_availability:" + strconv.Itoa(movieID) + ":" + region
+	data, err := r.client.Get(r.prefix + key).Result()
+	if err != nil {
+		return false, false
+	}
+	available, err := strconv.ParseBool(data)
+	if err != nil {
+		r.logger.Errorf("Error while parsing movie availability: %v", err)
+		return false, false
+	}
+	return available, true
+}
+
+func (r *redisMediaCache) AddTVSeasonCredits(tvID int, seasonNumber int, c *credits) {
+	key := "tv_season_credits:" + strconv.Itoa(tvID) + ":" + strconv.Itoa(seasonNumber)
+	data, err := json.Marshal(c)
+	if err != nil {
+		r.logger.Errorf("Error while marshalling tv season credits: %v", err)
+		return
+	}
+	r.set(key, data, oneWeekExpiration)
+}
+
+func (r *redisMediaCache) GetTVSeasonCredits(tvID int, seasonNumber int) *credits {
+	key := "tv_season_credits:" + strconv.Itoa(tvID) + ":" + strconv.Itoa(seasonNumber)
+	data, err := r.get(key)
+	if err != nil {
+		return nil
+	}
+	var c credits
+	err = json.Unmarshal(data, &c)
+	if err != nil {
+		r.logger.Errorf("Error while unmarshalling tv season credits: %v", err)
+		return nil
+	}
+	return &c
+}
+
+func (r *redisMediaCache) AddStudio(s *Studio) {
+	key := "studio:" + strconv.Itoa(s.ID)
+	data, err := json.Marshal(s)
+	if err != nil {
+		r.logger.Errorf("Error while marshalling studio: %v", err)
+		return
+	}
+	r.set(key, data, defaultExpiration)
+}
+
+func (r *redisMediaCache) GetStudio(id int) *Studio {
+	key := "studio:" + strconv.Itoa(id)
+	data, err := r.get(key)
+	if err != nil {
+		return nil
+	}
+	var s Studio
+	err = json.Unmarshal(data, &s)
+	if err != nil {
+		r.logger.Errorf("Error while unmarshalling studio: %v", err)
+		return nil
+	}
+	return &s
+}
+
+func (r *redisMediaCache) AddNetwork(n *Studio) {
+	key := "network:" + strconv.Itoa(n.ID)
+	data, err := json.Marshal(n)
+	if err != nil {
+		r.logger.Errorf("Error while marshalling network: %v", err)
+		return
+	}
+	r.set(key, data, defaultExpiration)
+}
+
+func (r *redisMediaCache) GetNetwork(id int) *Studio {
+	key := "network:" + strconv.Itoa(id)
+	data, err := r.get(key)
+	if err != nil {
+		return nil
+	}
+	var n Studio
+	err = json.Unmarshal(data, &n)
+	if err != nil {
+		r.logger.Errorf("Error while unmarshalling network: %v", err)
+		return nil
+	}
+	return &n
+}
+
+func (r *redisMediaCache) GetStaleMovie(id int) *Movie {
+	var m Movie
+	if !r.getStale("movie:"+strconv.Itoa(id), &m) {
+		return nil
+	}
+	return &m
+}
+
+func (r *redisMediaCache) GetStaleMovieShort(id int) *Movie {
+	var m Movie
+	if !r.getStale("movie_short:"+strconv.Itoa(id), &m) {
+		return nil
+	}
+	return &m
+}
+
+func (r *redisMediaCache) GetStaleTV(id int) *TVShow {
+	var t TVShow
+	if !r.getStale("tv:"+strconv.Itoa(id), &t) {
+		return nil
+	}
+	return &t
+}
+
+func (r *redisMediaCache) GetStaleTVShort(id int) *TVShow {
+	var t TVShow
+	if !r.getStale("tv_short:"+strconv.Itoa(id), &t) {
+		return nil
+	}
+	return &t
+}
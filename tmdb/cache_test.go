@@ -0,0 +1,340 @@
+package tmdb
+
+import (
+	"errors"
+	"fmt"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/bingemate/media-go-pkg/logging"
+	"github.com/ryanbradynd05/go-tmdb"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// newTestRedisCache returns a Redis cache backed by an in-memory Redis server.
+func newTestRedisCache(t *testing.T) (*redisMediaCache, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	return newTestRedisCacheOn(t, server), server
+}
+
+// newTestRedisCacheOn returns a Redis cache using server, configured with opts.
+func newTestRedisCacheOn(t *testing.T, server *miniredis.Miniredis, opts ...Option) *redisMediaCache {
+	t.Helper()
+	opts = append([]Option{WithLogger(logging.NewStdLogger(logging.LevelError))}, opts...)
+	cache := newRedisMediaCache(server.Addr(), "", newClientOptions(opts)).(*redisMediaCache)
+	t.Cleanup(func() { cache.client.Close() })
+	return cache
+}
+
+func TestRedisCachePaginatedResultsPage(t *testing.T) {
+	cache, server := newTestRedisCache(t)
+	// Written before Page existed
+	server.Set("movie_genre:28:2", `{"v":1,"d":{"TotalPage":5,"TotalResult":100,"Results":[{"id":550}]}}`)
+	server.Set("tv_network:49:3", `{"v":1,"d":{"TotalPage":5,"TotalResult":100,"Results":[{"id":1399}]}}`)
+
+	movies := cache.GetMoviesByGenre(28, 2)
+	if movies == nil || movies.Page != 2 || len(movies.Results) != 1 {
+		t.Errorf("GetMoviesByGenre() = %+v, want page 2 with one movie", movies)
+	}
+	tvShows := cache.GetTVsByNetwork(49, 3)
+	if tvShows == nil || tvShows.Page != 3 || !tvShows.HasNext() {
+		t.Errorf("GetTVsByNetwork() = %+v, want page 3 of 5", tvShows)
+	}
+
+	cache.AddNowPlayingMovies(1, &PaginatedMovieResults{Page: 1, TotalPage: 2})
+	if nowPlaying := cache.GetNowPlayingMovies(1); nowPlaying == nil || nowPlaying.Page != 1 || nowPlaying.NextPage() != 2 {
+		t.Errorf("GetNowPlayingMovies() = %+v, want page 1 of 2", nowPlaying)
+	}
+	if cache.GetMoviesByGenre(28, 3) != nil {
+		t.Error("GetMoviesByGenre() of an uncached page != nil")
+	}
+}
+
+func TestRedisCacheExists(t *testing.T) {
+	cache, server := newTestRedisCache(t)
+
+	if _, ok := cache.GetMovieExists(27205); ok {
+		t.Error("GetMovieExists() of an unknown movie is cached")
+	}
+	cache.AddMovieExists(27205, true)
+	cache.AddTVExists(1, false)
+	if exists, ok := cache.GetMovieExists(27205); !ok || !exists {
+		t.Errorf("GetMovieExists() = %v, %v, want a cached true", exists, ok)
+	}
+	if exists, ok := cache.GetTVExists(1); !ok || exists {
+		t.Errorf("GetTVExists() = %v, %v, want a cached false", exists, ok)
+	}
+	if ttl := server.TTL("movie_exists:27205"); ttl != time.Hour {
+		t.Errorf("TTL = %v, want 1h", ttl)
+	}
+
+	server.Set("tv_exists:2", "peut-être")
+	if _, ok := cache.GetTVExists(2); ok {
+		t.Error("GetTVExists() of an invalid value is cached")
+	}
+}
+
+func TestRedisCacheStudioAndNetwork(t *testing.T) {
+	cache, server := newTestRedisCache(t)
+
+	if cache.GetStudio(420) != nil {
+		t.Error("GetStudio() of an unknown studio != nil")
+	}
+	cache.AddStudio(&Studio{ID: 420, Name: "Marvel Studios"})
+	cache.AddNetwork(&Studio{ID: 420, Name: "Marvel Television"})
+	if studio := cache.GetStudio(420); studio == nil || studio.Name != "Marvel Studios" {
+		t.Errorf("GetStudio() = %+v, want Marvel Studios", studio)
+	}
+	// studios and networks share IDs, not keys
+	if network := cache.GetNetwork(420); network == nil || network.Name != "Marvel Television" {
+		t.Errorf("GetNetwork() = %+v, want Marvel Television", network)
+	}
+	if ttl := server.TTL("network:420"); ttl != defaultExpiration {
+		t.Errorf("TTL = %v, want %v", ttl, defaultExpiration)
+	}
+}
+
+func TestRedisCacheVersionedEntries(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		hit   bool
+	}{
+		{"current version", `{"v":1,"d":{"id":550,"title":"Fight Club"}}`, true},
+		{"newer version", `{"v":2,"d":{"id":550,"title":"Fight Club"}}`, true},
+		{"older version", `{"v":0,"d":{"id":550,"title":"Fight Club"}}`, false},
+		{"written before versioning", `{"id":550,"title":"Fight Club"}`, false},
+		{"not JSON", `Fight Club`, false},
+		{"corrupted compression", "\x01not gzip", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache, server := newTestRedisCache(t)
+			server.Set("movie:550", tt.value)
+
+			movie := cache.GetMovie(550)
+			if hit := movie != nil; hit != tt.hit {
+				t.Fatalf("GetMovie() = %+v, want a hit: %v", movie, tt.hit)
+			}
+			if tt.hit && movie.Title != "Fight Club" {
+				t.Errorf("GetMovie() title = %q, want Fight Club", movie.Title)
+			}
+		})
+	}
+}
+
+func TestRedisCacheWritesCurrentVersion(t *testing.T) {
+	cache, server := newTestRedisCache(t)
+	cache.AddMovie(&Movie{ID: 550, Title: "Fight Club"})
+
+	value, err := server.Get("movie:550")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var envelope cacheEnvelope
+	if err := json.Unmarshal([]byte(value), &envelope); err != nil {
+		t.Fatalf("entry %q is not an envelope: %v", value, err)
+	}
+	if envelope.Version != cacheSchemaVersion {
+		t.Errorf("entry written with version %d, want %d", envelope.Version, cacheSchemaVersion)
+	}
+}
+
+func TestCompressCacheValue(t *testing.T) {
+	value := []byte(`{"v":1,"d":{"id":550,"title":"Fight Club"}}`)
+	compressed, err := compressCacheValue(value)
+	if err != nil {
+		t.Fatalf("compressCacheValue() = %v", err)
+	}
+	if compressed[0] != cacheGzipHeader {
+		t.Errorf("compressed value starts with %#x, want %#x", compressed[0], cacheGzipHeader)
+	}
+	decompressed, err := decompressCacheValue(compressed)
+	if err != nil {
+		t.Fatalf("decompressCacheValue() = %v", err)
+	}
+	if string(decompressed) != string(value) {
+		t.Errorf("decompressCacheValue() = %s, want %s", decompressed, value)
+	}
+}
+
+func TestRedisCacheCompressionRoundTrip(t *testing.T) {
+	tests := []struct {
+		name         string
+		writeOptions []Option
+		readOptions  []Option
+		compressed   bool
+	}{
+		{"uncompressed", nil, nil, false},
+		{"compressed", []Option{WithCacheCompression()}, []Option{WithCacheCompression()}, true},
+		{"compressed read without compression", []Option{WithCacheCompression()}, nil, true},
+		{"uncompressed read with compression", nil, []Option{WithCacheCompression()}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := miniredis.RunT(t)
+			writer := newTestRedisCacheOn(t, server, tt.writeOptions...)
+			writer.AddMovie(&Movie{ID: 550, Title: "Fight Club"})
+
+			value, err := server.Get("movie:550")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if compressed := value[0] == cacheGzipHeader; compressed != tt.compressed {
+				t.Errorf("stored value compressed: %v, want %v", compressed, tt.compressed)
+			}
+
+			reader := newTestRedisCacheOn(t, server, tt.readOptions...)
+			movie := reader.GetMovie(550)
+			if movie == nil || movie.Title != "Fight Club" {
+				t.Errorf("GetMovie() = %+v, want Fight Club", movie)
+			}
+		})
+	}
+}
+
+// newTestRedisClient returns a client with a Redis cache backed by an in-memory Redis server, calling the given
+// fake instead of TMDB.
+func newTestRedisClient(t *testing.T, fake *fakeTMDB, opts ...Option) (*mediaClient, *miniredis.Miniredis) {
+	t.Helper()
+	server := miniredis.RunT(t)
+	opts = append([]Option{WithLogger(logging.NewStdLogger(logging.LevelError))}, opts...)
+	client := NewRedisMediaClient("key", server.Addr(), "", opts...).(*mediaClient)
+	client.tmdbClient = fake
+	t.Cleanup(func() { client.cache.(*redisMediaCache).client.Close() })
+	return client, server
+}
+
+func TestGetMoviesReadsCacheWithSingleMGet(t *testing.T) {
+	ids := []int{5, 3, 1, 4, 2}
+	fake := &fakeTMDB{movies: make(map[int]*tmdb.Movie)}
+	for _, id := range ids {
+		fake.movies[id] = &tmdb.Movie{ID: id, Title: fmt.Sprintf("Film %d", id)}
+	}
+	client, server := newTestRedisClient(t, fake)
+	for _, id := range ids {
+		if _, err := client.GetMovie(id); err != nil {
+			t.Fatal(err)
+		}
+	}
+	tmdbCalls, commands := fake.callCount(), server.CommandCount()
+
+	movies, err := client.GetMovies(ids)
+	if err != nil {
+		t.Fatalf("GetMovies() = %v", err)
+	}
+	for i, movie := range movies {
+		if movie.ID != ids[i] {
+			t.Errorf("movie %d has ID %d, want %d", i, movie.ID, ids[i])
+		}
+	}
+	if got := server.CommandCount() - commands; got != 1 {
+		t.Errorf("GetMovies() sent %d Redis commands, want a single MGET", got)
+	}
+	if got := fake.callCount() - tmdbCalls; got != 0 {
+		t.Errorf("GetMovies() called TMDB %d times for cached movies, want 0", got)
+	}
+}
+
+func TestRedisCacheKeyPrefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		want   []string
+	}{
+		{"no prefix", "", []string{"movie:550", "movie_exists:550"}},
+		{"prefix", "prod:media:", []string{"prod:media:movie:550", "prod:media:movie_exists:550"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := miniredis.RunT(t)
+			cache := newTestRedisCacheOn(t, server, WithCacheKeyPrefix(tt.prefix))
+			cache.AddMovie(&Movie{ID: 550, Title: "Fight Club"})
+			cache.AddMovieExists(550, true)
+
+			if got := server.Keys(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("keys = %v, want %v", got, tt.want)
+			}
+			if movie := cache.GetMovie(550); movie == nil {
+				t.Error("GetMovie() = nil, want the movie stored under the prefixed key")
+			}
+			if movies := cache.GetMovies([]int{550}); movies[550] == nil {
+				t.Error("GetMovies() misses the movie stored under the prefixed key")
+			}
+			if exists, ok := cache.GetMovieExists(550); !ok || !exists {
+				t.Errorf("GetMovieExists() = %v, %v, want true, true", exists, ok)
+			}
+		})
+	}
+}
+
+func TestGetMoviesServesStaleMovies(t *testing.T) {
+	fake := &fakeTMDB{movies: map[int]*tmdb.Movie{2: {ID: 2, Title: "Film 2"}}}
+	client, server := newTestRedisClient(t, fake, WithStaleOnError(time.Hour))
+	server.Set("movie:1", `{"v":1,"e":1,"d":{"id":1,"title":"Film 1"}}`)
+	if _, err := client.GetMovie(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := client.cache.GetMovies([]int{1, 2}); got[1] != nil || got[2] == nil {
+		t.Errorf("cache GetMovies() = %v, want only the fresh movie 2", got)
+	}
+
+	fake.err = errors.New("503 Service Unavailable")
+	movies, err := client.GetMovies([]int{1, 2})
+	if err != nil {
+		t.Fatalf("GetMovies() = %v, want the stale movie without error", err)
+	}
+	if movies[0].Title != "Film 1" || movies[1].ID != 2 {
+		t.Errorf("GetMovies() = %+v, %+v, want the stale movie 1 and the cached movie 2", movies[0], movies[1])
+	}
+}
+
+func TestStaleOnError(t *testing.T) {
+	serverErr := errors.New("Code (11): Internal error.")
+	notFoundErr := errors.New("Code (34): The resource you requested could not be found.")
+	tests := []struct {
+		name      string
+		opts      []Option
+		tmdbErr   error
+		wantStale bool
+		wantErr   error
+	}{
+		{"server error", []Option{WithStaleOnError(time.Hour)}, serverErr, true, ErrServer},
+		{"not found", []Option{WithStaleOnError(time.Hour)}, notFoundErr, false, ErrNotFound},
+		{"without stale on error", nil, serverErr, false, ErrServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTMDB{err: tt.tmdbErr}
+			client, server := newTestRedisClient(t, fake, tt.opts...)
+			server.Set("movie:1", `{"v":1,"e":1,"d":{"id":1,"title":"Film 1"}}`)
+			server.Set("tv:1", `{"v":1,"e":1,"d":{"id":1,"title":"Série 1"}}`)
+
+			movie, err := client.GetMovie(1)
+			checkStale(t, "GetMovie", movie != nil, err, tt.wantStale, tt.wantErr)
+			if tt.wantStale && movie.Title != "Film 1" {
+				t.Errorf("GetMovie() = %+v, want the stale movie", movie)
+			}
+			tvShow, err := client.GetTVShow(1)
+			checkStale(t, "GetTVShow", tvShow != nil, err, tt.wantStale, tt.wantErr)
+			if tt.wantStale && tvShow.Title != "Série 1" {
+				t.Errorf("GetTVShow() = %+v, want the stale TV show", tvShow)
+			}
+		})
+	}
+}
+
+func checkStale(t *testing.T, call string, served bool, err error, wantStale bool, wantErr error) {
+	t.Helper()
+	if served != wantStale {
+		t.Errorf("%s() served a value: %v, want %v", call, served, wantStale)
+	}
+	if errors.Is(err, ErrStale) != wantStale {
+		t.Errorf("%s() = %v, want ErrStale: %v", call, err, wantStale)
+	}
+	if !errors.Is(err, wantErr) {
+		t.Errorf("%s() = %v, want it to wrap %v", call, err, wantErr)
+	}
+}
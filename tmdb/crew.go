@@ -0,0 +1,68 @@
+package tmdb
+
+import (
+	"sort"
+)
+
+// jobRanks orders the most prominent jobs of each department, lower first.
+// Jobs missing from this list come after the ranked ones, in their original order.
+var jobRanks = map[string]int{
+	"Director":                  0,
+	"Co-Director":               1,
+	"Assistant Director":        2,
+	"Screenplay":                0,
+	"Writer":                    1,
+	"Story":                     2,
+	"Novel":                     3,
+	"Characters":                4,
+	"Producer":                  0,
+	"Executive Producer":        1,
+	"Co-Producer":               2,
+	"Casting":                   3,
+	"Director of Photography":   0,
+	"Camera Operator":           1,
+	"Original Music Composer":   0,
+	"Music":                     1,
+	"Sound Designer":            2,
+	"Editor":                    0,
+	"Production Design":         0,
+	"Art Direction":             1,
+	"Costume Design":            0,
+	"Makeup Artist":             1,
+	"Visual Effects Supervisor": 0,
+}
+
+// CrewByDepartment groups the movie crew by department (Directing, Writing, Camera, Sound...),
+// the most prominent jobs of each department first.
+func (m *Movie) CrewByDepartment() map[string][]Person {
+	return groupCrewByDepartment(m.Crew)
+}
+
+// CrewByDepartment groups the TV show crew by department (Directing, Writing, Camera, Sound...),
+// the most prominent jobs of each department first.
+func (t *TVShow) CrewByDepartment() map[string][]Person {
+	return groupCrewByDepartment(t.Crew)
+}
+
+// groupCrewByDepartment groups crew members by department and sorts each group by job rank.
+// Members cached before the department was recorded are grouped under the empty department.
+func groupCrewByDepartment(crew []Person) map[string][]Person {
+	departments := make(map[string][]Person)
+	for _, person := range crew {
+		departments[person.Department] = append(departments[person.Department], person)
+	}
+	for _, members := range departments {
+		sort.SliceStable(members, func(i, j int) bool {
+			return jobRank(members[i].Character) < jobRank(members[j].Character)
+		})
+	}
+	return departments
+}
+
+// jobRank returns the rank of a job within its department, unknown jobs ranking last.
+func jobRank(job string) int {
+	if rank, ok := jobRanks[job]; ok {
+		return rank
+	}
+	return len(jobRanks)
+}
@@ -0,0 +1,52 @@
+package tmdb
+
+import (
+	"github.com/ryanbradynd05/go-tmdb"
+	"reflect"
+	"testing"
+)
+
+func TestCrewByDepartment(t *testing.T) {
+	movie := &Movie{Crew: []Person{
+		{ID: 1, Character: "Story", Department: "Writing"},
+		{ID: 2, Character: "Assistant Director", Department: "Directing"},
+		{ID: 3, Character: "Screenplay", Department: "Writing"},
+		{ID: 4, Character: "Script Supervisor", Department: "Directing"},
+		{ID: 5, Character: "Director", Department: "Directing"},
+		{ID: 6, Character: "Novel", Department: "Writing"},
+		{ID: 7, Character: "Director", Department: "Directing"},
+		{ID: 8, Character: "Gaffer"},
+	}}
+	want := map[string][]int{
+		// jobs missing from jobRanks come last, and ties keep their original order
+		"Directing": {5, 7, 2, 4},
+		"Writing":   {3, 1, 6},
+		"":          {8},
+	}
+
+	got := make(map[string][]int)
+	for department, members := range movie.CrewByDepartment() {
+		for _, person := range members {
+			got[department] = append(got[department], person.ID)
+		}
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CrewByDepartment() = %v, want %v", got, want)
+	}
+
+	show := &TVShow{Crew: movie.Crew}
+	if !reflect.DeepEqual(show.CrewByDepartment(), movie.CrewByDepartment()) {
+		t.Error("TVShow.CrewByDepartment() differs from Movie.CrewByDepartment() for the same crew")
+	}
+}
+
+func TestExtractCrewDepartment(t *testing.T) {
+	var credits tmdb.MovieCredits
+	if err := json.Unmarshal([]byte(`{"crew":[{"id":1,"job":"Director","department":"Directing","name":"Jane"}]}`), &credits); err != nil {
+		t.Fatal(err)
+	}
+	want := []Person{{ID: 1, Character: "Director", Name: "Jane", ProfileURL: emptyProfileURL, Department: "Directing"}}
+	if got := *extractMovieCrew(&credits); !reflect.DeepEqual(got, want) {
+		t.Errorf("extractMovieCrew() = %+v, want %+v", got, want)
+	}
+}
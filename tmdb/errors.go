@@ -0,0 +1,73 @@
+package tmdb
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// ErrNotFound is returned when the requested resource does not exist on TMDB.
+	ErrNotFound = errors.New("tmdb: resource not found")
+	// ErrRateLimited is returned when TMDB rejected the request because of its rate limit.
+	ErrRateLimited = errors.New("tmdb: rate limited")
+	// ErrServer is returned when TMDB failed to process the request on its side.
+	ErrServer = errors.New("tmdb: server error")
+	// ErrTimeout is returned when a TMDB request exceeded the timeout set with WithCallTimeout.
+	ErrTimeout = errors.New("tmdb: request timed out")
+	// ErrStale is returned along with a value served from an expired cache entry because TMDB failed
+	// (see WithStaleOnError).
+	ErrStale = errors.New("tmdb: stale data")
+)
+
+// statusCodePattern matches the error message built by go-tmdb from the TMDB status response.
+var statusCodePattern = regexp.MustCompile(`^Code \((\d+)\):`)
+
+// statusErrors maps TMDB status codes (https://developer.themoviedb.org/docs/errors) to sentinel errors.
+var statusErrors = map[int]error{
+	6:  ErrNotFound,    // Invalid id
+	34: ErrNotFound,    // The resource you requested could not be found
+	25: ErrRateLimited, // Your request count is over the allowed limit
+	9:  ErrServer,      // Service offline
+	11: ErrServer,      // Internal error
+	15: ErrServer,      // Failed
+	24: ErrServer,      // Backend timeout
+	43: ErrServer,      // Backend connection failure
+	46: ErrServer,      // Maintenance
+}
+
+// wrapError wraps an error returned by go-tmdb with the matching sentinel error, so that callers
+// can tell them apart with errors.Is. Errors that cannot be classified are returned as is.
+func wrapError(err error) error {
+	if err == nil {
+		return nil
+	}
+	match := statusCodePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return err
+	}
+	code, convErr := strconv.Atoi(match[1])
+	if convErr != nil {
+		return err
+	}
+	sentinel, ok := statusErrors[code]
+	if !ok {
+		return err
+	}
+	return fmt.Errorf("%w: %w", sentinel, err)
+}
+
+// staleFallback returns the stale value found by lookup and an error wrapping ErrStale and err when the client
+// serves stale data on error, or err otherwise.
+func staleFallback[T any](m *mediaClient, err error, lookup func() *T) (*T, error) {
+	if m.clientOptions.staleGrace <= 0 || errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+	stale := lookup()
+	if stale == nil {
+		return nil, err
+	}
+	m.clientOptions.logger.Warnf("Serving stale cached data after TMDB error: %s", err)
+	return stale, fmt.Errorf("%w: %w", ErrStale, err)
+}
@@ -0,0 +1,109 @@
+package tmdb
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ryanbradynd05/go-tmdb"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestWrapError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "invalid id", err: errors.New("Code (6): Invalid id: The pre-requisite id is invalid or not found."), want: ErrNotFound},
+		{name: "not found", err: errors.New("Code (34): The resource you requested could not be found."), want: ErrNotFound},
+		{name: "rate limited", err: errors.New("Code (25): Your request count (41) is over the allowed limit of 40."), want: ErrRateLimited},
+		{name: "internal error", err: errors.New("Code (11): Internal error: Something went wrong, contact TMDb."), want: ErrServer},
+		{name: "maintenance", err: errors.New("Code (46): The API is undergoing maintenance. Try again later."), want: ErrServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := wrapError(tt.err)
+			if !errors.Is(got, tt.want) {
+				t.Errorf("wrapError() = %v, want it to wrap %v", got, tt.want)
+			}
+			if !errors.Is(got, tt.err) {
+				t.Errorf("wrapError() = %v, want it to keep the original error", got)
+			}
+		})
+	}
+}
+
+func TestWrapErrorUnclassified(t *testing.T) {
+	for _, err := range []error{
+		errors.New("Code (7): Invalid API key: You must be granted a valid key."),
+		errors.New("dial tcp: connection refused"),
+	} {
+		got := wrapError(err)
+		if got != err {
+			t.Errorf("wrapError(%q) = %v, want the error unchanged", err, got)
+		}
+	}
+	if wrapError(nil) != nil {
+		t.Error("wrapError(nil) != nil")
+	}
+}
+
+func TestGetJSONStatusErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		code   int
+		want   error
+	}{
+		{status: http.StatusNotFound, code: 34, want: ErrNotFound},
+		{status: http.StatusTooManyRequests, code: 25, want: ErrRateLimited},
+		{status: http.StatusInternalServerError, code: 11, want: ErrServer},
+		{status: http.StatusServiceUnavailable, code: 46, want: ErrServer},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprintf(w, `{"status_code": %d, "status_message": "stub"}`, tt.code)
+			})
+			client := NewMediaClient("key").(*mediaClient)
+			var v struct{}
+			if err := client.getJSON("/movie/1", nil, &v); !errors.Is(err, tt.want) {
+				t.Errorf("getJSON() = %v, want it to wrap %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCallTimeout(t *testing.T) {
+	tests := []struct {
+		name    string
+		timeout time.Duration
+		want    error
+	}{
+		{name: "no timeout", timeout: 0, want: nil},
+		{name: "long enough", timeout: time.Second, want: nil},
+		{name: "too short", timeout: 10 * time.Millisecond, want: ErrTimeout},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTMDB{
+				movies: map[int]*tmdb.Movie{27205: {ID: 27205, Title: "Inception"}},
+				delay:  100 * time.Millisecond,
+			}
+			client := newTestClient(fake, WithCallTimeout(tt.timeout))
+			if _, err := client.GetMovieShort(27205); !errors.Is(err, tt.want) {
+				t.Errorf("GetMovieShort() = %v, want %v", err, tt.want)
+			}
+
+			newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				time.Sleep(100 * time.Millisecond)
+				w.Write([]byte(`{}`))
+			})
+			var v struct{}
+			if err := client.getJSON("/movie/1", nil, &v); !errors.Is(err, tt.want) {
+				t.Errorf("getJSON() = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
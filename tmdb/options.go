@@ -0,0 +1,122 @@
+package tmdb
+
+import (
+	"github.com/bingemate/media-go-pkg/logging"
+	"time"
+)
+
+// defaultConcurrencyLimit is the default maximum number of concurrent TMDB lookups made by fan-out methods.
+const defaultConcurrencyLimit = 8
+
+type clientOptions struct {
+	episodeBackdropFallback bool
+	concurrencyLimit        int
+	logger                  logging.Logger
+	englishFallback         bool
+	genreEnrichment         bool
+	callTimeout             time.Duration
+	cacheCompression        bool
+	cacheKeyPrefix          string
+	staleGrace              time.Duration
+}
+
+// Option customizes the MediaClient created by NewMediaClient or NewRedisMediaClient.
+type Option func(*clientOptions)
+
+func newClientOptions(opts []Option) *clientOptions {
+	o := &clientOptions{
+		concurrencyLimit: defaultConcurrencyLimit,
+		logger:           logging.Default,
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithEpisodeBackdropFallback uses the backdrop of the TV show as the picture of the episodes
+// without a still, instead of the generic empty backdrop.
+// This costs one extra TV show lookup (GetTVShowShort, cached) per episode or season request
+// containing an episode without a still.
+func WithEpisodeBackdropFallback() Option {
+	return func(o *clientOptions) {
+		o.episodeBackdropFallback = true
+	}
+}
+
+// WithConcurrencyLimit sets the maximum number of concurrent TMDB lookups made by the methods fanning out
+// over several shows, seasons or movies (GetTVShowsReleases, GetTVShowsByActor, GetMoviesReleases...).
+// The limit is shared by all the calls made on the client. Values lower than 1 are ignored.
+func WithConcurrencyLimit(limit int) Option {
+	return func(o *clientOptions) {
+		if limit > 0 {
+			o.concurrencyLimit = limit
+		}
+	}
+}
+
+// WithLogger sets the logger of the client and of its Redis cache.
+func WithLogger(logger logging.Logger) Option {
+	return func(o *clientOptions) {
+		if logger != nil {
+			o.logger = logger
+		}
+	}
+}
+
+// WithEnglishFallback fills the empty localized title and overview of movies and TV shows retrieved
+// by ID with their English version, at the cost of one extra request for these titles.
+// The language of the overview is reported in OverviewLanguage.
+func WithEnglishFallback() Option {
+	return func(o *clientOptions) {
+		o.englishFallback = true
+	}
+}
+
+// WithGenreEnrichment sets the genres of the movies and TV shows of list results (popular, search,
+// discover, recommendations...), which TMDB only returns as IDs, from the genre list.
+// The genre lists are retrieved once per client, on first use.
+func WithGenreEnrichment() Option {
+	return func(o *clientOptions) {
+		o.genreEnrichment = true
+	}
+}
+
+// WithCallTimeout makes every TMDB request fail with ErrTimeout when it takes longer than the given timeout.
+// A timeout of 0, the default, waits for the requests to complete.
+func WithCallTimeout(timeout time.Duration) Option {
+	return func(o *clientOptions) {
+		o.callTimeout = timeout
+	}
+}
+
+// WithCacheCompression gzips the values stored in the Redis cache of a client created by NewRedisMediaClient.
+// JSON compresses well: a page of search or list results takes about 3 to 4 times less memory, at the cost
+// of some CPU time on every cache read and write. Entries written with and without compression can be read
+// either way, so the option can be toggled without flushing the cache.
+func WithCacheCompression() Option {
+	return func(o *clientOptions) {
+		o.cacheCompression = true
+	}
+}
+
+// WithCacheKeyPrefix prepends prefix (e.g. "prod:media:") to every key of the Redis cache of a client created
+// by NewRedisMediaClient, so that several environments or services can share a Redis instance.
+// The default, no prefix, keeps the keys used by previous versions.
+func WithCacheKeyPrefix(prefix string) Option {
+	return func(o *clientOptions) {
+		o.cacheKeyPrefix = prefix
+	}
+}
+
+// WithStaleOnError keeps the entries of the Redis cache of a client created by NewRedisMediaClient for grace
+// past their expiration. When TMDB fails while refreshing an expired movie or TV show (GetMovie, GetMovieShort,
+// GetTVShow, GetTVShowShort), the stale entry is returned along with an error wrapping ErrStale and the TMDB
+// error, instead of failing; GetMovies returns the stale movies without error. A resource TMDB reports as not
+// found is never served stale.
+// Expired entries remain in Redis for the grace period, which increases its memory use.
+func WithStaleOnError(grace time.Duration) Option {
+	return func(o *clientOptions) {
+		o.staleGrace = grace
+	}
+}
@@ -0,0 +1,39 @@
+package tmdb
+
+import (
+	"strconv"
+	"strings"
+)
+
+type watchProvider struct {
+	ProviderID   int    `json:"provider_id"`
+	ProviderName string `json:"provider_name"`
+}
+
+type watchProviders struct {
+	Results map[string]struct {
+		Flatrate []watchProvider `json:"flatrate"`
+		Rent     []watchProvider `json:"rent"`
+		Buy      []watchProvider `json:"buy"`
+	} `json:"results"`
+}
+
+// GetMovieRegionAvailability reports whether a movie can be streamed, rented or bought in the given region
+// (ISO 3166-1 code, e.g. "FR"), according to the TMDB watch providers.
+// It returns false without error when no provider offers the movie in the region.
+func (m *mediaClient) GetMovieRegionAvailability(movieID int, region string) (bool, error) {
+	region = strings.ToUpper(region)
+	if available, ok := m.cache.GetMovieAvailability(movieID, region); ok {
+		return available, nil
+	}
+
+	var providers watchProviders
+	err := m.getJSON("/movie/"+strconv.Itoa(movieID)+"/watch/providers", nil, &providers)
+	if err != nil {
+		return false, err
+	}
+	regionProviders, ok := providers.Results[region]
+	available := ok && len(regionProviders.Flatrate)+len(regionProviders.Rent)+len(regionProviders.Buy) > 0
+	m.cache.AddMovieAvailability(movieID, region, available)
+	return available, nil
+}
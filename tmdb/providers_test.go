@@ -0,0 +1,61 @@
+package tmdb
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func TestGetMovieRegionAvailability(t *testing.T) {
+	tests := []struct {
+		name   string
+		region string
+		want   bool
+	}{
+		{name: "flatrate", region: "fr", want: true},
+		{name: "buy only", region: "US", want: true},
+		{name: "no provider", region: "DE", want: false},
+		{name: "unknown region", region: "JP", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var paths []string
+			newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				w.Write([]byte(`{"id": 27205, "results": {
+					"FR": {"flatrate": [{"provider_id": 8, "provider_name": "Netflix"}]},
+					"US": {"buy": [{"provider_id": 2, "provider_name": "Apple TV"}]},
+					"DE": {}
+				}}`))
+			})
+			client := newTestClient(&fakeTMDB{})
+
+			for i := 0; i < 2; i++ {
+				available, err := client.GetMovieRegionAvailability(27205, tt.region)
+				if err != nil {
+					t.Fatalf("GetMovieRegionAvailability() = %v", err)
+				}
+				if available != tt.want {
+					t.Errorf("GetMovieRegionAvailability() = %v, want %v", available, tt.want)
+				}
+			}
+			// The availability is cached
+			if len(paths) != 1 || paths[0] != "/movie/27205/watch/providers" {
+				t.Errorf("requested %v, want /movie/27205/watch/providers once", paths)
+			}
+		})
+	}
+}
+
+func TestGetMovieRegionAvailabilityNotFound(t *testing.T) {
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status_code": 34, "status_message": "The resource you requested could not be found."}`))
+	})
+	client := newTestClient(&fakeTMDB{})
+
+	_, err := client.GetMovieRegionAvailability(1, "FR")
+	if !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetMovieRegionAvailability() = %v, want ErrNotFound", err)
+	}
+}
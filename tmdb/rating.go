@@ -0,0 +1,36 @@
+package tmdb
+
+import (
+	"sort"
+)
+
+// Recommended defaults for WeightedRating: TMDB's average vote is around 6.5, and titles
+// with less than a few hundred votes are too noisy to be ranked on their own average.
+const (
+	DefaultMinVotes   = 300
+	DefaultMeanRating = 6.5
+)
+
+// WeightedRating computes the IMDb Bayesian weighted rating of a title:
+//
+//	WR = (v / (v + m)) * R + (m / (v + m)) * C
+//
+// where R is the vote average, v the vote count, m the minimum votes required to be trusted
+// and C the mean rating across the catalog. Titles with few votes are pulled toward C.
+// DefaultMinVotes and DefaultMeanRating are sensible values for TMDB data.
+func WeightedRating(voteAverage float32, voteCount int, minVotes int, meanRating float32) float32 {
+	v := float32(voteCount)
+	m := float32(minVotes)
+	if v+m <= 0 {
+		return meanRating
+	}
+	return (v/(v+m))*voteAverage + (m/(v+m))*meanRating
+}
+
+// SortMoviesByWeightedRating sorts movies by descending weighted rating, keeping the original order of ties.
+func SortMoviesByWeightedRating(movies []*Movie, minVotes int, meanRating float32) {
+	sort.SliceStable(movies, func(i, j int) bool {
+		return WeightedRating(movies[i].VoteAverage, movies[i].VoteCount, minVotes, meanRating) >
+			WeightedRating(movies[j].VoteAverage, movies[j].VoteCount, minVotes, meanRating)
+	})
+}
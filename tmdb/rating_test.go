@@ -0,0 +1,55 @@
+package tmdb
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestWeightedRating(t *testing.T) {
+	tests := []struct {
+		name        string
+		voteAverage float32
+		voteCount   int
+		minVotes    int
+		meanRating  float32
+		want        float32
+	}{
+		{name: "no votes", voteAverage: 10, voteCount: 0, minVotes: 300, meanRating: 6.5, want: 6.5},
+		{name: "as many votes as the minimum", voteAverage: 8.5, voteCount: 300, minVotes: 300, meanRating: 6.5, want: 7.5},
+		{name: "many votes", voteAverage: 8, voteCount: 29700, minVotes: 300, meanRating: 6.5, want: 7.985},
+		{name: "no minimum", voteAverage: 9, voteCount: 3, minVotes: 0, meanRating: 6.5, want: 9},
+		{name: "nothing to weigh", voteAverage: 9, voteCount: 0, minVotes: 0, meanRating: 6.5, want: 6.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := WeightedRating(tt.voteAverage, tt.voteCount, tt.minVotes, tt.meanRating)
+			if math.Abs(float64(got-tt.want)) > 1e-4 {
+				t.Errorf("WeightedRating(%v, %d, %d, %v) = %v, want %v", tt.voteAverage, tt.voteCount, tt.minVotes, tt.meanRating, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortMoviesByWeightedRating(t *testing.T) {
+	movies := []*Movie{
+		{ID: 1, VoteAverage: 10, VoteCount: 2},
+		{ID: 2, VoteAverage: 8.4, VoteCount: 20000},
+		{ID: 3, VoteAverage: 7, VoteCount: 500},
+		{ID: 4, VoteAverage: 8.4, VoteCount: 20000},
+		{ID: 5, VoteAverage: 5, VoteCount: 10000},
+	}
+	SortMoviesByWeightedRating(movies, DefaultMinVotes, DefaultMeanRating)
+
+	if want := []int{2, 4, 3, 1, 5}; !reflect.DeepEqual(movieIDs(movies), want) {
+		t.Errorf("sorted IDs = %v, want %v", movieIDs(movies), want)
+	}
+}
+
+func movieIDs(movies []*Movie) []int {
+	var ids = make([]int, len(movies))
+	for i, movie := range movies {
+		ids[i] = movie.ID
+	}
+	return ids
+}
@@ -1,12 +1,14 @@
 package tmdb
 
 import (
+	"errors"
 	"fmt"
 	"github.com/ryanbradynd05/go-tmdb"
-	"log"
 	"math"
+	"net/url"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -15,6 +17,9 @@ const imageBaseURL = "https://image.tmdb.org/t/p/original"
 const emptyProfileURL = "https://bingemate.fr/assets/empty_profile.jpg"
 const emptyBackdropURL = "https://bingemate.fr/assets/empty_background.jpg"
 const emptyPosterURL = "https://bingemate.fr/assets/empty_poster.jpg"
+const fallbackLanguage = "en"
+const defaultRecentMoviesPages = 5
+const defaultRecentMoviesLimit = 20
 
 // Genre represents a movie/TV genre with its ID and name.
 type Genre struct {
@@ -24,106 +29,201 @@ type Genre struct {
 
 // Person represents a person involved in a movie or TV show with their ID, character name,
 // real name, and the URL to their profile picture.
+// For crew members, Character holds the job and Department the TMDB department (Directing, Writing...).
 type Person struct {
 	ID         int    `json:"id"`
 	Character  string `json:"character"`
 	Name       string `json:"name"`
 	ProfileURL string `json:"profileUrl"`
+	Department string `json:"department,omitempty"`
 }
 
 // Actor represents a person
+// KnownFor lists the titles the person is famous for and is only set by SearchActors.
+// Gender follows the TMDB convention (0 unknown, 1 female, 2 male, 3 non-binary) and is only set
+// by GetActor, since the person search response does not include it.
 type Actor struct {
-	ID         int    `json:"id"`
-	Name       string `json:"name"`
-	ProfileURL string `json:"profileUrl"`
-	Overview   string `json:"overview"`
+	ID         int      `json:"id"`
+	Name       string   `json:"name"`
+	ProfileURL string   `json:"profileUrl"`
+	Overview   string   `json:"overview"`
+	KnownFor   []string `json:"knownFor,omitempty"`
+	Popularity float32  `json:"popularity"`
+	Gender     int      `json:"gender"`
 }
 
-// Studio represents a movie/TV studio with its ID, name, and logo URL.
+// Studio represents a movie/TV studio with its ID, name, logo URL and ISO 3166-1 origin country.
+// OriginCountry is only set for the studios and networks embedded in a movie or TV show.
 type Studio struct {
-	ID      int    `json:"id"`
-	Name    string `json:"name"`
-	LogoURL string `json:"logoUrl"`
+	ID            int    `json:"id"`
+	Name          string `json:"name"`
+	LogoURL       string `json:"logoUrl"`
+	OriginCountry string `json:"originCountry"`
+}
+
+// Video represents a trailer, teaser or clip hosted on an external site (e.g. YouTube).
+type Video struct {
+	Key  string `json:"key"`
+	Name string `json:"name"`
+	Site string `json:"site"`
+	Type string `json:"type"`
+}
+
+// ReleaseDate represents a release of a movie in a region, with its TMDB release type
+// (1 premiere, 2 limited theatrical, 3 theatrical, 4 digital, 5 physical, 6 TV).
+type ReleaseDate struct {
+	Certification string `json:"certification"`
+	Date          string `json:"date"`
+	Note          string `json:"note"`
+	Type          int    `json:"type"`
 }
 
 // Movie represents a movie with its attributes such as ID, actors list (Person), backdrop URL,
 // crew list (Person), genre list (Genre), overview, poster URL, release date, studio list (Studio),
 // title, vote average, and vote count.
+// Videos, backdrop and poster URLs, certification and release dates are only set by GetMovieFull.
+// OverviewLanguage is only set when the client is created with WithEnglishFallback.
 type Movie struct {
-	ID          int      `json:"id"`
-	Actors      []Person `json:"actors"`
-	BackdropURL string   `json:"backdropUrl"`
-	Crew        []Person `json:"crew"`
-	Genres      []Genre  `json:"genres"`
-	Overview    string   `json:"overview"`
-	PosterURL   string   `json:"posterUrl"`
-	ReleaseDate string   `json:"releaseDate"`
-	Studios     []Studio `json:"studios"`
-	Title       string   `json:"title"`
-	VoteAverage float32  `json:"voteAverage"`
-	VoteCount   int      `json:"voteCount"`
+	ID               int           `json:"id"`
+	Actors           []Person      `json:"actors"`
+	BackdropURL      string        `json:"backdropUrl"`
+	Crew             []Person      `json:"crew"`
+	Genres           []Genre       `json:"genres"`
+	Overview         string        `json:"overview"`
+	PosterURL        string        `json:"posterUrl"`
+	ReleaseDate      string        `json:"releaseDate"`
+	Studios          []Studio      `json:"studios"`
+	Title            string        `json:"title"`
+	VoteAverage      float32       `json:"voteAverage"`
+	VoteCount        int           `json:"voteCount"`
+	Videos           []Video       `json:"videos,omitempty"`
+	BackdropURLs     []string      `json:"backdropUrls,omitempty"`
+	PosterURLs       []string      `json:"posterUrls,omitempty"`
+	Certification    string        `json:"certification,omitempty"`
+	ReleaseDates     []ReleaseDate `json:"releaseDates,omitempty"`
+	OverviewLanguage string        `json:"overviewLanguage,omitempty"`
 }
 
 // TVEpisode represents a TV episode with its attributes such as ID, TV show ID, poster URL,
-// season number, episode number, name, overview, and air date.
+// season number, episode number, name, overview, air date, guest stars and crew (Person).
+// Guest stars and crew are set by GetTVEpisode, and by GetTVSeasonEpisodes when the season
+// response includes them; they are empty for the episodes returned by FindByIMDbID.
 type TVEpisode struct {
-	ID            int    `json:"id"`
-	TVShowID      int    `json:"tvShowId"`
-	PosterURL     string `json:"posterUrl"`
-	EpisodeNumber int    `json:"episodeNumber"`
-	SeasonNumber  int    `json:"seasonNumber"`
-	Name          string `json:"name"`
-	Overview      string `json:"overview"`
-	AirDate       string `json:"airDate"`
+	ID            int      `json:"id"`
+	TVShowID      int      `json:"tvShowId"`
+	PosterURL     string   `json:"posterUrl"`
+	EpisodeNumber int      `json:"episodeNumber"`
+	SeasonNumber  int      `json:"seasonNumber"`
+	Name          string   `json:"name"`
+	Overview      string   `json:"overview"`
+	AirDate       string   `json:"airDate"`
+	GuestStars    []Person `json:"guestStars,omitempty"`
+	Crew          []Person `json:"crew,omitempty"`
 }
 
 // TVShow represents a TV show with its attributes such as ID, actors list (Person), backdrop URL,
 // crew list (Person), genre list (Genre), overview, poster URL, release date, studio list (Studio),
 // status, next episode (TVEpisode), title, seasons count, vote average, and vote count.
+// OverviewLanguage is only set when the client is created with WithEnglishFallback.
 type TVShow struct {
-	ID            int        `json:"id"`
-	Actors        []Person   `json:"actors"`
-	BackdropURL   string     `json:"backdropUrl"`
-	Crew          []Person   `json:"crew"`
-	Genres        []Genre    `json:"genres"`
-	Overview      string     `json:"overview"`
-	PosterURL     string     `json:"posterUrl"`
-	ReleaseDate   string     `json:"releaseDate"`
-	Networks      []Studio   `json:"networks"`
-	Status        string     `json:"status"`
-	NextEpisode   *TVEpisode `json:"nextEpisode"`
-	Title         string     `json:"title"`
-	SeasonsCount  int        `json:"seasonsCount"`
-	EpisodesCount int        `json:"episodesCount"`
-	VoteAverage   float32    `json:"voteAverage"`
-	VoteCount     int        `json:"voteCount"`
+	ID               int        `json:"id"`
+	Actors           []Person   `json:"actors"`
+	BackdropURL      string     `json:"backdropUrl"`
+	Crew             []Person   `json:"crew"`
+	Genres           []Genre    `json:"genres"`
+	Overview         string     `json:"overview"`
+	PosterURL        string     `json:"posterUrl"`
+	ReleaseDate      string     `json:"releaseDate"`
+	Networks         []Studio   `json:"networks"`
+	Status           string     `json:"status"`
+	NextEpisode      *TVEpisode `json:"nextEpisode"`
+	Title            string     `json:"title"`
+	SeasonsCount     int        `json:"seasonsCount"`
+	EpisodesCount    int        `json:"episodesCount"`
+	VoteAverage      float32    `json:"voteAverage"`
+	VoteCount        int        `json:"voteCount"`
+	OverviewLanguage string     `json:"overviewLanguage,omitempty"`
+}
+
+// FindResult represents the media matching an external ID, only the matching kind is set.
+type FindResult struct {
+	Movie   *Movie     `json:"movie"`
+	TVShow  *TVShow    `json:"tvShow"`
+	Episode *TVEpisode `json:"episode"`
+}
+
+// credits holds the cast and crew of a movie, TV show or season, as cached by the Get*Credits methods.
+type credits struct {
+	Cast []Person `json:"cast"`
+	Crew []Person `json:"crew"`
 }
 
 type PaginatedMovieResults struct {
 	Results     []*Movie
+	Page        int
 	TotalPage   int
 	TotalResult int
 }
 
+// HasNext reports whether there is a page after this one.
+func (r *PaginatedMovieResults) HasNext() bool {
+	return r.Page < r.TotalPage
+}
+
+// NextPage returns the number of the page after this one, to pass to the method that returned these results.
+func (r *PaginatedMovieResults) NextPage() int {
+	return r.Page + 1
+}
+
 type PaginatedTVShowResults struct {
 	Results     []*TVShow
+	Page        int
 	TotalPage   int
 	TotalResult int
 }
 
+// HasNext reports whether there is a page after this one.
+func (r *PaginatedTVShowResults) HasNext() bool {
+	return r.Page < r.TotalPage
+}
+
+// NextPage returns the number of the page after this one, to pass to the method that returned these results.
+func (r *PaginatedTVShowResults) NextPage() int {
+	return r.Page + 1
+}
+
 type PaginatedActorResults struct {
 	Results     []*Actor
+	Page        int
 	TotalPage   int
 	TotalResult int
 }
 
+// HasNext reports whether there is a page after this one.
+func (r *PaginatedActorResults) HasNext() bool {
+	return r.Page < r.TotalPage
+}
+
+// NextPage returns the number of the page after this one, to pass to the method that returned these results.
+func (r *PaginatedActorResults) NextPage() int {
+	return r.Page + 1
+}
+
 // MediaClient is an interface for a media client API.
 type MediaClient interface {
+	FindByIMDbID(imdbID string) (*FindResult, error)
+	GenreIDByName(name, mediaType string) (int, error)
+	GenreNamesToIDs(names []string, mediaType string) ([]int, error)
 	GetActor(actorID int) (*Actor, error)
+	GetCollectionOrderedMovies(collectionID int) ([]*Movie, error)
 	GetMovie(id int) (*Movie, error)
+	GetMovieCredits(movieID int) ([]Person, []Person, error)
+	GetMovieFull(id int) (*Movie, error)
 	GetMovieGenre(genreID int) (*Genre, error)
 	GetMovieGenres() ([]*Genre, error)
 	GetMovieRecommendations(movieID int) ([]*Movie, error)
+	GetMovieRegionAvailability(movieID int, region string) (bool, error)
+	GetMovies(ids []int) ([]*Movie, error)
 	GetMoviesByActor(actorID int, page int) (*PaginatedMovieResults, error)
 	GetMoviesByDirector(directorID int, page int) (*PaginatedMovieResults, error)
 	GetMoviesByGenre(genreID int, page int) (*PaginatedMovieResults, error)
@@ -131,64 +231,146 @@ type MediaClient interface {
 	GetMovieShort(movieID int) (*Movie, error)
 	GetMoviesReleases(movieIds []int, startDate, endDate time.Time) ([]*Movie, error)
 	GetNetwork(networkID int) (*Studio, error)
+	GetNowPlayingMovies(page int) (*PaginatedMovieResults, error)
+	GetPersonImages(personID int) ([]string, error)
 	GetPopularMovies(page int) (*PaginatedMovieResults, error)
 	GetPopularTVShows(page int) (*PaginatedTVShowResults, error)
 	GetRecentMovies() ([]*Movie, error)
+	GetRecentMoviesWindow(pagesToScan, limit int) ([]*Movie, error)
 	GetRecentTVShows() ([]*TVShow, error)
+	GetShowEpisodesInRange(tvID int, startDate, endDate time.Time, includeSpecials bool, loc *time.Location) ([]*TVEpisode, error)
 	GetStudio(studioID int) (*Studio, error)
 	GetTVEpisode(tvID, season, episodeNumber int) (*TVEpisode, error)
+	GetTVAllSeasons(tvID int) (map[int][]*TVEpisode, error)
 	GetTVGenre(genreID int) (*Genre, error)
+	GetTVSeasonCredits(tvID, season int) ([]Person, []Person, error)
 	GetTVSeasonEpisodes(id int, season int) ([]*TVEpisode, error)
 	GetTVShow(id int) (*TVShow, error)
+	GetTVShowCredits(tvShowID int) ([]Person, []Person, error)
 	GetTVShowGenres() ([]*Genre, error)
 	GetTVShowRecommendations(tvShowID int) ([]*TVShow, error)
 	GetTVShowsByActor(actorID int, page int) (*PaginatedTVShowResults, error)
 	GetTVShowsByGenre(genreID int, page int) (*PaginatedTVShowResults, error)
 	GetTVShowsByNetwork(studioID int, page int) (*PaginatedTVShowResults, error)
 	GetTVShowShort(tvShowID int) (*TVShow, error)
-	GetTVShowsReleases(tvIds []int, startDate, endDate time.Time) ([]*TVEpisode, []*TVShow, error)
+	GetTVShowsReleases(tvIds []int, startDate, endDate time.Time, includeSpecials bool, loc *time.Location) ([]*TVEpisode, []*TVShow, error)
+	MovieExists(id int) (bool, error)
 	SearchMovies(query string, page int, adult bool) (*PaginatedMovieResults, error)
 	SearchMoviesYear(query string, year string, page int) (*PaginatedMovieResults, error)
 	SearchTVShows(query string, page int, adult bool) (*PaginatedTVShowResults, error)
 	SearchActors(query string, page int, adult bool) (*PaginatedActorResults, error)
+	TVShowExists(id int) (bool, error)
+	WithOptions(options map[string]string) MediaClient
+}
+
+// tmdbAPI is the part of the go-tmdb client used by mediaClient, so that tests can stub TMDB.
+type tmdbAPI interface {
+	DiscoverMovie(options map[string]string) (*tmdb.MoviePagedResults, error)
+	DiscoverTV(options map[string]string) (*tmdb.TvPagedResults, error)
+	GetCollectionInfo(id int, options map[string]string) (*tmdb.Collection, error)
+	GetCompanyInfo(id int, options map[string]string) (*tmdb.Company, error)
+	GetFind(id, source string, options map[string]string) (*tmdb.FindResults, error)
+	GetMovieCredits(id int, options map[string]string) (*tmdb.MovieCredits, error)
+	GetMovieGenres(options map[string]string) (*tmdb.Genre, error)
+	GetMovieInfo(id int, options map[string]string) (*tmdb.Movie, error)
+	GetMovieNowPlaying(options map[string]string) (*tmdb.MovieDatedResults, error)
+	GetMoviePopular(options map[string]string) (*tmdb.MoviePagedResults, error)
+	GetMovieRecommendations(id int, options map[string]string) (*tmdb.MovieRecommendations, error)
+	GetNetworkInfo(id int) (*tmdb.Network, error)
+	GetPersonImages(id int) (*tmdb.PersonImages, error)
+	GetPersonInfo(id int, options map[string]string) (*tmdb.Person, error)
+	GetPersonTvCredits(id int, options map[string]string) (*tmdb.PersonTvCredits, error)
+	GetTvAiringToday(options map[string]string) (*tmdb.TvPagedResults, error)
+	GetTvCredits(id int, options map[string]string) (*tmdb.TvCredits, error)
+	GetTvEpisodeInfo(showID, seasonNum, episodeNum int, options map[string]string) (*tmdb.TvEpisode, error)
+	GetTvGenres(options map[string]string) (*tmdb.Genre, error)
+	GetTvInfo(id int, options map[string]string) (*tmdb.TV, error)
+	GetTvPopular(options map[string]string) (*tmdb.TvPagedResults, error)
+	GetTvRecommendations(id int, options map[string]string) (*tmdb.TvRecommendations, error)
+	GetTvSeasonCredits(showID, seasonNum int) (*tmdb.TvCredits, error)
+	GetTvSeasonInfo(showID, seasonID int, options map[string]string) (*tmdb.TvSeason, error)
+	SearchMovie(name string, options map[string]string) (*tmdb.MovieSearchResults, error)
+	SearchPerson(name string, options map[string]string) (*tmdb.PersonSearchResults, error)
+	SearchTv(name string, options map[string]string) (*tmdb.TvSearchResults, error)
 }
 
+// mediaClient implements MediaClient. Its options hold the default query options and are never written
+// after construction: every call passes go-tmdb its own copy from extractOptions.
 type mediaClient struct {
-	tmdbClient *tmdb.TMDb
-	cache      mediaCache
-	options    map[string]string
+	tmdbClient    tmdbAPI
+	apiKey        string
+	cache         mediaCache
+	options       map[string]string
+	clientOptions *clientOptions
+	// sem bounds the concurrent lookups of the fan-out methods
+	sem chan struct{}
+	// genres holds the genre names, shared with the clients derived by WithOptions
+	genres *genreNames
 }
 
-func NewMediaClient(apiKey string) MediaClient {
+// genreNames maps the movie and TV show genre IDs to their names, loaded on first use.
+type genreNames struct {
+	lock  sync.Mutex
+	movie map[int]string
+	tv    map[int]string
+}
+
+func NewMediaClient(apiKey string, opts ...Option) MediaClient {
 	config := tmdb.Config{
 		APIKey:   apiKey,
 		Proxies:  nil,
 		UseProxy: false,
 	}
+	clientOptions := newClientOptions(opts)
 	return &mediaClient{
 		tmdbClient: tmdb.Init(config),
+		apiKey:     apiKey,
 		options: map[string]string{
 			"language": "fr",
 			"region":   "fr",
 		},
-		cache: newInMemoryMediaCache(),
+		cache:         newInMemoryMediaCache(),
+		clientOptions: clientOptions,
+		sem:           make(chan struct{}, clientOptions.concurrencyLimit),
+		genres:        &genreNames{},
 	}
 }
 
-func NewRedisMediaClient(apiKey, redisHost, redisPass string) MediaClient {
+func NewRedisMediaClient(apiKey, redisHost, redisPass string, opts ...Option) MediaClient {
 	config := tmdb.Config{
 		APIKey:   apiKey,
 		Proxies:  nil,
 		UseProxy: false,
 	}
+	clientOptions := newClientOptions(opts)
 	return &mediaClient{
 		tmdbClient: tmdb.Init(config),
+		apiKey:     apiKey,
 		options: map[string]string{
 			"language": "fr",
 			"region":   "fr",
 		},
-		cache: newRedisMediaCache(redisHost, redisPass),
+		cache:         newRedisMediaCache(redisHost, redisPass, clientOptions),
+		clientOptions: clientOptions,
+		sem:           make(chan struct{}, clientOptions.concurrencyLimit),
+		genres:        &genreNames{},
+	}
+}
+
+// WithOptions returns a client sending the given query options (e.g. "certification_country") on top of
+// the default ones with every TMDB request. The options are passed straight to go-tmdb, which drops the ones
+// an endpoint doesn't support; they are not validated.
+// The derived client shares everything but its options with the client it derives from, which is left
+// unchanged: its cache, its concurrency limit and its genre names. As cache keys don't include the options,
+// the results of both clients are cached together.
+func (m *mediaClient) WithOptions(options map[string]string) MediaClient {
+	merged := extractOptions(m.options)
+	for key, value := range options {
+		merged[key] = value
 	}
+	derived := *m
+	derived.options = merged
+	return &derived
 }
 
 // GetMovie retrieves movie info and credits by ID and returns a Movie object.
@@ -198,17 +380,114 @@ func (m *mediaClient) GetMovie(id int) (*Movie, error) {
 		return cachedMovie, nil
 	}
 
-	movie, err := m.tmdbClient.GetMovieInfo(id, m.options)
+	movie, err := callWithTimeout(m, func() (*tmdb.Movie, error) {
+		return m.tmdbClient.GetMovieInfo(id, extractOptions(m.options))
+	})
+	if err != nil {
+		return staleFallback(m, wrapError(err), func() *Movie { return m.cache.GetStaleMovie(id) })
+	}
+	overviewLanguage := m.applyMovieEnglishFallback(movie)
+	short := extractMovie(movie, nil)
+	short.OverviewLanguage = overviewLanguage
+	m.cache.AddMovieShort(short)
+	credits, err := callWithTimeout(m, func() (*tmdb.MovieCredits, error) {
+		return m.tmdbClient.GetMovieCredits(id, extractOptions(m.options))
+	})
 	if err != nil {
+		return staleFallback(m, wrapError(err), func() *Movie { return m.cache.GetStaleMovie(id) })
+	}
+	extracted := extractMovie(movie, credits)
+	extracted.OverviewLanguage = overviewLanguage
+	m.cache.AddMovie(extracted)
+
+	return extracted, nil
+}
+
+// GetMovies retrieves several movies by ID, like GetMovie, in the order of the IDs.
+// The cached movies are read in a single cache round trip and the others are retrieved concurrently.
+// The errors of every failed lookup are joined into the returned error; the stale movies served because of
+// WithStaleOnError are returned without error.
+func (m *mediaClient) GetMovies(ids []int) ([]*Movie, error) {
+	cached := m.cache.GetMovies(ids)
+	movies := make([]*Movie, len(ids))
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		if movie, ok := cached[id]; ok {
+			movies[i] = movie
+			continue
+		}
+		wg.Add(1)
+		go func(i, id int) {
+			defer wg.Done()
+			m.sem <- struct{}{}
+			movie, err := m.GetMovie(id)
+			<-m.sem
+			if err != nil && !errors.Is(err, ErrStale) {
+				errs[i] = fmt.Errorf("movie %d: %w", id, err)
+				return
+			}
+			movies[i] = movie
+		}(i, id)
+	}
+	wg.Wait()
+	if err := errors.Join(errs...); err != nil {
 		return nil, err
 	}
-	m.cache.AddMovieShort(extractMovie(movie, nil))
-	credits, err := m.tmdbClient.GetMovieCredits(id, m.options)
+	return movies, nil
+}
+
+// movieFull is the movie details response with the appended credits, videos, images and release dates.
+// go-tmdb only decodes the legacy "releases" append, so release_dates is decoded here.
+type movieFull struct {
+	tmdb.Movie
+	ReleaseDates *movieReleaseDates `json:"release_dates,omitempty"`
+}
+
+type movieReleaseDates struct {
+	Results []struct {
+		Iso3166_1    string `json:"iso_3166_1"`
+		ReleaseDates []struct {
+			Certification string `json:"certification"`
+			Note          string `json:"note"`
+			ReleaseDate   string `json:"release_date"`
+			Type          int    `json:"type"`
+		} `json:"release_dates"`
+	} `json:"results"`
+}
+
+// GetMovieFull retrieves movie info, credits, videos, images and release dates by ID in a single
+// request using append_to_response, and returns a Movie object.
+// Images are restricted to the client language, English and images without text.
+func (m *mediaClient) GetMovieFull(id int) (*Movie, error) {
+	cachedMovie := m.cache.GetMovieFull(id)
+	if cachedMovie != nil {
+		return cachedMovie, nil
+	}
+
+	var query = url.Values{}
+	query.Set("language", m.options["language"])
+	query.Set("append_to_response", "credits,videos,images,release_dates")
+	query.Set("include_image_language", imageLanguages(m.options["language"]))
+	var movie movieFull
+	err := m.getJSON("/movie/"+strconv.Itoa(id), query, &movie)
 	if err != nil {
 		return nil, err
 	}
-	extracted := extractMovie(movie, credits)
-	m.cache.AddMovie(extracted)
+	overviewLanguage := m.applyMovieEnglishFallback(&movie.Movie)
+	extracted := extractMovie(&movie.Movie, movie.Credits)
+	extracted.OverviewLanguage = overviewLanguage
+	extracted.Videos = extractMovieVideos(movie.Videos)
+	if movie.Images != nil {
+		extracted.BackdropURLs = extractImageURLs(movie.Images.Backdrops, backdropImgURL)
+		extracted.PosterURLs = extractImageURLs(movie.Images.Posters, posterImgURL)
+	}
+	extracted.ReleaseDates = extractReleaseDates(movie.ReleaseDates, m.options["region"])
+	extracted.Certification = extractCertification(extracted.ReleaseDates)
+	short := extractMovie(&movie.Movie, nil)
+	short.OverviewLanguage = overviewLanguage
+	m.cache.AddMovieShort(short)
+	m.cache.AddMovieFull(extracted)
 
 	return extracted, nil
 }
@@ -220,21 +499,93 @@ func (m *mediaClient) GetTVShow(id int) (*TVShow, error) {
 		return cachedTVShow, nil
 	}
 
-	tvShow, err := m.tmdbClient.GetTvInfo(id, m.options)
+	tvShow, err := callWithTimeout(m, func() (*tmdb.TV, error) {
+		return m.tmdbClient.GetTvInfo(id, extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
-	}
-	m.cache.AddTVShort(extractTVShow(tvShow, nil))
-	credits, err := m.tmdbClient.GetTvCredits(id, m.options)
+		return staleFallback(m, wrapError(err), func() *TVShow { return m.cache.GetStaleTV(id) })
+	}
+	overviewLanguage := m.applyTVEnglishFallback(tvShow)
+	short := extractTVShow(tvShow, nil)
+	short.OverviewLanguage = overviewLanguage
+	m.cache.AddTVShort(short)
+	credits, err := callWithTimeout(m, func() (*tmdb.TvCredits, error) {
+		return m.tmdbClient.GetTvCredits(id, extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return staleFallback(m, wrapError(err), func() *TVShow { return m.cache.GetStaleTV(id) })
 	}
 	extracted := extractTVShow(tvShow, credits)
+	extracted.OverviewLanguage = overviewLanguage
 	m.cache.AddTV(extracted)
 
 	return extracted, nil
 }
 
+// GetMovieCredits retrieves only the cast and crew of a movie by ID, without its info.
+func (m *mediaClient) GetMovieCredits(movieID int) (cast []Person, crew []Person, err error) {
+	cachedCredits := m.cache.GetMovieCredits(movieID)
+	if cachedCredits != nil {
+		return cachedCredits.Cast, cachedCredits.Crew, nil
+	}
+
+	response, err := callWithTimeout(m, func() (*tmdb.MovieCredits, error) {
+		return m.tmdbClient.GetMovieCredits(movieID, extractOptions(m.options))
+	})
+	if err != nil {
+		return nil, nil, wrapError(err)
+	}
+	extracted := &credits{
+		Cast: *extractMovieActors(response),
+		Crew: *extractMovieCrew(response),
+	}
+	m.cache.AddMovieCredits(movieID, extracted)
+	return extracted.Cast, extracted.Crew, nil
+}
+
+// GetTVShowCredits retrieves only the cast and crew of a TV show by ID, without its info.
+func (m *mediaClient) GetTVShowCredits(tvShowID int) (cast []Person, crew []Person, err error) {
+	cachedCredits := m.cache.GetTVCredits(tvShowID)
+	if cachedCredits != nil {
+		return cachedCredits.Cast, cachedCredits.Crew, nil
+	}
+
+	response, err := callWithTimeout(m, func() (*tmdb.TvCredits, error) {
+		return m.tmdbClient.GetTvCredits(tvShowID, extractOptions(m.options))
+	})
+	if err != nil {
+		return nil, nil, wrapError(err)
+	}
+	extracted := &credits{
+		Cast: *extractTVActors(response),
+		Crew: *extractTVCrew(response),
+	}
+	m.cache.AddTVCredits(tvShowID, extracted)
+	return extracted.Cast, extracted.Crew, nil
+}
+
+// GetTVSeasonCredits retrieves the cast and crew of a single season of a TV show, which differ from the
+// show-level credits for anthologies and long-running shows.
+func (m *mediaClient) GetTVSeasonCredits(tvID, season int) (cast []Person, crew []Person, err error) {
+	cachedCredits := m.cache.GetTVSeasonCredits(tvID, season)
+	if cachedCredits != nil {
+		return cachedCredits.Cast, cachedCredits.Crew, nil
+	}
+
+	response, err := callWithTimeout(m, func() (*tmdb.TvCredits, error) {
+		return m.tmdbClient.GetTvSeasonCredits(tvID, season)
+	})
+	if err != nil {
+		return nil, nil, wrapError(err)
+	}
+	extracted := &credits{
+		Cast: *extractTVActors(response),
+		Crew: *extractTVCrew(response),
+	}
+	m.cache.AddTVSeasonCredits(tvID, season, extracted)
+	return extracted.Cast, extracted.Crew, nil
+}
+
 // GetMovieShort retrieves movie info by ID and returns a Movie object.
 func (m *mediaClient) GetMovieShort(id int) (*Movie, error) {
 	cachedMovie := m.cache.GetMovieShort(id)
@@ -242,11 +593,15 @@ func (m *mediaClient) GetMovieShort(id int) (*Movie, error) {
 		return cachedMovie, nil
 	}
 
-	movie, err := m.tmdbClient.GetMovieInfo(id, m.options)
+	movie, err := callWithTimeout(m, func() (*tmdb.Movie, error) {
+		return m.tmdbClient.GetMovieInfo(id, extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return staleFallback(m, wrapError(err), func() *Movie { return m.cache.GetStaleMovieShort(id) })
 	}
+	overviewLanguage := m.applyMovieEnglishFallback(movie)
 	extracted := extractMovie(movie, nil)
+	extracted.OverviewLanguage = overviewLanguage
 	m.cache.AddMovieShort(extracted)
 
 	return extracted, nil
@@ -258,16 +613,112 @@ func (m *mediaClient) GetTVShowShort(id int) (*TVShow, error) {
 	if cachedTVShow != nil {
 		return cachedTVShow, nil
 	}
-	tvShow, err := m.tmdbClient.GetTvInfo(id, m.options)
+	tvShow, err := callWithTimeout(m, func() (*tmdb.TV, error) {
+		return m.tmdbClient.GetTvInfo(id, extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return staleFallback(m, wrapError(err), func() *TVShow { return m.cache.GetStaleTVShort(id) })
 	}
+	overviewLanguage := m.applyTVEnglishFallback(tvShow)
 	extracted := extractTVShow(tvShow, nil)
+	extracted.OverviewLanguage = overviewLanguage
 	m.cache.AddTVShort(extracted)
 
 	return extracted, nil
 }
 
+// applyMovieEnglishFallback fills the empty title and overview of the movie from its English version
+// if WithEnglishFallback is set, and returns the language of the overview (empty if the option is not set).
+func (m *mediaClient) applyMovieEnglishFallback(movie *tmdb.Movie) string {
+	if !m.clientOptions.englishFallback {
+		return ""
+	}
+	if movie.Overview != "" && movie.Title != "" {
+		return m.options["language"]
+	}
+	options := extractOptions(m.options)
+	options["language"] = fallbackLanguage
+	englishMovie, err := callWithTimeout(m, func() (*tmdb.Movie, error) {
+		return m.tmdbClient.GetMovieInfo(movie.ID, options)
+	})
+	if err != nil {
+		m.clientOptions.logger.Warnf("Error while retrieving English version of movie %d: %s", movie.ID, err)
+		return m.options["language"]
+	}
+	if movie.Title == "" {
+		movie.Title = englishMovie.Title
+	}
+	if movie.Overview == "" && englishMovie.Overview != "" {
+		movie.Overview = englishMovie.Overview
+		return fallbackLanguage
+	}
+	return m.options["language"]
+}
+
+// applyTVEnglishFallback fills the empty name and overview of the TV show from its English version
+// if WithEnglishFallback is set, and returns the language of the overview (empty if the option is not set).
+func (m *mediaClient) applyTVEnglishFallback(tvShow *tmdb.TV) string {
+	if !m.clientOptions.englishFallback {
+		return ""
+	}
+	if tvShow.Overview != "" && tvShow.Name != "" {
+		return m.options["language"]
+	}
+	options := extractOptions(m.options)
+	options["language"] = fallbackLanguage
+	englishTVShow, err := callWithTimeout(m, func() (*tmdb.TV, error) {
+		return m.tmdbClient.GetTvInfo(tvShow.ID, options)
+	})
+	if err != nil {
+		m.clientOptions.logger.Warnf("Error while retrieving English version of TV show %d: %s", tvShow.ID, err)
+		return m.options["language"]
+	}
+	if tvShow.Name == "" {
+		tvShow.Name = englishTVShow.Name
+	}
+	if tvShow.Overview == "" && englishTVShow.Overview != "" {
+		tvShow.Overview = englishTVShow.Overview
+		return fallbackLanguage
+	}
+	return m.options["language"]
+}
+
+// MovieExists reports whether a movie with the given ID exists on TMDB, fetching only its info.
+// Only ErrNotFound is reported as a missing movie, other errors are returned.
+func (m *mediaClient) MovieExists(id int) (bool, error) {
+	if exists, ok := m.cache.GetMovieExists(id); ok {
+		return exists, nil
+	}
+	_, err := m.GetMovieShort(id)
+	if errors.Is(err, ErrNotFound) {
+		m.cache.AddMovieExists(id, false)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	m.cache.AddMovieExists(id, true)
+	return true, nil
+}
+
+// TVShowExists reports whether a TV show with the given ID exists on TMDB, fetching only its info.
+// Only ErrNotFound is reported as a missing TV show, other errors are returned.
+func (m *mediaClient) TVShowExists(id int) (bool, error) {
+	if exists, ok := m.cache.GetTVExists(id); ok {
+		return exists, nil
+	}
+	_, err := m.GetTVShowShort(id)
+	if errors.Is(err, ErrNotFound) {
+		m.cache.AddTVExists(id, false)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	m.cache.AddTVExists(id, true)
+	return true, nil
+}
+
 // GetTVEpisode retrieves the information of a TV episode by TV show ID, season number and episode number and returns a TVEpisode object.
 func (m *mediaClient) GetTVEpisode(tvID, season, episodeNumber int) (*TVEpisode, error) {
 	cachedEpisode := m.cache.GetEpisode(tvID, season, episodeNumber)
@@ -275,11 +726,17 @@ func (m *mediaClient) GetTVEpisode(tvID, season, episodeNumber int) (*TVEpisode,
 		return cachedEpisode, nil
 	}
 
-	episode, err := m.tmdbClient.GetTvEpisodeInfo(tvID, season, episodeNumber, m.options)
+	episode, err := callWithTimeout(m, func() (*tmdb.TvEpisode, error) {
+		return m.tmdbClient.GetTvEpisodeInfo(tvID, season, episodeNumber, extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
-	extracted := extractTVEpisode(tvID, episode)
+	fallbackURL := ""
+	if episode.StillPath == "" {
+		fallbackURL = m.episodeFallbackURL(tvID)
+	}
+	extracted := extractTVEpisode(tvID, episode, fallbackURL)
 	m.cache.AddEpisode(extracted)
 
 	return extracted, nil
@@ -292,31 +749,89 @@ func (m *mediaClient) GetTVSeasonEpisodes(tvID int, season int) ([]*TVEpisode, e
 		return cachedEpisodes, nil
 	}
 
-	episodes, err := m.tmdbClient.GetTvSeasonInfo(tvID, season, m.options)
+	episodes, err := callWithTimeout(m, func() (*tmdb.TvSeason, error) {
+		return m.tmdbClient.GetTvSeasonInfo(tvID, season, extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
+	}
+	fallbackURL := ""
+	for _, episode := range episodes.Episodes {
+		if episode.StillPath == "" {
+			fallbackURL = m.episodeFallbackURL(tvID)
+			break
+		}
 	}
 	var extractedEpisodes = make([]*TVEpisode, len(episodes.Episodes))
 	for i, episode := range episodes.Episodes {
-		extractedEpisodes[i] = extractTVEpisode(tvID, &episode)
+		extractedEpisodes[i] = extractTVEpisode(tvID, &episode, fallbackURL)
 	}
 	m.cache.AddSeason(tvID, season, extractedEpisodes)
 	return extractedEpisodes, nil
 }
 
+// GetTVAllSeasons retrieves the episodes of every season of a TV show concurrently, keyed by season number.
+// A season that cannot be retrieved is left out of the map and its error is joined to the returned error,
+// so a non-nil error may come with the seasons that were retrieved.
+func (m *mediaClient) GetTVAllSeasons(tvID int) (map[int][]*TVEpisode, error) {
+	tvShow, err := m.GetTVShowShort(tvID)
+	if err != nil {
+		return nil, err
+	}
+	seasons := make(map[int][]*TVEpisode, tvShow.SeasonsCount)
+	var errs []error
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	for seasonNumber := 1; seasonNumber <= tvShow.SeasonsCount; seasonNumber++ {
+		wg.Add(1)
+		go func(seasonNumber int) {
+			defer wg.Done()
+			m.sem <- struct{}{}
+			episodes, err := m.GetTVSeasonEpisodes(tvID, seasonNumber)
+			<-m.sem
+			lock.Lock()
+			defer lock.Unlock()
+			if err != nil {
+				errs = append(errs, fmt.Errorf("season %d: %w", seasonNumber, err))
+				return
+			}
+			seasons[seasonNumber] = episodes
+		}(seasonNumber)
+	}
+	wg.Wait()
+	return seasons, errors.Join(errs...)
+}
+
+// episodeFallbackURL returns the backdrop of the given TV show to use for its episodes without a still,
+// or an empty string if WithEpisodeBackdropFallback is not set or the TV show cannot be retrieved.
+func (m *mediaClient) episodeFallbackURL(tvID int) string {
+	if !m.clientOptions.episodeBackdropFallback {
+		return ""
+	}
+	tvShow, err := m.GetTVShowShort(tvID)
+	if err != nil {
+		m.clientOptions.logger.Errorf("Error while retrieving TV show %d backdrop: %s", tvID, err)
+		return ""
+	}
+	return tvShow.BackdropURL
+}
+
 // GetPopularMovies retrieves the most popular movies and returns a slice of Movie objects.
 func (m *mediaClient) GetPopularMovies(page int) (*PaginatedMovieResults, error) {
 	options := extractOptions(m.options)
 	options["page"] = strconv.Itoa(page)
-	movies, err := m.tmdbClient.GetMoviePopular(options)
+	movies, err := callWithTimeout(m, func() (*tmdb.MoviePagedResults, error) {
+		return m.tmdbClient.GetMoviePopular(options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedMovies = make([]*Movie, len(movies.Results))
 	for i, movie := range movies.Results {
-		extractedMovies[i] = extractMovieShort(&movie)
+		extractedMovies[i] = extractMovieShort(&movie, m.movieGenreNames())
 	}
 	return &PaginatedMovieResults{
+		Page:        page,
 		Results:     extractedMovies,
 		TotalPage:   movies.TotalPages,
 		TotalResult: movies.TotalResults,
@@ -327,32 +842,74 @@ func (m *mediaClient) GetPopularMovies(page int) (*PaginatedMovieResults, error)
 func (m *mediaClient) GetPopularTVShows(page int) (*PaginatedTVShowResults, error) {
 	options := extractOptions(m.options)
 	options["page"] = strconv.Itoa(page)
-	tvShows, err := m.tmdbClient.GetTvPopular(options)
+	tvShows, err := callWithTimeout(m, func() (*tmdb.TvPagedResults, error) {
+		return m.tmdbClient.GetTvPopular(options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedTVShows = make([]*TVShow, len(tvShows.Results))
 	for i, tvShow := range tvShows.Results {
-		extractedTVShows[i] = extractTVShowShort(&tvShow)
+		extractedTVShows[i] = extractTVShowShort(&tvShow, m.tvGenreNames())
 	}
 	return &PaginatedTVShowResults{
+		Page:        page,
 		Results:     extractedTVShows,
 		TotalPage:   tvShows.TotalPages,
 		TotalResult: tvShows.TotalResults,
 	}, nil
 }
 
+// GetNowPlayingMovies retrieves a page of the movies currently in theatres, as returned by TMDB.
+// Unlike GetRecentMovies, the results are neither truncated nor re-sorted.
+func (m *mediaClient) GetNowPlayingMovies(page int) (*PaginatedMovieResults, error) {
+	cachedResults := m.cache.GetNowPlayingMovies(page)
+	if cachedResults != nil {
+		return cachedResults, nil
+	}
+
+	options := extractOptions(m.options)
+	options["page"] = strconv.Itoa(page)
+	movies, err := callWithTimeout(m, func() (*tmdb.MovieDatedResults, error) {
+		return m.tmdbClient.GetMovieNowPlaying(options)
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	var extractedMovies = make([]*Movie, len(movies.Results))
+	for i, movie := range movies.Results {
+		extractedMovies[i] = extractMovieShort(&movie, m.movieGenreNames())
+	}
+	result := &PaginatedMovieResults{
+		Page:        page,
+		TotalPage:   movies.TotalPages,
+		TotalResult: movies.TotalResults,
+		Results:     extractedMovies,
+	}
+	m.cache.AddNowPlayingMovies(page, result)
+	return result, nil
+}
+
 // GetRecentMovies retrieves the most recent movies and returns a slice of Movie objects.
+// It returns the defaultRecentMoviesLimit most popular of the first defaultRecentMoviesPages pages
+// of the movies now playing in France, see GetRecentMoviesWindow.
 func (m *mediaClient) GetRecentMovies() ([]*Movie, error) {
+	return m.GetRecentMoviesWindow(defaultRecentMoviesPages, defaultRecentMoviesLimit)
+}
+
+// GetRecentMoviesWindow scans the given number of pages of the movies now playing in France (20 per page),
+// and returns the limit most popular ones, the most recent first.
+func (m *mediaClient) GetRecentMoviesWindow(pagesToScan, limit int) ([]*Movie, error) {
 	options := extractOptions(m.options)
 	options["region"] = "fr"
 	movies := make([]tmdb.MovieShort, 0)
-	// Get the 100 most recent movies in France (20 per page)
-	for page := 1; page <= 5; page++ {
+	for page := 1; page <= pagesToScan; page++ {
 		options["page"] = strconv.Itoa(page)
-		retrievedMovies, err := m.tmdbClient.GetMovieNowPlaying(options)
+		retrievedMovies, err := callWithTimeout(m, func() (*tmdb.MovieDatedResults, error) {
+			return m.tmdbClient.GetMovieNowPlaying(options)
+		})
 		if err != nil {
-			return nil, err
+			return nil, wrapError(err)
 		}
 		movies = append(movies, retrievedMovies.Results...)
 	}
@@ -360,10 +917,16 @@ func (m *mediaClient) GetRecentMovies() ([]*Movie, error) {
 	sort.Slice(movies, func(i, j int) bool {
 		return movies[i].Popularity > movies[j].Popularity
 	})
-	var extractedMovies = make([]*Movie, 0)
-	// Get the 20 most popular
-	for _, movie := range movies[:20] {
-		extractedMovies = append(extractedMovies, extractMovieShort(&movie))
+	if limit < 0 {
+		limit = 0
+	}
+	if limit > len(movies) {
+		limit = len(movies)
+	}
+	var extractedMovies = make([]*Movie, 0, limit)
+	// Get the most popular
+	for _, movie := range movies[:limit] {
+		extractedMovies = append(extractedMovies, extractMovieShort(&movie, m.movieGenreNames()))
 	}
 	// Sort them by release date (the most recent first)
 	sort.Slice(extractedMovies, func(i, j int) bool {
@@ -388,9 +951,11 @@ func (m *mediaClient) GetRecentTVShows() ([]*TVShow, error) {
 	// Get the 100 most recent tvshows in France (20 per page)
 	for page := 1; page <= 5; page++ {
 		options["page"] = strconv.Itoa(page)
-		retrievedTVShows, err := m.tmdbClient.GetTvAiringToday(options)
+		retrievedTVShows, err := callWithTimeout(m, func() (*tmdb.TvPagedResults, error) {
+			return m.tmdbClient.GetTvAiringToday(options)
+		})
 		if err != nil {
-			return nil, err
+			return nil, wrapError(err)
 		}
 		tvshows = append(tvshows, retrievedTVShows.Results...)
 	}
@@ -401,7 +966,7 @@ func (m *mediaClient) GetRecentTVShows() ([]*TVShow, error) {
 	var extractedTVShows = make([]*TVShow, 0)
 	// Get the 20 most popular
 	for _, tvshow := range tvshows[:20] {
-		extractedTVShows = append(extractedTVShows, extractTVShowShort(&tvshow))
+		extractedTVShows = append(extractedTVShows, extractTVShowShort(&tvshow, m.tvGenreNames()))
 	}
 	// Return result
 	return extractedTVShows, nil
@@ -420,15 +985,18 @@ func (m *mediaClient) SearchMovies(query string, page int, adult bool) (*Paginat
 	if adult {
 		options["include_adult"] = "true"
 	}
-	movies, err := m.tmdbClient.SearchMovie(query, options)
+	movies, err := callWithTimeout(m, func() (*tmdb.MovieSearchResults, error) {
+		return m.tmdbClient.SearchMovie(query, options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedMovies = make([]*Movie, len(movies.Results))
 	for i, movie := range movies.Results {
-		extractedMovies[i] = extractMovieShort(&movie)
+		extractedMovies[i] = extractMovieShort(&movie, m.movieGenreNames())
 	}
 	result := &PaginatedMovieResults{
+		Page:        page,
 		TotalPage:   movies.TotalPages,
 		TotalResult: movies.TotalResults,
 		Results:     extractedMovies,
@@ -448,15 +1016,18 @@ func (m *mediaClient) SearchMoviesYear(query string, year string, page int) (*Pa
 	options["page"] = strconv.Itoa(page)
 	options["region"] = "fr"
 	options["year"] = year
-	movies, err := m.tmdbClient.SearchMovie(query, options)
+	movies, err := callWithTimeout(m, func() (*tmdb.MovieSearchResults, error) {
+		return m.tmdbClient.SearchMovie(query, options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedMovies = make([]*Movie, len(movies.Results))
 	for i, movie := range movies.Results {
-		extractedMovies[i] = extractMovieShort(&movie)
+		extractedMovies[i] = extractMovieShort(&movie, m.movieGenreNames())
 	}
 	result := &PaginatedMovieResults{
+		Page:        page,
 		TotalPage:   movies.TotalPages,
 		TotalResult: movies.TotalResults,
 		Results:     extractedMovies,
@@ -477,15 +1048,18 @@ func (m *mediaClient) SearchTVShows(query string, page int, adult bool) (*Pagina
 	if adult {
 		options["include_adult"] = "true"
 	}
-	tvShows, err := m.tmdbClient.SearchTv(query, options)
+	tvShows, err := callWithTimeout(m, func() (*tmdb.TvSearchResults, error) {
+		return m.tmdbClient.SearchTv(query, options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedTVShows = make([]*TVShow, len(tvShows.Results))
 	for i, tvShow := range tvShows.Results {
 		extractedTVShows[i] = extractTVShowResult(&tvShow)
 	}
 	result := &PaginatedTVShowResults{
+		Page:        page,
 		TotalPage:   tvShows.TotalPages,
 		TotalResult: tvShows.TotalResults,
 		Results:     extractedTVShows,
@@ -494,7 +1068,8 @@ func (m *mediaClient) SearchTVShows(query string, page int, adult bool) (*Pagina
 	return result, nil
 }
 
-// SearchActors searches for actors matching the given query and returns a slice of Actor objects.
+// SearchActors searches for actors matching the given query and returns a slice of Actor objects,
+// sorted by popularity in descending order.
 func (m *mediaClient) SearchActors(query string, page int, adult bool) (*PaginatedActorResults, error) {
 	extractedResults := m.cache.GetActorSearchResults(query, page, adult)
 	if extractedResults != nil {
@@ -506,13 +1081,17 @@ func (m *mediaClient) SearchActors(query string, page int, adult bool) (*Paginat
 	if adult {
 		options["include_adult"] = "true"
 	}
-	actors, err := m.tmdbClient.SearchPerson(query, options)
+	actors, err := callWithTimeout(m, func() (*tmdb.PersonSearchResults, error) {
+		return m.tmdbClient.SearchPerson(query, options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedActors = extractActors(actors.Results)
+	sortActorsByPopularity(extractedActors)
 
 	result := &PaginatedActorResults{
+		Page:        page,
 		TotalPage:   actors.TotalPages,
 		TotalResult: actors.TotalResults,
 		Results:     extractedActors,
@@ -531,15 +1110,18 @@ func (m *mediaClient) GetMoviesByGenre(genreID int, page int) (*PaginatedMovieRe
 	options := extractOptions(m.options)
 	options["page"] = strconv.Itoa(page)
 	options["with_genres"] = strconv.Itoa(genreID)
-	movies, err := m.tmdbClient.DiscoverMovie(options)
+	movies, err := callWithTimeout(m, func() (*tmdb.MoviePagedResults, error) {
+		return m.tmdbClient.DiscoverMovie(options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedMovies = make([]*Movie, len(movies.Results))
 	for i, movie := range movies.Results {
-		extractedMovies[i] = extractMovieShort(&movie)
+		extractedMovies[i] = extractMovieShort(&movie, m.movieGenreNames())
 	}
 	result := &PaginatedMovieResults{
+		Page:        page,
 		TotalPage:   movies.TotalPages,
 		TotalResult: movies.TotalResults,
 		Results:     extractedMovies,
@@ -558,15 +1140,18 @@ func (m *mediaClient) GetTVShowsByGenre(genreID int, page int) (*PaginatedTVShow
 	options := extractOptions(m.options)
 	options["page"] = strconv.Itoa(page)
 	options["with_genres"] = strconv.Itoa(genreID)
-	tvShows, err := m.tmdbClient.DiscoverTV(options)
+	tvShows, err := callWithTimeout(m, func() (*tmdb.TvPagedResults, error) {
+		return m.tmdbClient.DiscoverTV(options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedTVShows = make([]*TVShow, len(tvShows.Results))
 	for i, tvShow := range tvShows.Results {
-		extractedTVShows[i] = extractTVShowShort(&tvShow)
+		extractedTVShows[i] = extractTVShowShort(&tvShow, m.tvGenreNames())
 	}
 	result := &PaginatedTVShowResults{
+		Page:        page,
 		TotalPage:   tvShows.TotalPages,
 		TotalResult: tvShows.TotalResults,
 		Results:     extractedTVShows,
@@ -585,15 +1170,18 @@ func (m *mediaClient) GetMoviesByActor(actorID int, page int) (*PaginatedMovieRe
 	options["page"] = strconv.Itoa(page)
 	options["with_cast"] = strconv.Itoa(actorID)
 	options["include_adult"] = "true"
-	movies, err := m.tmdbClient.DiscoverMovie(options)
+	movies, err := callWithTimeout(m, func() (*tmdb.MoviePagedResults, error) {
+		return m.tmdbClient.DiscoverMovie(options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedMovies = make([]*Movie, len(movies.Results))
 	for i, movie := range movies.Results {
-		extractedMovies[i] = extractMovieShort(&movie)
+		extractedMovies[i] = extractMovieShort(&movie, m.movieGenreNames())
 	}
 	result := &PaginatedMovieResults{
+		Page:        page,
 		TotalPage:   movies.TotalPages,
 		TotalResult: movies.TotalResults,
 		Results:     extractedMovies,
@@ -608,9 +1196,11 @@ func (m *mediaClient) GetTVShowsByActor(actorID int, page int) (*PaginatedTVShow
 		return cachedResults, nil
 	}
 
-	actorTVCredits, err := m.tmdbClient.GetPersonTvCredits(actorID, m.options)
+	actorTVCredits, err := callWithTimeout(m, func() (*tmdb.PersonTvCredits, error) {
+		return m.tmdbClient.GetPersonTvCredits(actorID, extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var wg sync.WaitGroup
 	var startIndex = int(math.Min(float64((page-1)*20), math.Max(0, float64(len(actorTVCredits.Cast)-1))))
@@ -621,9 +1211,11 @@ func (m *mediaClient) GetTVShowsByActor(actorID int, page int) (*PaginatedTVShow
 		wg.Add(1)
 		go func(tvShowID, index int) {
 			defer wg.Done()
+			m.sem <- struct{}{}
 			tvShow, err := m.GetTVShowShort(tvShowID)
+			<-m.sem
 			if err != nil {
-				log.Printf("Error while retrieving TV show %d: %s", tvShowID, err)
+				m.clientOptions.logger.Errorf("Error while retrieving TV show %d: %s", tvShowID, err)
 				return
 			}
 			lockIndexes[index].Lock()
@@ -634,7 +1226,8 @@ func (m *mediaClient) GetTVShowsByActor(actorID int, page int) (*PaginatedTVShow
 	wg.Wait()
 
 	result := &PaginatedTVShowResults{
-		TotalPage:   int(math.Round(float64(len(actorTVCredits.Cast)) / 20)),
+		Page:        page,
+		TotalPage:   int(math.Ceil(float64(len(actorTVCredits.Cast)) / 20)),
 		TotalResult: len(actorTVCredits.Cast),
 		Results:     extractedTVShows,
 	}
@@ -647,15 +1240,18 @@ func (m *mediaClient) GetMoviesByDirector(directorID int, page int) (*PaginatedM
 	options := extractOptions(m.options)
 	options["page"] = strconv.Itoa(page)
 	options["with_crew"] = strconv.Itoa(directorID)
-	movies, err := m.tmdbClient.DiscoverMovie(options)
+	movies, err := callWithTimeout(m, func() (*tmdb.MoviePagedResults, error) {
+		return m.tmdbClient.DiscoverMovie(options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedMovies = make([]*Movie, len(movies.Results))
 	for i, movie := range movies.Results {
-		extractedMovies[i] = extractMovieShort(&movie)
+		extractedMovies[i] = extractMovieShort(&movie, m.movieGenreNames())
 	}
 	return &PaginatedMovieResults{
+		Page:        page,
 		TotalPage:   movies.TotalPages,
 		TotalResult: movies.TotalResults,
 		Results:     extractedMovies,
@@ -672,15 +1268,18 @@ func (m *mediaClient) GetMoviesByStudio(studioID int, page int) (*PaginatedMovie
 	options["page"] = strconv.Itoa(page)
 	options["with_companies"] = strconv.Itoa(studioID)
 	options["include_adult"] = "true"
-	movies, err := m.tmdbClient.DiscoverMovie(options)
+	movies, err := callWithTimeout(m, func() (*tmdb.MoviePagedResults, error) {
+		return m.tmdbClient.DiscoverMovie(options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedMovies = make([]*Movie, len(movies.Results))
 	for i, movie := range movies.Results {
-		extractedMovies[i] = extractMovieShort(&movie)
+		extractedMovies[i] = extractMovieShort(&movie, m.movieGenreNames())
 	}
 	result := &PaginatedMovieResults{
+		Page:        page,
 		TotalPage:   movies.TotalPages,
 		TotalResult: movies.TotalResults,
 		Results:     extractedMovies,
@@ -699,15 +1298,18 @@ func (m *mediaClient) GetTVShowsByNetwork(studioID int, page int) (*PaginatedTVS
 	options["page"] = strconv.Itoa(page)
 	options["with_networks"] = strconv.Itoa(studioID)
 	options["include_adult"] = "true"
-	tvShows, err := m.tmdbClient.DiscoverTV(options)
+	tvShows, err := callWithTimeout(m, func() (*tmdb.TvPagedResults, error) {
+		return m.tmdbClient.DiscoverTV(options)
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	var extractedTVShows = make([]*TVShow, len(tvShows.Results))
 	for i, tvShow := range tvShows.Results {
-		extractedTVShows[i] = extractTVShowShort(&tvShow)
+		extractedTVShows[i] = extractTVShowShort(&tvShow, m.tvGenreNames())
 	}
 	result := &PaginatedTVShowResults{
+		Page:        page,
 		TotalPage:   tvShows.TotalPages,
 		TotalResult: tvShows.TotalResults,
 		Results:     extractedTVShows,
@@ -717,7 +1319,9 @@ func (m *mediaClient) GetTVShowsByNetwork(studioID int, page int) (*PaginatedTVS
 }
 
 // GetTVShowsReleases retrieves all TV shows airing between the given dates and returns a slice of TVEpisodeRelease objects.
-func (m *mediaClient) GetTVShowsReleases(tvIds []int, startDate, endDate time.Time) ([]*TVEpisode, []*TVShow, error) {
+// Specials (season 0) are only included if includeSpecials is true.
+// Air dates, which TMDB gives without timezone, are taken as midnight in loc (UTC if nil).
+func (m *mediaClient) GetTVShowsReleases(tvIds []int, startDate, endDate time.Time, includeSpecials bool, loc *time.Location) ([]*TVEpisode, []*TVShow, error) {
 	// Get all episodes for the given TV shows that are airing between the given dates
 	var episodes []*TVEpisode
 	var tvShows []*TVShow
@@ -727,46 +1331,19 @@ func (m *mediaClient) GetTVShowsReleases(tvIds []int, startDate, endDate time.Ti
 		wg.Add(1)
 		go func(tvID int) {
 			defer wg.Done()
+			m.sem <- struct{}{}
 			tvShow, err := m.GetTVShowShort(tvID)
+			<-m.sem
 			if err != nil {
-				log.Printf("Error while retrieving TV show %d: %s", tvID, err)
+				m.clientOptions.logger.Errorf("Error while retrieving TV show %d: %s", tvID, err)
 				return
 			}
-			// Get all episodes for the given TV show that are airing between the given dates
-			showAdded := false
-			for seasonNumber := 1; seasonNumber <= tvShow.SeasonsCount; seasonNumber++ {
-				wg.Add(1)
-				go func(tvID, seasonNumber int) {
-					defer wg.Done()
-					seasonEpisodes, err := m.GetTVSeasonEpisodes(tvID, seasonNumber)
-					if err != nil {
-						log.Printf("Error while retrieving TV show %d season %d: %s", tvID, seasonNumber, err)
-						return
-					}
-					var episodesToAdd []*TVEpisode
-					for _, episode := range seasonEpisodes {
-						airDate, err := time.Parse("2006-01-02", episode.AirDate)
-						if err != nil {
-							log.Printf("Could not parse air date %s for episode %d of TV show %d",
-								episode.AirDate, episode.ID, tvID)
-							continue
-						}
-						if (airDate.After(startDate) && airDate.Before(endDate)) ||
-							airDate.Equal(startDate) ||
-							airDate.Equal(endDate) {
-							episodesToAdd = append(episodesToAdd, episode)
-						}
-					}
-					if len(episodesToAdd) > 0 {
-						lock.Lock()
-						defer lock.Unlock()
-						episodes = append(episodes, episodesToAdd...)
-						if !showAdded {
-							tvShows = append(tvShows, tvShow)
-							showAdded = true
-						}
-					}
-				}(tvID, seasonNumber)
+			episodesToAdd := m.getEpisodesInRange(tvShow, startDate, endDate, includeSpecials, loc)
+			if len(episodesToAdd) > 0 {
+				lock.Lock()
+				defer lock.Unlock()
+				episodes = append(episodes, episodesToAdd...)
+				tvShows = append(tvShows, tvShow)
 			}
 		}(tvID)
 	}
@@ -774,6 +1351,67 @@ func (m *mediaClient) GetTVShowsReleases(tvIds []int, startDate, endDate time.Ti
 	return episodes, tvShows, nil
 }
 
+// GetShowEpisodesInRange retrieves the episodes of a TV show airing between the given dates,
+// sorted by season and episode number. Specials (season 0) are only included if includeSpecials is true.
+// Air dates, which TMDB gives without timezone, are taken as midnight in loc (UTC if nil).
+func (m *mediaClient) GetShowEpisodesInRange(tvID int, startDate, endDate time.Time, includeSpecials bool, loc *time.Location) ([]*TVEpisode, error) {
+	tvShow, err := m.GetTVShowShort(tvID)
+	if err != nil {
+		return nil, err
+	}
+	return m.getEpisodesInRange(tvShow, startDate, endDate, includeSpecials, loc), nil
+}
+
+// getEpisodesInRange fetches the seasons of the given TV show concurrently and returns the episodes
+// airing between the given dates, sorted by season and episode number.
+// Season 0, holding the specials, is only fetched if includeSpecials is true.
+// Seasons that cannot be retrieved are logged and skipped.
+func (m *mediaClient) getEpisodesInRange(tvShow *TVShow, startDate, endDate time.Time, includeSpecials bool, loc *time.Location) []*TVEpisode {
+	var episodes []*TVEpisode
+	var lock sync.Mutex
+	var wg sync.WaitGroup
+	firstSeason := 1
+	if includeSpecials {
+		firstSeason = 0
+	}
+	for seasonNumber := firstSeason; seasonNumber <= tvShow.SeasonsCount; seasonNumber++ {
+		wg.Add(1)
+		go func(tvID, seasonNumber int) {
+			defer wg.Done()
+			m.sem <- struct{}{}
+			seasonEpisodes, err := m.GetTVSeasonEpisodes(tvID, seasonNumber)
+			<-m.sem
+			if err != nil {
+				m.clientOptions.logger.Errorf("Error while retrieving TV show %d season %d: %s", tvID, seasonNumber, err)
+				return
+			}
+			var episodesToAdd []*TVEpisode
+			for _, episode := range seasonEpisodes {
+				inRange, err := isDateInRange(episode.AirDate, loc, startDate, endDate)
+				if err != nil {
+					m.clientOptions.logger.Warnf("Could not parse air date %s for episode %d of TV show %d",
+						episode.AirDate, episode.ID, tvID)
+					continue
+				}
+				if inRange {
+					episodesToAdd = append(episodesToAdd, episode)
+				}
+			}
+			lock.Lock()
+			defer lock.Unlock()
+			episodes = append(episodes, episodesToAdd...)
+		}(tvShow.ID, seasonNumber)
+	}
+	wg.Wait()
+	sort.Slice(episodes, func(i, j int) bool {
+		if episodes[i].SeasonNumber != episodes[j].SeasonNumber {
+			return episodes[i].SeasonNumber < episodes[j].SeasonNumber
+		}
+		return episodes[i].EpisodeNumber < episodes[j].EpisodeNumber
+	})
+	return episodes
+}
+
 // GetMoviesReleases retrieves all movies released between the given dates and returns a slice of MovieRelease objects.
 func (m *mediaClient) GetMoviesReleases(movieIds []int, startDate, endDate time.Time) ([]*Movie, error) {
 	var movies []*Movie
@@ -783,20 +1421,20 @@ func (m *mediaClient) GetMoviesReleases(movieIds []int, startDate, endDate time.
 		wg.Add(1)
 		go func(movieID int) {
 			defer wg.Done()
+			m.sem <- struct{}{}
 			movie, err := m.GetMovieShort(movieID)
+			<-m.sem
 			if err != nil {
-				log.Printf("Error while retrieving movie %d: %s", movieID, err)
+				m.clientOptions.logger.Errorf("Error while retrieving movie %d: %s", movieID, err)
 				return
 			}
-			airDate, err := time.Parse("2006-01-02", movie.ReleaseDate)
+			inRange, err := isDateInRange(movie.ReleaseDate, time.UTC, startDate, endDate)
 			if err != nil {
-				log.Printf("Could not parse air date %s for movie %d",
+				m.clientOptions.logger.Warnf("Could not parse air date %s for movie %d",
 					movie.ReleaseDate, movie.ID)
 				return
 			}
-			if (airDate.After(startDate) && airDate.Before(endDate)) ||
-				airDate.Equal(startDate) ||
-				airDate.Equal(endDate) {
+			if inRange {
 				lock.Lock()
 				defer lock.Unlock()
 				movies = append(movies, movie)
@@ -807,15 +1445,56 @@ func (m *mediaClient) GetMoviesReleases(movieIds []int, startDate, endDate time.
 	return movies, nil
 }
 
+// GetCollectionOrderedMovies retrieves the movies of a collection (e.g. a trilogy) sorted by release date,
+// the movies without a valid release date last.
+func (m *mediaClient) GetCollectionOrderedMovies(collectionID int) ([]*Movie, error) {
+	cachedMovies := m.cache.GetCollectionMovies(collectionID)
+	if cachedMovies != nil {
+		return cachedMovies, nil
+	}
+	collection, err := callWithTimeout(m, func() (*tmdb.Collection, error) {
+		return m.tmdbClient.GetCollectionInfo(collectionID, extractOptions(m.options))
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	movies := make([]*Movie, len(collection.Parts))
+	releaseDates := make(map[int]time.Time, len(collection.Parts))
+	for i, part := range collection.Parts {
+		movies[i] = &Movie{
+			ID:          part.ID,
+			BackdropURL: backdropImgURL(part.BackdropPath),
+			PosterURL:   posterImgURL(part.PosterPath),
+			Title:       part.Title,
+			ReleaseDate: part.ReleaseDate,
+		}
+		if releaseDate, err := time.Parse("2006-01-02", part.ReleaseDate); err == nil {
+			releaseDates[part.ID] = releaseDate
+		}
+	}
+	sort.SliceStable(movies, func(i, j int) bool {
+		releaseDateI, okI := releaseDates[movies[i].ID]
+		releaseDateJ, okJ := releaseDates[movies[j].ID]
+		if !okI || !okJ {
+			return okI && !okJ
+		}
+		return releaseDateI.Before(releaseDateJ)
+	})
+	m.cache.AddCollectionMovies(collectionID, movies)
+	return movies, nil
+}
+
 // GetMovieRecommendations retrieves movie recommendations for the given movie and returns a slice of Movie objects.
 func (m *mediaClient) GetMovieRecommendations(movieID int) ([]*Movie, error) {
 	cachedResults := m.cache.GetMovieRecommendations(movieID)
 	if cachedResults != nil {
 		return cachedResults, nil
 	}
-	recommendations, err := m.tmdbClient.GetMovieRecommendations(movieID, m.options)
+	recommendations, err := callWithTimeout(m, func() (*tmdb.MovieRecommendations, error) {
+		return m.tmdbClient.GetMovieRecommendations(movieID, extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	movies := make([]*Movie, len(recommendations.Results))
 	for i, movieRecommendation := range recommendations.Results {
@@ -828,7 +1507,8 @@ func (m *mediaClient) GetMovieRecommendations(movieID int) ([]*Movie, error) {
 			BackdropPath: movieRecommendation.BackdropPath,
 			VoteAverage:  movieRecommendation.VoteAverage,
 			VoteCount:    movieRecommendation.VoteCount,
-		})
+			GenreIDs:     toGenreIDs(movieRecommendation.GenreIDs),
+		}, m.movieGenreNames())
 	}
 	m.cache.AddMovieRecommendations(movieID, movies)
 	return movies, nil
@@ -840,9 +1520,11 @@ func (m *mediaClient) GetTVShowRecommendations(tvShowID int) ([]*TVShow, error)
 	if cachedResults != nil {
 		return cachedResults, nil
 	}
-	recommendations, err := m.tmdbClient.GetTvRecommendations(tvShowID, m.options)
+	recommendations, err := callWithTimeout(m, func() (*tmdb.TvRecommendations, error) {
+		return m.tmdbClient.GetTvRecommendations(tvShowID, extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	tvShows := make([]*TVShow, len(recommendations.Results))
 	for i, tvShowRecommendation := range recommendations.Results {
@@ -855,7 +1537,8 @@ func (m *mediaClient) GetTVShowRecommendations(tvShowID int) ([]*TVShow, error)
 			BackdropPath: tvShowRecommendation.BackdropPath,
 			VoteAverage:  tvShowRecommendation.VoteAverage,
 			VoteCount:    tvShowRecommendation.VoteCount,
-		})
+			GenreIDs:     toGenreIDs(tvShowRecommendation.GenreIDs),
+		}, m.tvGenreNames())
 	}
 	m.cache.AddTVRecommendations(tvShowID, tvShows)
 	return tvShows, nil
@@ -867,9 +1550,11 @@ func (m *mediaClient) GetMovieGenre(genreID int) (*Genre, error) {
 		return cachedGenre, nil
 	}
 
-	genres, err := m.tmdbClient.GetMovieGenres(m.options)
+	genres, err := callWithTimeout(m, func() (*tmdb.Genre, error) {
+		return m.tmdbClient.GetMovieGenres(extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	for _, genre := range genres.Genres {
 		if genre.ID == genreID {
@@ -890,9 +1575,11 @@ func (m *mediaClient) GetTVGenre(genreID int) (*Genre, error) {
 		return cachedGenre, nil
 	}
 
-	genres, err := m.tmdbClient.GetTvGenres(m.options)
+	genres, err := callWithTimeout(m, func() (*tmdb.Genre, error) {
+		return m.tmdbClient.GetTvGenres(extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	for _, genre := range genres.Genres {
 		if genre.ID == genreID {
@@ -908,9 +1595,11 @@ func (m *mediaClient) GetTVGenre(genreID int) (*Genre, error) {
 }
 
 func (m *mediaClient) GetMovieGenres() ([]*Genre, error) {
-	genres, err := m.tmdbClient.GetMovieGenres(m.options)
+	genres, err := callWithTimeout(m, func() (*tmdb.Genre, error) {
+		return m.tmdbClient.GetMovieGenres(extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	movieGenres := make([]*Genre, len(genres.Genres))
 	for i, genre := range genres.Genres {
@@ -922,11 +1611,93 @@ func (m *mediaClient) GetMovieGenres() ([]*Genre, error) {
 	return movieGenres, nil
 }
 
-func (m *mediaClient) GetTVShowGenres() ([]*Genre, error) {
-	genres, err := m.tmdbClient.GetTvGenres(m.options)
+// movieGenreNames returns the names of the movie genres by ID if WithGenreEnrichment is set, nil otherwise.
+// The genre list is retrieved once per client; nil is returned until it could be retrieved.
+func (m *mediaClient) movieGenreNames() map[int]string {
+	if !m.clientOptions.genreEnrichment {
+		return nil
+	}
+	names, err := m.loadGenreNames(&m.genres.movie, m.GetMovieGenres)
+	if err != nil {
+		m.clientOptions.logger.Warnf("Error while retrieving movie genres: %s", err)
+	}
+	return names
+}
+
+// tvGenreNames returns the names of the TV show genres by ID if WithGenreEnrichment is set, nil otherwise.
+func (m *mediaClient) tvGenreNames() map[int]string {
+	if !m.clientOptions.genreEnrichment {
+		return nil
+	}
+	names, err := m.loadGenreNames(&m.genres.tv, m.GetTVShowGenres)
+	if err != nil {
+		m.clientOptions.logger.Warnf("Error while retrieving TV show genres: %s", err)
+	}
+	return names
+}
+
+// loadGenreNames returns the names of the genres by ID, retrieving them with getGenres on first use.
+func (m *mediaClient) loadGenreNames(names *map[int]string, getGenres func() ([]*Genre, error)) (map[int]string, error) {
+	m.genres.lock.Lock()
+	defer m.genres.lock.Unlock()
+	if *names != nil {
+		return *names, nil
+	}
+	genres, err := getGenres()
 	if err != nil {
 		return nil, err
 	}
+	*names = make(map[int]string, len(genres))
+	for _, genre := range genres {
+		(*names)[genre.ID] = genre.Name
+	}
+	return *names, nil
+}
+
+// GenreIDByName returns the ID of the genre with the given name, compared case-insensitively,
+// for the given media type ("movie" or "tv"). It returns ErrNotFound for an unknown name.
+func (m *mediaClient) GenreIDByName(name, mediaType string) (int, error) {
+	var names map[int]string
+	var err error
+	switch mediaType {
+	case "movie":
+		names, err = m.loadGenreNames(&m.genres.movie, m.GetMovieGenres)
+	case "tv":
+		names, err = m.loadGenreNames(&m.genres.tv, m.GetTVShowGenres)
+	default:
+		return 0, fmt.Errorf("unknown media type %q", mediaType)
+	}
+	if err != nil {
+		return 0, err
+	}
+	for id, genreName := range names {
+		if strings.EqualFold(genreName, strings.TrimSpace(name)) {
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s genre %q", ErrNotFound, mediaType, name)
+}
+
+// GenreNamesToIDs returns the IDs of the genres with the given names, see GenreIDByName.
+func (m *mediaClient) GenreNamesToIDs(names []string, mediaType string) ([]int, error) {
+	var ids = make([]int, len(names))
+	for i, name := range names {
+		id, err := m.GenreIDByName(name, mediaType)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
+
+func (m *mediaClient) GetTVShowGenres() ([]*Genre, error) {
+	genres, err := callWithTimeout(m, func() (*tmdb.Genre, error) {
+		return m.tmdbClient.GetTvGenres(extractOptions(m.options))
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
 	tvGenres := make([]*Genre, len(genres.Genres))
 	for i, genre := range genres.Genres {
 		tvGenres[i] = &Genre{
@@ -943,42 +1714,130 @@ func (m *mediaClient) GetActor(actorID int) (*Actor, error) {
 		return cachedActor, nil
 	}
 
-	response, err := m.tmdbClient.GetPersonInfo(actorID, m.options)
+	response, err := callWithTimeout(m, func() (*tmdb.Person, error) {
+		return m.tmdbClient.GetPersonInfo(actorID, extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
 	actor := &Actor{
 		ID:         response.ID,
 		Name:       response.Name,
 		ProfileURL: profileImgURL(response.ProfilePath),
 		Overview:   response.Biography,
+		Gender:     response.Gender,
 	}
 	m.cache.AddActor(actor)
 	return actor, nil
 }
 
+// GetPersonImages retrieves the URLs of all the profile images of a person, so that a secondary
+// image can be used when the primary profile path is missing.
+func (m *mediaClient) GetPersonImages(personID int) ([]string, error) {
+	cachedImages := m.cache.GetPersonImages(personID)
+	if cachedImages != nil {
+		return cachedImages, nil
+	}
+
+	response, err := callWithTimeout(m, func() (*tmdb.PersonImages, error) {
+		return m.tmdbClient.GetPersonImages(personID)
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	var images = make([]string, 0, len(response.Profiles))
+	for _, profile := range response.Profiles {
+		if profile.FilePath != "" {
+			images = append(images, imageBaseURL+profile.FilePath)
+		}
+	}
+	m.cache.AddPersonImages(personID, images)
+	return images, nil
+}
+
 func (m *mediaClient) GetStudio(studioID int) (*Studio, error) {
-	response, err := m.tmdbClient.GetCompanyInfo(studioID, m.options)
+	cachedStudio := m.cache.GetStudio(studioID)
+	if cachedStudio != nil {
+		return cachedStudio, nil
+	}
+
+	response, err := callWithTimeout(m, func() (*tmdb.Company, error) {
+		return m.tmdbClient.GetCompanyInfo(studioID, extractOptions(m.options))
+	})
 	if err != nil {
-		return nil, err
+		return nil, wrapError(err)
 	}
-	return &Studio{
+	studio := &Studio{
 		ID:      response.ID,
 		Name:    response.Name,
 		LogoURL: profileImgURL(response.LogoPath),
-	}, nil
+	}
+	m.cache.AddStudio(studio)
+	return studio, nil
+}
+
+// networkDetails is the subset of the TMDB network details read by GetNetwork.
+type networkDetails struct {
+	ID       int    `json:"id"`
+	Name     string `json:"name"`
+	LogoPath string `json:"logo_path"`
 }
 
 func (m *mediaClient) GetNetwork(networkID int) (*Studio, error) {
-	response, err := m.tmdbClient.GetNetworkInfo(networkID)
-	if err != nil {
+	cachedNetwork := m.cache.GetNetwork(networkID)
+	if cachedNetwork != nil {
+		return cachedNetwork, nil
+	}
+
+	// go-tmdb's network details lack the logo path, so the endpoint is called directly.
+	var response networkDetails
+	if err := m.getJSON("/network/"+strconv.Itoa(networkID), nil, &response); err != nil {
 		return nil, err
 	}
-	return &Studio{
+	network := &Studio{
 		ID:      response.ID,
 		Name:    response.Name,
-		LogoURL: profileImgURL(""),
-	}, nil
+		LogoURL: profileImgURL(response.LogoPath),
+	}
+	m.cache.AddNetwork(network)
+	return network, nil
+}
+
+// FindByIMDbID retrieves the movie, TV show or episode matching the given IMDb ID.
+func (m *mediaClient) FindByIMDbID(imdbID string) (*FindResult, error) {
+	cachedResult := m.cache.GetFindResult(imdbID)
+	if cachedResult != nil {
+		return cachedResult, nil
+	}
+
+	results, err := callWithTimeout(m, func() (*tmdb.FindResults, error) {
+		return m.tmdbClient.GetFind(imdbID, "imdb_id", extractOptions(m.options))
+	})
+	if err != nil {
+		return nil, wrapError(err)
+	}
+	result := &FindResult{}
+	switch {
+	case len(results.MovieResults) > 0:
+		result.Movie = extractMovieShort(&results.MovieResults[0], m.movieGenreNames())
+	case len(results.TvResults) > 0:
+		result.TVShow = extractTVShowShort(&results.TvResults[0], m.tvGenreNames())
+	case len(results.TvEpisodeResults) > 0:
+		episode := results.TvEpisodeResults[0]
+		result.Episode = &TVEpisode{
+			ID:            episode.ID,
+			TVShowID:      episode.ShowID,
+			PosterURL:     backdropImgURL(episode.StillPath),
+			EpisodeNumber: episode.EpisodeNumber,
+			SeasonNumber:  episode.SeasonNumber,
+			Name:          episode.Name,
+			AirDate:       episode.AirDate,
+		}
+	default:
+		return nil, fmt.Errorf("no media found for IMDb ID %s", imdbID)
+	}
+	m.cache.AddFindResult(imdbID, result)
+	return result, nil
 }
 
 // extractMovie extracts movie information from a tmdb.Movie object and returns a Movie object.
@@ -1000,9 +1859,79 @@ func extractMovie(movie *tmdb.Movie, credits *tmdb.MovieCredits) *Movie {
 	}
 }
 
+// extractMovieVideos extracts videos from movie videos and returns a list of Video.
+func extractMovieVideos(videos *tmdb.MovieVideos) []Video {
+	if videos == nil {
+		return nil
+	}
+	var extractedVideos = make([]Video, len(videos.Results))
+	for i, video := range videos.Results {
+		extractedVideos[i] = Video{
+			Key:  video.Key,
+			Name: video.Name,
+			Site: video.Site,
+			Type: video.Type,
+		}
+	}
+	return extractedVideos
+}
+
+// extractImageURLs returns the URLs of a list of images using the given URL builder.
+func extractImageURLs(images []tmdb.MovieImage, imgURL func(path string) string) []string {
+	var urls = make([]string, len(images))
+	for i, image := range images {
+		urls[i] = imgURL(image.FilePath)
+	}
+	return urls
+}
+
+// extractReleaseDates returns the release dates of the movie in the given region.
+func extractReleaseDates(releaseDates *movieReleaseDates, region string) []ReleaseDate {
+	if releaseDates == nil {
+		return nil
+	}
+	for _, country := range releaseDates.Results {
+		if !strings.EqualFold(country.Iso3166_1, region) {
+			continue
+		}
+		var extracted = make([]ReleaseDate, len(country.ReleaseDates))
+		for i, release := range country.ReleaseDates {
+			extracted[i] = ReleaseDate{
+				Certification: release.Certification,
+				Date:          release.ReleaseDate,
+				Note:          release.Note,
+				Type:          release.Type,
+			}
+		}
+		return extracted
+	}
+	return nil
+}
+
+// extractCertification returns the first certification among the release dates, or an empty string if none.
+func extractCertification(releaseDates []ReleaseDate) string {
+	for _, release := range releaseDates {
+		if release.Certification != "" {
+			return release.Certification
+		}
+	}
+	return ""
+}
+
+// imageLanguages returns the include_image_language value for the given language:
+// the language itself, English, and images without text.
+func imageLanguages(language string) string {
+	if language == "" || language == "en" {
+		return "en,null"
+	}
+	return language + ",en,null"
+}
+
 // extractMovieShort extracts movie information from a tmdb.MovieShort object and returns a Movie object.
-func extractMovieShort(movie *tmdb.MovieShort) *Movie {
+// Its genres are only set if genreNames, mapping the genre IDs to their names, is not nil.
+func extractMovieShort(movie *tmdb.MovieShort, genreNames map[int]string) *Movie {
 	return &Movie{
+		Genres:      extractGenreIDs(movie.GenreIDs, genreNames),
 		ID:          movie.ID,
 		BackdropURL: backdropImgURL(movie.BackdropPath),
 		PosterURL:   posterImgURL(movie.PosterPath),
@@ -1014,20 +1943,62 @@ func extractMovieShort(movie *tmdb.MovieShort) *Movie {
 	}
 }
 
-// extractTVShow extracts TV show information from a tmdb.TVShow object and returns a TVShow object.
-func extractTVEpisode(tvId int, episode *tmdb.TvEpisode) *TVEpisode {
+// extractTVEpisode extracts episode information from a tmdb.TvEpisode object and returns a TVEpisode object.
+// The fallbackURL, if not empty, is used instead of the empty backdrop when the episode has no still.
+func extractTVEpisode(tvId int, episode *tmdb.TvEpisode, fallbackURL string) *TVEpisode {
+	posterURL := backdropImgURL(episode.StillPath)
+	if episode.StillPath == "" && fallbackURL != "" {
+		posterURL = fallbackURL
+	}
 	return &TVEpisode{
 		ID:            episode.ID,
 		TVShowID:      tvId,
-		PosterURL:     backdropImgURL(episode.StillPath),
+		PosterURL:     posterURL,
 		EpisodeNumber: episode.EpisodeNumber,
 		SeasonNumber:  episode.SeasonNumber,
 		Name:          episode.Name,
 		Overview:      episode.Overview,
 		AirDate:       episode.AirDate,
+		GuestStars:    extractEpisodeGuestStars(episode),
+		Crew:          extractEpisodeCrew(episode),
 	}
 }
 
+// extractEpisodeGuestStars extracts the guest stars of an episode and returns a list of Person.
+func extractEpisodeGuestStars(episode *tmdb.TvEpisode) []Person {
+	if len(episode.GuestStars) == 0 {
+		return nil
+	}
+	var guestStars = make([]Person, len(episode.GuestStars))
+	for i, guestStar := range episode.GuestStars {
+		guestStars[i] = Person{
+			ID:         guestStar.ID,
+			Character:  guestStar.Character,
+			Name:       guestStar.Name,
+			ProfileURL: profileImgURL(guestStar.ProfilePath),
+		}
+	}
+	return guestStars
+}
+
+// extractEpisodeCrew extracts the crew of an episode and returns a list of Person.
+func extractEpisodeCrew(episode *tmdb.TvEpisode) []Person {
+	if len(episode.Crew) == 0 {
+		return nil
+	}
+	var crew = make([]Person, len(episode.Crew))
+	for i, member := range episode.Crew {
+		crew[i] = Person{
+			ID:         member.ID,
+			Character:  member.Job,
+			Name:       member.Name,
+			ProfileURL: profileImgURL(member.ProfilePath),
+			Department: member.Department,
+		}
+	}
+	return crew
+}
+
 // extractTVShow extracts TV show information from a tmdb.TVShow object and returns a TVShow object.
 func extractTVShow(tvShow *tmdb.TV, credits *tmdb.TvCredits) *TVShow {
 	return &TVShow{
@@ -1065,8 +2036,10 @@ func extractTVShow(tvShow *tmdb.TV, credits *tmdb.TvCredits) *TVShow {
 }
 
 // extractTVShowShort extracts TV show information from a tmdb.TVShowShort object and returns a TVShow object.
-func extractTVShowShort(tvShow *tmdb.TvShort) *TVShow {
+// Its genres are only set if genreNames, mapping the genre IDs to their names, is not nil.
+func extractTVShowShort(tvShow *tmdb.TvShort, genreNames map[int]string) *TVShow {
 	return &TVShow{
+		Genres:      extractGenreIDs(tvShow.GenreIDs, genreNames),
 		ID:          tvShow.ID,
 		BackdropURL: backdropImgURL(tvShow.BackdropPath),
 		PosterURL:   posterImgURL(tvShow.PosterPath),
@@ -1135,6 +2108,13 @@ func extractTVActors(credits *tmdb.TvCredits) *[]Person {
 	return &actors
 }
 
+// sortActorsByPopularity sorts the actors by decreasing popularity, keeping the TMDB order of equally popular ones.
+func sortActorsByPopularity(actors []*Actor) {
+	sort.SliceStable(actors, func(i, j int) bool {
+		return actors[i].Popularity > actors[j].Popularity
+	})
+}
+
 // extractActors extracts actors from credits and returns a list of Person.
 func extractActors(actors []struct {
 	Adult       bool
@@ -1162,6 +2142,16 @@ func extractActors(actors []struct {
 			ID:         actor.ID,
 			Name:       actor.Name,
 			ProfileURL: profileImgURL(actor.ProfilePath),
+			Popularity: actor.Popularity,
+		}
+		for _, media := range actor.KnownFor {
+			title := media.Title
+			if title == "" {
+				title = media.OriginalTitle
+			}
+			if title != "" {
+				cast[i].KnownFor = append(cast[i].KnownFor, title)
+			}
 		}
 	}
 	return cast
@@ -1179,6 +2169,7 @@ func extractMovieCrew(credits *tmdb.MovieCredits) *[]Person {
 			Character:  cast.Job,
 			Name:       cast.Name,
 			ProfileURL: profileImgURL(cast.ProfilePath),
+			Department: cast.Department,
 		}
 	}
 	return &crew
@@ -1196,6 +2187,7 @@ func extractTVCrew(credits *tmdb.TvCredits) *[]Person {
 			Character:  cast.Job,
 			Name:       cast.Name,
 			ProfileURL: profileImgURL(cast.ProfilePath),
+			Department: cast.Department,
 		}
 	}
 	return &crew
@@ -1216,6 +2208,30 @@ func extractGenres(genres *[]struct {
 	return &extractedGenres
 }
 
+// extractGenreIDs resolves the genre IDs of a short result with genreNames and returns a list of Genre,
+// or nil if genreNames is nil. Unknown IDs are skipped.
+func extractGenreIDs(genreIDs []int32, genreNames map[int]string) []Genre {
+	if genreNames == nil {
+		return nil
+	}
+	var genres = make([]Genre, 0, len(genreIDs))
+	for _, id := range genreIDs {
+		if name, ok := genreNames[int(id)]; ok {
+			genres = append(genres, Genre{ID: int(id), Name: name})
+		}
+	}
+	return genres
+}
+
+// toGenreIDs converts the genre IDs of the recommendation results to those of the short results.
+func toGenreIDs(ids []int) []int32 {
+	var genreIDs = make([]int32, len(ids))
+	for i, id := range ids {
+		genreIDs[i] = int32(id)
+	}
+	return genreIDs
+}
+
 // extractStudios extracts studios from a list of studio structs and returns a list of Studio.
 func extractStudios(studios *[]struct {
 	ID        int
@@ -1226,9 +2242,10 @@ func extractStudios(studios *[]struct {
 	var extractedStudios = make([]Studio, len(*studios))
 	for i, studio := range *studios {
 		extractedStudios[i] = Studio{
-			ID:      studio.ID,
-			Name:    studio.Name,
-			LogoURL: profileImgURL(studio.LogoPath),
+			ID:            studio.ID,
+			Name:          studio.Name,
+			LogoURL:       profileImgURL(studio.LogoPath),
+			OriginCountry: studio.Iso3166_1,
 		}
 	}
 	return &extractedStudios
@@ -1258,6 +2275,22 @@ func posterImgURL(path string) string {
 	return imageBaseURL + path
 }
 
+// isDateInRange parses a TMDB date (YYYY-MM-DD) as midnight in loc (UTC if nil) and reports whether
+// it is between the given dates, inclusive.
+func isDateInRange(date string, loc *time.Location, startDate, endDate time.Time) (bool, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	parsed, err := time.ParseInLocation("2006-01-02", date, loc)
+	if err != nil {
+		return false, err
+	}
+	return (parsed.After(startDate) && parsed.Before(endDate)) ||
+		parsed.Equal(startDate) ||
+		parsed.Equal(endDate), nil
+}
+
+// extractOptions returns a copy of the given query options, which the caller may modify.
 func extractOptions(options map[string]string) map[string]string {
 	var opts = make(map[string]string)
 	for key, value := range options {
@@ -0,0 +1,1326 @@
+package tmdb
+
+import (
+	"errors"
+	"fmt"
+	"github.com/ryanbradynd05/go-tmdb"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeTMDB stubs the go-tmdb calls made by the tests. Like a careless library could, it writes into the
+// options map it receives, so that a client passing its own options instead of a copy gets caught.
+// The calls not overridden panic.
+type fakeTMDB struct {
+	tmdbAPI
+	// err, when set, is returned by every call
+	err   error
+	lock  sync.Mutex
+	calls int
+	// options holds the options of the last call
+	options map[string]string
+	// delay is the duration of every call; inFlight counts the calls in progress, up to maxInFlight
+	delay       time.Duration
+	inFlight    int
+	maxInFlight int
+	// find holds the results of GetFind by external ID
+	find map[string]*tmdb.FindResults
+	// movieCredits and tvCredits hold the credits by movie and TV show ID
+	movieCredits map[int]*tmdb.MovieCredits
+	tvCredits    map[int]*tmdb.TvCredits
+	// seasonCredits holds the credits by "showID/seasonNumber"; a missing season is reported as not found
+	seasonCredits map[string]*tmdb.TvCredits
+	// personImages holds the images by person ID
+	personImages map[int]*tmdb.PersonImages
+	// nowPlaying holds the pages of GetMovieNowPlaying by page option
+	nowPlaying map[string]*tmdb.MovieDatedResults
+	// tvShows holds the TV shows by ID, seasons the seasons by "showID/seasonNumber";
+	// a missing season is reported as not found
+	tvShows map[int]*tmdb.TV
+	seasons map[string]*tmdb.TvSeason
+	// movies holds the movies by ID; a missing movie is reported as not found
+	movies map[int]*tmdb.Movie
+	// englishMovies and englishTVShows hold the movies and TV shows returned for the "en" language
+	englishMovies  map[int]*tmdb.Movie
+	englishTVShows map[int]*tmdb.TV
+	// movieGenres and tvGenres hold the genre lists
+	movieGenres *tmdb.Genre
+	tvGenres    *tmdb.Genre
+	// personTvCredits holds the TV credits by person ID
+	personTvCredits map[int]*tmdb.PersonTvCredits
+	// collections holds the collections by ID
+	collections map[int]*tmdb.Collection
+	// companies holds the studios by ID
+	companies map[int]*tmdb.Company
+}
+
+func (f *fakeTMDB) call(options map[string]string) error {
+	f.lock.Lock()
+	f.calls++
+	if options != nil {
+		options["call"] = strconv.Itoa(f.calls)
+	}
+	f.options = options
+	f.inFlight++
+	if f.inFlight > f.maxInFlight {
+		f.maxInFlight = f.inFlight
+	}
+	f.lock.Unlock()
+
+	time.Sleep(f.delay)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.inFlight--
+	return f.err
+}
+
+func (f *fakeTMDB) callCount() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.calls
+}
+
+func (f *fakeTMDB) maxConcurrentCalls() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.maxInFlight
+}
+
+func (f *fakeTMDB) lastOptions() map[string]string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.options
+}
+
+func (f *fakeTMDB) GetFind(id, source string, options map[string]string) (*tmdb.FindResults, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	if results, ok := f.find[source+":"+id]; ok {
+		return results, nil
+	}
+	return &tmdb.FindResults{}, nil
+}
+
+func (f *fakeTMDB) GetMovieCredits(id int, options map[string]string) (*tmdb.MovieCredits, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	return f.movieCredits[id], nil
+}
+
+func (f *fakeTMDB) GetTvCredits(id int, options map[string]string) (*tmdb.TvCredits, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	return f.tvCredits[id], nil
+}
+
+func (f *fakeTMDB) GetPersonImages(id int) (*tmdb.PersonImages, error) {
+	if err := f.call(nil); err != nil {
+		return nil, err
+	}
+	return f.personImages[id], nil
+}
+
+func (f *fakeTMDB) GetMovieNowPlaying(options map[string]string) (*tmdb.MovieDatedResults, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	if page, ok := f.nowPlaying[options["page"]]; ok {
+		return page, nil
+	}
+	return &tmdb.MovieDatedResults{}, nil
+}
+
+func (f *fakeTMDB) GetMovieInfo(id int, options map[string]string) (*tmdb.Movie, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	if movie, ok := f.englishMovies[id]; ok && options["language"] == "en" {
+		return movie, nil
+	}
+	if movie, ok := f.movies[id]; ok {
+		return movie, nil
+	}
+	return nil, errors.New("Code (34): The resource you requested could not be found.")
+}
+
+func (f *fakeTMDB) GetTvInfo(id int, options map[string]string) (*tmdb.TV, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	if tvShow, ok := f.englishTVShows[id]; ok && options["language"] == "en" {
+		return tvShow, nil
+	}
+	if tvShow, ok := f.tvShows[id]; ok {
+		return tvShow, nil
+	}
+	return nil, errors.New("Code (34): The resource you requested could not be found.")
+}
+
+func (f *fakeTMDB) GetTvSeasonInfo(showID, seasonID int, options map[string]string) (*tmdb.TvSeason, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	if season, ok := f.seasons[fmt.Sprintf("%d/%d", showID, seasonID)]; ok {
+		return season, nil
+	}
+	return nil, errors.New("Code (34): The resource you requested could not be found.")
+}
+
+func (f *fakeTMDB) GetTvSeasonCredits(showID, seasonNum int) (*tmdb.TvCredits, error) {
+	if err := f.call(nil); err != nil {
+		return nil, err
+	}
+	if credits, ok := f.seasonCredits[fmt.Sprintf("%d/%d", showID, seasonNum)]; ok {
+		return credits, nil
+	}
+	return nil, errors.New("Code (34): The resource you requested could not be found.")
+}
+
+func (f *fakeTMDB) GetPersonTvCredits(id int, options map[string]string) (*tmdb.PersonTvCredits, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	return f.personTvCredits[id], nil
+}
+
+func (f *fakeTMDB) GetMovieGenres(options map[string]string) (*tmdb.Genre, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	return f.movieGenres, nil
+}
+
+func (f *fakeTMDB) GetTvGenres(options map[string]string) (*tmdb.Genre, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	return f.tvGenres, nil
+}
+
+func (f *fakeTMDB) GetCollectionInfo(id int, options map[string]string) (*tmdb.Collection, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	return f.collections[id], nil
+}
+
+func (f *fakeTMDB) GetCompanyInfo(id int, options map[string]string) (*tmdb.Company, error) {
+	if err := f.call(options); err != nil {
+		return nil, err
+	}
+	return f.companies[id], nil
+}
+
+// newTestClient returns a client with an in-memory cache calling the given fake instead of TMDB.
+func newTestClient(fake *fakeTMDB, opts ...Option) *mediaClient {
+	client := NewMediaClient("key", opts...).(*mediaClient)
+	client.tmdbClient = fake
+	return client
+}
+
+// newTestAPI points getJSON at a stub server running handler until the end of the test.
+func newTestAPI(t *testing.T, handler http.HandlerFunc) {
+	server := httptest.NewServer(handler)
+	baseURL := apiBaseURL
+	apiBaseURL = server.URL
+	t.Cleanup(func() {
+		apiBaseURL = baseURL
+		server.Close()
+	})
+}
+
+func TestFindByIMDbID(t *testing.T) {
+	var find map[string]*tmdb.FindResults
+	err := json.Unmarshal([]byte(`{
+		"imdb_id:tt1375666": {"movie_results": [{"id": 27205, "title": "Inception", "release_date": "2010-07-15", "poster_path": "/inception.jpg"}]},
+		"imdb_id:tt0903747": {"tv_results": [{"id": 1396, "name": "Breaking Bad", "first_air_date": "2008-01-20"}]},
+		"imdb_id:tt0959621": {"tv_episode_results": [{"id": 62085, "name": "Pilot", "show_id": 1396, "season_number": 1, "episode_number": 1, "air_date": "2008-01-20"}]}
+	}`), &find)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTMDB{find: find}
+	client := newTestClient(fake)
+
+	result, err := client.FindByIMDbID("tt1375666")
+	if err != nil {
+		t.Fatalf("FindByIMDbID(movie) = %v", err)
+	}
+	if result.Movie == nil || result.Movie.ID != 27205 || result.Movie.Title != "Inception" || result.TVShow != nil || result.Episode != nil {
+		t.Errorf("FindByIMDbID(movie) = %+v, want only Inception", result)
+	}
+	if result.Movie.PosterURL != imageBaseURL+"/inception.jpg" {
+		t.Errorf("poster = %q, want it mapped by the movie extractor", result.Movie.PosterURL)
+	}
+
+	result, err = client.FindByIMDbID("tt0903747")
+	if err != nil {
+		t.Fatalf("FindByIMDbID(show) = %v", err)
+	}
+	if result.TVShow == nil || result.TVShow.Title != "Breaking Bad" || result.Movie != nil {
+		t.Errorf("FindByIMDbID(show) = %+v, want only Breaking Bad", result)
+	}
+
+	result, err = client.FindByIMDbID("tt0959621")
+	if err != nil {
+		t.Fatalf("FindByIMDbID(episode) = %v", err)
+	}
+	if result.Episode == nil || result.Episode.TVShowID != 1396 || result.Episode.SeasonNumber != 1 || result.Episode.EpisodeNumber != 1 {
+		t.Errorf("FindByIMDbID(episode) = %+v, want the pilot of Breaking Bad", result.Episode)
+	}
+
+	if _, err := client.FindByIMDbID("tt0000000"); err == nil {
+		t.Error("FindByIMDbID(unknown) = nil error")
+	}
+}
+
+func TestFindByIMDbIDCached(t *testing.T) {
+	fake := &fakeTMDB{find: map[string]*tmdb.FindResults{
+		"imdb_id:tt1375666": {MovieResults: []tmdb.MovieShort{{ID: 27205, Title: "Inception"}}},
+	}}
+	client := newTestClient(fake)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.FindByIMDbID("tt1375666"); err != nil {
+			t.Fatalf("FindByIMDbID() = %v", err)
+		}
+	}
+	if calls := fake.callCount(); calls != 1 {
+		t.Errorf("TMDB called %d times for 3 lookups, want 1", calls)
+	}
+}
+
+func TestGetMovieFull(t *testing.T) {
+	var requests []*http.Request
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r)
+		w.Write([]byte(`{
+			"id": 27205, "title": "Inception", "release_date": "2010-07-15",
+			"credits": {"cast": [{"id": 6193, "name": "Leonardo DiCaprio", "character": "Cobb"}], "crew": [{"id": 525, "name": "Christopher Nolan", "job": "Director"}]},
+			"videos": {"results": [{"key": "YoHD9XEInc0", "name": "Bande-annonce", "site": "YouTube", "type": "Trailer"}]},
+			"images": {"backdrops": [{"file_path": "/backdrop.jpg"}], "posters": [{"file_path": "/poster-fr.jpg"}, {"file_path": "/poster-en.jpg"}]},
+			"release_dates": {"results": [
+				{"iso_3166_1": "US", "release_dates": [{"certification": "PG-13", "release_date": "2010-07-16T00:00:00.000Z", "type": 3}]},
+				{"iso_3166_1": "FR", "release_dates": [
+					{"certification": "", "release_date": "2010-07-08T00:00:00.000Z", "type": 1, "note": "Paris"},
+					{"certification": "TP", "release_date": "2010-07-21T00:00:00.000Z", "type": 3}
+				]}
+			]}
+		}`))
+	})
+	client := newTestClient(&fakeTMDB{})
+
+	movie, err := client.GetMovieFull(27205)
+	if err != nil {
+		t.Fatalf("GetMovieFull() = %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("GetMovieFull() sent %d requests, want 1", len(requests))
+	}
+	if path := requests[0].URL.Path; path != "/movie/27205" {
+		t.Errorf("path = %q, want /movie/27205", path)
+	}
+	for param, want := range map[string]string{
+		"api_key":                "key",
+		"language":               "fr",
+		"append_to_response":     "credits,videos,images,release_dates",
+		"include_image_language": "fr,en,null",
+	} {
+		if got := requests[0].URL.Query().Get(param); got != want {
+			t.Errorf("%s = %q, want %q", param, got, want)
+		}
+	}
+
+	if movie.Title != "Inception" || len(movie.Actors) != 1 || len(movie.Crew) != 1 {
+		t.Errorf("GetMovieFull() = %+v, want Inception with its credits", movie)
+	}
+	if len(movie.Videos) != 1 || movie.Videos[0].Key != "YoHD9XEInc0" {
+		t.Errorf("videos = %+v, want the trailer", movie.Videos)
+	}
+	if len(movie.BackdropURLs) != 1 || len(movie.PosterURLs) != 2 || movie.PosterURLs[0] != imageBaseURL+"/poster-fr.jpg" {
+		t.Errorf("images = %v %v, want 1 backdrop and 2 posters", movie.BackdropURLs, movie.PosterURLs)
+	}
+	if movie.Certification != "TP" {
+		t.Errorf("certification = %q, want the FR one", movie.Certification)
+	}
+	wantReleases := []ReleaseDate{
+		{Date: "2010-07-08T00:00:00.000Z", Note: "Paris", Type: 1},
+		{Certification: "TP", Date: "2010-07-21T00:00:00.000Z", Type: 3},
+	}
+	if len(movie.ReleaseDates) != len(wantReleases) {
+		t.Fatalf("release dates = %+v, want %+v", movie.ReleaseDates, wantReleases)
+	}
+	for i, release := range movie.ReleaseDates {
+		if release != wantReleases[i] {
+			t.Errorf("release date %d = %+v, want %+v", i, release, wantReleases[i])
+		}
+	}
+
+	if _, err := client.GetMovieFull(27205); err != nil {
+		t.Fatalf("GetMovieFull() = %v", err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("cached GetMovieFull() sent %d requests, want 1", len(requests))
+	}
+}
+
+func TestGetMovieFullError(t *testing.T) {
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status_code": 34, "status_message": "The resource you requested could not be found."}`))
+	})
+	client := newTestClient(&fakeTMDB{})
+
+	if _, err := client.GetMovieFull(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetMovieFull() = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetMovieCredits(t *testing.T) {
+	var movieCredits tmdb.MovieCredits
+	err := json.Unmarshal([]byte(`{
+		"cast": [{"id": 6193, "name": "Leonardo DiCaprio", "character": "Cobb", "profile_path": "/leo.jpg"}, {"id": 24045, "name": "Joseph Gordon-Levitt", "character": "Arthur"}],
+		"crew": [{"id": 525, "name": "Christopher Nolan", "job": "Director"}]
+	}`), &movieCredits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTMDB{movieCredits: map[int]*tmdb.MovieCredits{27205: &movieCredits}}
+	client := newTestClient(fake)
+
+	for i := 0; i < 2; i++ {
+		cast, crew, err := client.GetMovieCredits(27205)
+		if err != nil {
+			t.Fatalf("GetMovieCredits() = %v", err)
+		}
+		if len(cast) != 2 || cast[0].Name != "Leonardo DiCaprio" || cast[0].Character != "Cobb" || cast[0].ProfileURL != imageBaseURL+"/leo.jpg" {
+			t.Errorf("cast = %+v, want DiCaprio then Gordon-Levitt", cast)
+		}
+		if len(crew) != 1 || crew[0].Name != "Christopher Nolan" || crew[0].Character != "Director" {
+			t.Errorf("crew = %+v, want Nolan as director", crew)
+		}
+	}
+	if calls := fake.callCount(); calls != 1 {
+		t.Errorf("TMDB called %d times for 2 lookups, want 1", calls)
+	}
+}
+
+func TestGetTVShowCredits(t *testing.T) {
+	var tvCredits tmdb.TvCredits
+	err := json.Unmarshal([]byte(`{
+		"cast": [{"id": 17419, "name": "Bryan Cranston", "character": "Walter White"}],
+		"crew": [{"id": 66633, "name": "Vince Gilligan", "job": "Executive Producer"}]
+	}`), &tvCredits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTMDB{tvCredits: map[int]*tmdb.TvCredits{1396: &tvCredits}}
+	client := newTestClient(fake)
+
+	cast, crew, err := client.GetTVShowCredits(1396)
+	if err != nil {
+		t.Fatalf("GetTVShowCredits() = %v", err)
+	}
+	if len(cast) != 1 || cast[0].Character != "Walter White" || len(crew) != 1 || crew[0].Name != "Vince Gilligan" {
+		t.Errorf("GetTVShowCredits() = %+v, %+v, want Cranston and Gilligan", cast, crew)
+	}
+	if _, _, err := client.GetTVShowCredits(1396); err != nil {
+		t.Fatalf("GetTVShowCredits() = %v", err)
+	}
+	if calls := fake.callCount(); calls != 1 {
+		t.Errorf("TMDB called %d times for 2 lookups, want 1", calls)
+	}
+}
+
+func TestGetTVSeasonCredits(t *testing.T) {
+	var seasonCredits tmdb.TvCredits
+	err := json.Unmarshal([]byte(`{
+		"cast": [{"id": 1, "name": "Matthew McConaughey", "character": "Rust Cohle"}],
+		"crew": [{"id": 2, "name": "Cary Joji Fukunaga", "job": "Director"}]
+	}`), &seasonCredits)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTMDB{seasonCredits: map[string]*tmdb.TvCredits{"46648/1": &seasonCredits}}
+	client := newTestClient(fake)
+
+	for i := 0; i < 2; i++ {
+		cast, crew, err := client.GetTVSeasonCredits(46648, 1)
+		if err != nil {
+			t.Fatalf("GetTVSeasonCredits() = %v", err)
+		}
+		if len(cast) != 1 || cast[0].Character != "Rust Cohle" || len(crew) != 1 || crew[0].Name != "Cary Joji Fukunaga" {
+			t.Errorf("GetTVSeasonCredits() = %+v, %+v, want McConaughey and Fukunaga", cast, crew)
+		}
+	}
+	if calls := fake.callCount(); calls != 1 {
+		t.Errorf("TMDB called %d times for 2 lookups, want 1", calls)
+	}
+	if _, _, err := client.GetTVSeasonCredits(46648, 2); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTVSeasonCredits(unknown season) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestExtractActorsKnownFor(t *testing.T) {
+	var response tmdb.PersonSearchResults
+	err := json.Unmarshal([]byte(`{"results": [
+		{"id": 6193, "name": "Leonardo DiCaprio", "known_for": [
+			{"id": 27205, "media_type": "movie", "title": "Inception", "original_title": "Inception"},
+			{"id": 11324, "media_type": "movie", "original_title": "Shutter Island"},
+			{"id": 1, "media_type": "tv"}
+		]},
+		{"id": 1, "name": "Inconnu"}
+	]}`), &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actors := extractActors(response.Results)
+	if want := []string{"Inception", "Shutter Island"}; !reflect.DeepEqual(actors[0].KnownFor, want) {
+		t.Errorf("KnownFor = %q, want %q", actors[0].KnownFor, want)
+	}
+	if actors[1].KnownFor != nil {
+		t.Errorf("KnownFor = %q, want nil", actors[1].KnownFor)
+	}
+}
+
+func TestSearchActorsOrdering(t *testing.T) {
+	var response tmdb.PersonSearchResults
+	err := json.Unmarshal([]byte(`{"results": [
+		{"id": 1, "name": "Homonyme obscur", "popularity": 0.6},
+		{"id": 2, "name": "Star", "popularity": 84.2},
+		{"id": 3, "name": "Second rôle", "popularity": 12.5},
+		{"id": 4, "name": "Autre homonyme", "popularity": 0.6}
+	]}`), &response)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	actors := extractActors(response.Results)
+	sortActorsByPopularity(actors)
+
+	var ids []int
+	for _, actor := range actors {
+		ids = append(ids, actor.ID)
+	}
+	if want := []int{2, 3, 1, 4}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("actors ordered %v, want %v", ids, want)
+	}
+	if actors[0].Popularity != 84.2 {
+		t.Errorf("popularity = %v, want 84.2", actors[0].Popularity)
+	}
+}
+
+func TestGetPersonImages(t *testing.T) {
+	var images tmdb.PersonImages
+	err := json.Unmarshal([]byte(`{"id": 6193, "profiles": [{"file_path": "/leo-1.jpg"}, {"file_path": ""}, {"file_path": "/leo-2.jpg"}]}`), &images)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTMDB{personImages: map[int]*tmdb.PersonImages{6193: &images}}
+	client := newTestClient(fake)
+
+	for i := 0; i < 2; i++ {
+		urls, err := client.GetPersonImages(6193)
+		if err != nil {
+			t.Fatalf("GetPersonImages() = %v", err)
+		}
+		if want := []string{imageBaseURL + "/leo-1.jpg", imageBaseURL + "/leo-2.jpg"}; !reflect.DeepEqual(urls, want) {
+			t.Errorf("GetPersonImages() = %q, want %q", urls, want)
+		}
+	}
+	if calls := fake.callCount(); calls != 1 {
+		t.Errorf("TMDB called %d times for 2 lookups, want 1", calls)
+	}
+}
+
+func TestGetNowPlayingMovies(t *testing.T) {
+	var page2 tmdb.MovieDatedResults
+	err := json.Unmarshal([]byte(`{"page": 2, "total_pages": 3, "total_results": 45, "results": [
+		{"id": 3, "title": "Ancien", "release_date": "2023-01-04", "popularity": 90},
+		{"id": 1, "title": "Nouveau", "release_date": "2023-03-01", "popularity": 10},
+		{"id": 2, "title": "Moyen", "release_date": "2023-02-15", "popularity": 50}
+	]}`), &page2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTMDB{nowPlaying: map[string]*tmdb.MovieDatedResults{"2": &page2}}
+	client := newTestClient(fake)
+
+	results, err := client.GetNowPlayingMovies(2)
+	if err != nil {
+		t.Fatalf("GetNowPlayingMovies() = %v", err)
+	}
+	if options := fake.lastOptions(); options["page"] != "2" || options["language"] != "fr" {
+		t.Errorf("options = %v, want page 2 in fr", options)
+	}
+	if want := []int{3, 1, 2}; !reflect.DeepEqual(movieIDs(results.Results), want) {
+		t.Errorf("movies = %v, want the TMDB order %v", movieIDs(results.Results), want)
+	}
+	if results.TotalPage != 3 || results.TotalResult != 45 {
+		t.Errorf("totals = %d pages, %d results, want 3 and 45", results.TotalPage, results.TotalResult)
+	}
+
+	if _, err := client.GetNowPlayingMovies(2); err != nil {
+		t.Fatalf("GetNowPlayingMovies() = %v", err)
+	}
+	if calls := fake.callCount(); calls != 1 {
+		t.Errorf("TMDB called %d times for 2 lookups of the same page, want 1", calls)
+	}
+}
+
+// testSeason returns a season of the given TV show with episodes airing on the given dates.
+func testSeason(t *testing.T, seasonNumber int, airDates ...string) *tmdb.TvSeason {
+	var season tmdb.TvSeason
+	season.SeasonNumber = seasonNumber
+	for i, airDate := range airDates {
+		var episode tmdb.TvEpisode
+		err := json.Unmarshal([]byte(fmt.Sprintf(`{"id": %d, "season_number": %d, "episode_number": %d, "air_date": %q}`,
+			seasonNumber*100+i+1, seasonNumber, i+1, airDate)), &episode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		season.Episodes = append(season.Episodes, episode)
+	}
+	return &season
+}
+
+func TestGetShowEpisodesInRange(t *testing.T) {
+	fake := &fakeTMDB{
+		tvShows: map[int]*tmdb.TV{1396: {ID: 1396, Name: "Breaking Bad", NumberOfSeasons: 3}},
+		seasons: map[string]*tmdb.TvSeason{
+			"1396/0": testSeason(t, 0, "2023-01-09", "2023-03-01"),
+			"1396/1": testSeason(t, 1, "2023-01-01", "2023-01-08", "2023-01-15"),
+			"1396/2": testSeason(t, 2, "2023-01-10", "", "2023-02-01"),
+			// season 3 cannot be retrieved and is skipped
+		},
+	}
+	client := newTestClient(fake)
+	start := time.Date(2023, 1, 8, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 1, 15, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		includeSpecials bool
+		want            []string
+	}{
+		{name: "without specials", want: []string{"S1E2", "S1E3", "S2E1"}},
+		{name: "with specials", includeSpecials: true, want: []string{"S0E1", "S1E2", "S1E3", "S2E1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			episodes, err := client.GetShowEpisodesInRange(1396, start, end, tt.includeSpecials, nil)
+			if err != nil {
+				t.Fatalf("GetShowEpisodesInRange() = %v", err)
+			}
+			var got []string
+			for _, episode := range episodes {
+				got = append(got, fmt.Sprintf("S%dE%d", episode.SeasonNumber, episode.EpisodeNumber))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("episodes = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := client.GetShowEpisodesInRange(1, start, end, false, nil); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetShowEpisodesInRange(unknown) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestExtractStudiosOriginCountry(t *testing.T) {
+	var movie tmdb.Movie
+	err := json.Unmarshal([]byte(`{"id": 27205, "production_companies": [
+		{"id": 923, "name": "Legendary Pictures", "logo_path": "/legendary.png", "origin_country": "US"},
+		{"id": 9996, "name": "Syncopy", "origin_country": "GB"}
+	]}`), &movie)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	studios := extractMovie(&movie, nil).Studios
+	if len(studios) != 2 {
+		t.Fatalf("studios = %+v, want 2", studios)
+	}
+	if studios[0].OriginCountry != "US" || studios[0].LogoURL != imageBaseURL+"/legendary.png" || studios[1].OriginCountry != "GB" {
+		t.Errorf("studios = %+v, want Legendary Pictures from US and Syncopy from GB", studios)
+	}
+}
+
+func TestEpisodeBackdropFallback(t *testing.T) {
+	season := testSeason(t, 1, "2008-01-20", "2008-01-27")
+	season.Episodes[0].StillPath = "/pilot.jpg"
+	tests := []struct {
+		name string
+		opts []Option
+		want string
+	}{
+		{name: "default", want: emptyBackdropURL},
+		{name: "fallback", opts: []Option{WithEpisodeBackdropFallback()}, want: imageBaseURL + "/breaking-bad.jpg"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTMDB{
+				tvShows: map[int]*tmdb.TV{1396: {ID: 1396, BackdropPath: "/breaking-bad.jpg", NumberOfSeasons: 1}},
+				seasons: map[string]*tmdb.TvSeason{"1396/1": season},
+			}
+			client := newTestClient(fake, tt.opts...)
+
+			episodes, err := client.GetTVSeasonEpisodes(1396, 1)
+			if err != nil {
+				t.Fatalf("GetTVSeasonEpisodes() = %v", err)
+			}
+			if episodes[0].PosterURL != imageBaseURL+"/pilot.jpg" {
+				t.Errorf("episode 1 picture = %q, want its still", episodes[0].PosterURL)
+			}
+			if episodes[1].PosterURL != tt.want {
+				t.Errorf("episode 2 picture = %q, want %q", episodes[1].PosterURL, tt.want)
+			}
+		})
+	}
+}
+
+func TestPaginatedResultsNextPage(t *testing.T) {
+	tests := []struct {
+		page, totalPage int
+		wantHasNext     bool
+	}{
+		{page: 1, totalPage: 3, wantHasNext: true},
+		{page: 3, totalPage: 3, wantHasNext: false},
+		{page: 1, totalPage: 0, wantHasNext: false},
+	}
+	for _, tt := range tests {
+		movies := &PaginatedMovieResults{Page: tt.page, TotalPage: tt.totalPage}
+		tvShows := &PaginatedTVShowResults{Page: tt.page, TotalPage: tt.totalPage}
+		actors := &PaginatedActorResults{Page: tt.page, TotalPage: tt.totalPage}
+		for _, results := range []interface {
+			HasNext() bool
+			NextPage() int
+		}{movies, tvShows, actors} {
+			if got := results.HasNext(); got != tt.wantHasNext {
+				t.Errorf("%T{Page: %d, TotalPage: %d}.HasNext() = %v, want %v", results, tt.page, tt.totalPage, got, tt.wantHasNext)
+			}
+			if got := results.NextPage(); got != tt.page+1 {
+				t.Errorf("%T{Page: %d}.NextPage() = %d, want %d", results, tt.page, got, tt.page+1)
+			}
+		}
+	}
+}
+
+// newActorTVCreditsFake returns a fake TMDB where the person 17419 played in count TV shows.
+func newActorTVCreditsFake(t *testing.T, count int) *fakeTMDB {
+	var credits tmdb.PersonTvCredits
+	var cast []string
+	fake := &fakeTMDB{tvShows: map[int]*tmdb.TV{}}
+	for id := 1; id <= count; id++ {
+		fake.tvShows[id] = &tmdb.TV{ID: id}
+		cast = append(cast, fmt.Sprintf(`{"id": %d}`, id))
+	}
+	if err := json.Unmarshal([]byte(`{"cast": [`+strings.Join(cast, ",")+`]}`), &credits); err != nil {
+		t.Fatal(err)
+	}
+	fake.personTvCredits = map[int]*tmdb.PersonTvCredits{17419: &credits}
+	return fake
+}
+
+func TestGetTVShowsByActorPages(t *testing.T) {
+	fake := newActorTVCreditsFake(t, 21)
+	client := newTestClient(fake)
+
+	var pages []int
+	var ids []int
+	for page := 1; ; {
+		results, err := client.GetTVShowsByActor(17419, page)
+		if err != nil {
+			t.Fatalf("GetTVShowsByActor(page %d) = %v", page, err)
+		}
+		if results.TotalPage != 2 || results.TotalResult != 21 {
+			t.Errorf("page %d totals = %d pages, %d results, want 2 and 21", page, results.TotalPage, results.TotalResult)
+		}
+		pages = append(pages, results.Page)
+		for _, tvShow := range results.Results {
+			ids = append(ids, tvShow.ID)
+		}
+		if !results.HasNext() {
+			break
+		}
+		page = results.NextPage()
+	}
+	if want := []int{1, 2}; !reflect.DeepEqual(pages, want) {
+		t.Errorf("pages = %v, want %v", pages, want)
+	}
+	if len(ids) != 21 || ids[20] != 21 {
+		t.Errorf("TV shows = %v, want the 21 credits", ids)
+	}
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []Option
+		want int
+	}{
+		{name: "default", want: defaultConcurrencyLimit},
+		{name: "limited", opts: []Option{WithConcurrencyLimit(3)}, want: 3},
+		{name: "invalid limit ignored", opts: []Option{WithConcurrencyLimit(0)}, want: defaultConcurrencyLimit},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newActorTVCreditsFake(t, 20)
+			fake.delay = 20 * time.Millisecond
+			client := newTestClient(fake, tt.opts...)
+
+			results, err := client.GetTVShowsByActor(17419, 1)
+			if err != nil {
+				t.Fatalf("GetTVShowsByActor() = %v", err)
+			}
+			if len(results.Results) != 20 {
+				t.Errorf("%d TV shows, want 20", len(results.Results))
+			}
+			if got := fake.maxConcurrentCalls(); got > tt.want {
+				t.Errorf("%d concurrent TMDB calls, want at most %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMovieExists(t *testing.T) {
+	fake := &fakeTMDB{movies: map[int]*tmdb.Movie{27205: {ID: 27205, Title: "Inception"}}}
+	client := newTestClient(fake)
+
+	for i := 0; i < 2; i++ {
+		if exists, err := client.MovieExists(27205); err != nil || !exists {
+			t.Errorf("MovieExists(27205) = %v, %v, want true", exists, err)
+		}
+		if exists, err := client.MovieExists(1); err != nil || exists {
+			t.Errorf("MovieExists(1) = %v, %v, want false", exists, err)
+		}
+	}
+	if calls := fake.callCount(); calls != 2 {
+		t.Errorf("TMDB called %d times for 4 lookups of 2 movies, want 2", calls)
+	}
+
+	// Other errors are neither reported as a missing movie nor cached
+	fake.err = errors.New("Code (11): Internal error: Something went wrong, contact TMDb.")
+	if exists, err := client.MovieExists(2); !errors.Is(err, ErrServer) || exists {
+		t.Errorf("MovieExists() on a server error = %v, %v, want ErrServer", exists, err)
+	}
+	fake.err = nil
+	fake.movies[2] = &tmdb.Movie{ID: 2}
+	if exists, err := client.MovieExists(2); err != nil || !exists {
+		t.Errorf("MovieExists() after a server error = %v, %v, want true", exists, err)
+	}
+}
+
+func TestTVShowExists(t *testing.T) {
+	fake := &fakeTMDB{tvShows: map[int]*tmdb.TV{1396: {ID: 1396}}}
+	client := newTestClient(fake)
+
+	for i := 0; i < 2; i++ {
+		if exists, err := client.TVShowExists(1396); err != nil || !exists {
+			t.Errorf("TVShowExists(1396) = %v, %v, want true", exists, err)
+		}
+		if exists, err := client.TVShowExists(1); err != nil || exists {
+			t.Errorf("TVShowExists(1) = %v, %v, want false", exists, err)
+		}
+	}
+	if calls := fake.callCount(); calls != 2 {
+		t.Errorf("TMDB called %d times for 4 lookups of 2 TV shows, want 2", calls)
+	}
+}
+
+func TestEnglishFallback(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []Option
+		frOverview    string
+		wantOverview  string
+		wantLanguage  string
+		wantTMDBCalls int
+	}{
+		{name: "disabled", frOverview: "", wantOverview: "", wantLanguage: "", wantTMDBCalls: 2},
+		{name: "empty French overview", opts: []Option{WithEnglishFallback()}, frOverview: "", wantOverview: "A thief who steals corporate secrets.", wantLanguage: "en", wantTMDBCalls: 4},
+		{name: "French overview", opts: []Option{WithEnglishFallback()}, frOverview: "Un voleur qui s'approprie des secrets.", wantOverview: "Un voleur qui s'approprie des secrets.", wantLanguage: "fr", wantTMDBCalls: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTMDB{
+				movies:         map[int]*tmdb.Movie{27205: {ID: 27205, Title: "Inception", Overview: tt.frOverview}},
+				englishMovies:  map[int]*tmdb.Movie{27205: {ID: 27205, Title: "Inception", Overview: "A thief who steals corporate secrets."}},
+				tvShows:        map[int]*tmdb.TV{1396: {ID: 1396, Name: "Breaking Bad", Overview: tt.frOverview}},
+				englishTVShows: map[int]*tmdb.TV{1396: {ID: 1396, Name: "Breaking Bad", Overview: "A thief who steals corporate secrets."}},
+			}
+			client := newTestClient(fake, tt.opts...)
+
+			movie, err := client.GetMovieShort(27205)
+			if err != nil {
+				t.Fatalf("GetMovieShort() = %v", err)
+			}
+			if movie.Overview != tt.wantOverview || movie.OverviewLanguage != tt.wantLanguage {
+				t.Errorf("movie overview = %q (%q), want %q (%q)", movie.Overview, movie.OverviewLanguage, tt.wantOverview, tt.wantLanguage)
+			}
+			tvShow, err := client.GetTVShowShort(1396)
+			if err != nil {
+				t.Fatalf("GetTVShowShort() = %v", err)
+			}
+			if tvShow.Overview != tt.wantOverview || tvShow.OverviewLanguage != tt.wantLanguage {
+				t.Errorf("TV show overview = %q (%q), want %q (%q)", tvShow.Overview, tvShow.OverviewLanguage, tt.wantOverview, tt.wantLanguage)
+			}
+			if calls := fake.callCount(); calls != tt.wantTMDBCalls {
+				t.Errorf("TMDB called %d times, want %d", calls, tt.wantTMDBCalls)
+			}
+		})
+	}
+}
+
+func TestEnglishFallbackEmptyTitle(t *testing.T) {
+	fake := &fakeTMDB{
+		movies:        map[int]*tmdb.Movie{1: {ID: 1, Overview: "Un film sans titre français."}},
+		englishMovies: map[int]*tmdb.Movie{1: {ID: 1, Title: "Untitled", Overview: "A movie."}},
+	}
+	client := newTestClient(fake, WithEnglishFallback())
+
+	movie, err := client.GetMovieShort(1)
+	if err != nil {
+		t.Fatalf("GetMovieShort() = %v", err)
+	}
+	if movie.Title != "Untitled" || movie.Overview != "Un film sans titre français." || movie.OverviewLanguage != "fr" {
+		t.Errorf("movie = %q: %q (%q), want the English title and the French overview", movie.Title, movie.Overview, movie.OverviewLanguage)
+	}
+}
+
+func TestGetTVAllSeasons(t *testing.T) {
+	fake := &fakeTMDB{
+		tvShows: map[int]*tmdb.TV{1396: {ID: 1396, NumberOfSeasons: 3}},
+		seasons: map[string]*tmdb.TvSeason{
+			"1396/1": testSeason(t, 1, "2008-01-20", "2008-01-27"),
+			"1396/3": testSeason(t, 3, "2010-03-21"),
+		},
+	}
+	client := newTestClient(fake)
+
+	seasons, err := client.GetTVAllSeasons(1396)
+	if !errors.Is(err, ErrNotFound) || !strings.Contains(err.Error(), "season 2") {
+		t.Errorf("GetTVAllSeasons() error = %v, want season 2 not found", err)
+	}
+	if len(seasons) != 2 || len(seasons[1]) != 2 || len(seasons[3]) != 1 {
+		t.Errorf("GetTVAllSeasons() = %v, want seasons 1 and 3 with their episodes", seasons)
+	}
+	if _, ok := seasons[2]; ok {
+		t.Error("season 2 is in the map despite its error")
+	}
+
+	fake.seasons["1396/2"] = testSeason(t, 2, "2009-03-08")
+	seasons, err = client.GetTVAllSeasons(1396)
+	if err != nil || len(seasons) != 3 {
+		t.Errorf("GetTVAllSeasons() = %v, %v, want 3 seasons", seasons, err)
+	}
+
+	if _, err := client.GetTVAllSeasons(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetTVAllSeasons(unknown) = %v, want ErrNotFound", err)
+	}
+}
+
+func TestGetRecentMoviesWindow(t *testing.T) {
+	fake := &fakeTMDB{nowPlaying: map[string]*tmdb.MovieDatedResults{}}
+	for page, results := range map[string]string{
+		"1": `{"results": [{"id": 1, "popularity": 10, "release_date": "2023-03-01"}, {"id": 2, "popularity": 80, "release_date": "2023-02-01"}]}`,
+		"2": `{"results": [{"id": 3, "popularity": 50, "release_date": "2023-03-15"}, {"id": 4, "popularity": 5, "release_date": "2023-03-20"}]}`,
+		"3": `{"results": [{"id": 5, "popularity": 99, "release_date": "2023-01-01"}]}`,
+	} {
+		var dated tmdb.MovieDatedResults
+		if err := json.Unmarshal([]byte(results), &dated); err != nil {
+			t.Fatal(err)
+		}
+		fake.nowPlaying[page] = &dated
+	}
+	client := newTestClient(fake)
+
+	tests := []struct {
+		name        string
+		pagesToScan int
+		limit       int
+		want        []int
+	}{
+		{name: "two most popular of two pages", pagesToScan: 2, limit: 2, want: []int{3, 2}},
+		{name: "limit above the results", pagesToScan: 3, limit: 20, want: []int{4, 3, 1, 2, 5}},
+		{name: "negative limit", pagesToScan: 1, limit: -1, want: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			movies, err := client.GetRecentMoviesWindow(tt.pagesToScan, tt.limit)
+			if err != nil {
+				t.Fatalf("GetRecentMoviesWindow() = %v", err)
+			}
+			if got := movieIDs(movies); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GetRecentMoviesWindow(%d, %d) = %v, want %v", tt.pagesToScan, tt.limit, got, tt.want)
+			}
+		})
+	}
+}
+
+// testGenres returns the genre list of the given JSON genres.
+func testGenres(t *testing.T, genres string) *tmdb.Genre {
+	var list tmdb.Genre
+	if err := json.Unmarshal([]byte(`{"genres": `+genres+`}`), &list); err != nil {
+		t.Fatal(err)
+	}
+	return &list
+}
+
+func TestGenreEnrichment(t *testing.T) {
+	var page tmdb.MovieDatedResults
+	err := json.Unmarshal([]byte(`{"results": [
+		{"id": 27205, "genre_ids": [28, 878]},
+		{"id": 157336, "genre_ids": [12, 9999]}
+	]}`), &page)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name string
+		opts []Option
+		want [][]Genre
+	}{
+		{name: "disabled", want: [][]Genre{nil, nil}},
+		{name: "enabled", opts: []Option{WithGenreEnrichment()}, want: [][]Genre{
+			{{ID: 28, Name: "Action"}, {ID: 878, Name: "Science-Fiction"}},
+			{{ID: 12, Name: "Aventure"}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeTMDB{
+				nowPlaying:  map[string]*tmdb.MovieDatedResults{"1": &page, "2": &page},
+				movieGenres: testGenres(t, `[{"id": 28, "name": "Action"}, {"id": 12, "name": "Aventure"}, {"id": 878, "name": "Science-Fiction"}]`),
+			}
+			client := newTestClient(fake, tt.opts...)
+
+			for _, pageNumber := range []int{1, 2} {
+				results, err := client.GetNowPlayingMovies(pageNumber)
+				if err != nil {
+					t.Fatalf("GetNowPlayingMovies() = %v", err)
+				}
+				for i, movie := range results.Results {
+					if !reflect.DeepEqual(movie.Genres, tt.want[i]) {
+						t.Errorf("movie %d genres = %v, want %v", movie.ID, movie.Genres, tt.want[i])
+					}
+				}
+			}
+			// The genre list is only retrieved once
+			wantCalls := 2
+			if tt.opts != nil {
+				wantCalls = 3
+			}
+			if calls := fake.callCount(); calls != wantCalls {
+				t.Errorf("TMDB called %d times, want %d", calls, wantCalls)
+			}
+		})
+	}
+}
+
+func TestGetCollectionOrderedMovies(t *testing.T) {
+	var collection tmdb.Collection
+	err := json.Unmarshal([]byte(`{"id": 10, "parts": [
+		{"id": 3, "title": "Return of the Jedi", "release_date": "1983-05-25"},
+		{"id": 4, "title": "Untitled", "release_date": ""},
+		{"id": 1, "title": "A New Hope", "release_date": "1977-05-25"},
+		{"id": 5, "title": "Unknown", "release_date": "TBA"},
+		{"id": 2, "title": "The Empire Strikes Back", "release_date": "1980-05-20"}
+	]}`), &collection)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTMDB{collections: map[int]*tmdb.Collection{10: &collection}}
+	client := newTestClient(fake)
+
+	for i := 0; i < 2; i++ {
+		movies, err := client.GetCollectionOrderedMovies(10)
+		if err != nil {
+			t.Fatalf("GetCollectionOrderedMovies() = %v", err)
+		}
+		// Movies without a valid release date come last, in their original order
+		if got, want := movieIDs(movies), []int{1, 2, 3, 4, 5}; !reflect.DeepEqual(got, want) {
+			t.Errorf("GetCollectionOrderedMovies() = %v, want %v", got, want)
+		}
+	}
+	if calls := fake.callCount(); calls != 1 {
+		t.Errorf("TMDB called %d times, want 1", calls)
+	}
+}
+
+func TestEpisodeGuestStarsAndCrew(t *testing.T) {
+	var season tmdb.TvSeason
+	err := json.Unmarshal([]byte(`{"season_number": 1, "episodes": [
+		{"id": 62085, "episode_number": 1, "season_number": 1,
+			"guest_stars": [{"id": 92495, "name": "John Koyama", "character": "Emilio Koyama", "profile_path": "/koyama.jpg"}],
+			"crew": [{"id": 66633, "name": "Vince Gilligan", "department": "Writing", "job": "Writer", "profile_path": ""}]},
+		{"id": 62086, "episode_number": 2, "season_number": 1}
+	]}`), &season)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake := &fakeTMDB{
+		tvShows: map[int]*tmdb.TV{1396: {ID: 1396, NumberOfSeasons: 1}},
+		seasons: map[string]*tmdb.TvSeason{"1396/1": &season},
+	}
+	client := newTestClient(fake)
+
+	episodes, err := client.GetTVSeasonEpisodes(1396, 1)
+	if err != nil {
+		t.Fatalf("GetTVSeasonEpisodes() = %v", err)
+	}
+	wantGuestStars := []Person{{ID: 92495, Character: "Emilio Koyama", Name: "John Koyama", ProfileURL: imageBaseURL + "/koyama.jpg"}}
+	if !reflect.DeepEqual(episodes[0].GuestStars, wantGuestStars) {
+		t.Errorf("episode 1 guest stars = %v, want %v", episodes[0].GuestStars, wantGuestStars)
+	}
+	wantCrew := []Person{{ID: 66633, Character: "Writer", Name: "Vince Gilligan", ProfileURL: emptyProfileURL, Department: "Writing"}}
+	if !reflect.DeepEqual(episodes[0].Crew, wantCrew) {
+		t.Errorf("episode 1 crew = %v, want %v", episodes[0].Crew, wantCrew)
+	}
+	if episodes[1].GuestStars != nil || episodes[1].Crew != nil {
+		t.Errorf("episode 2 guest stars and crew = %v, %v, want none", episodes[1].GuestStars, episodes[1].Crew)
+	}
+}
+
+func TestGenreIDByName(t *testing.T) {
+	fake := &fakeTMDB{
+		movieGenres: testGenres(t, `[{"id": 28, "name": "Action"}, {"id": 878, "name": "Science-Fiction"}]`),
+		tvGenres:    testGenres(t, `[{"id": 10759, "name": "Action & Adventure"}]`),
+	}
+	client := newTestClient(fake)
+	tests := []struct {
+		name, mediaType string
+		want            int
+		wantNotFound    bool
+		wantErr         bool
+	}{
+		{name: "Action", mediaType: "movie", want: 28},
+		{name: " science-fiction ", mediaType: "movie", want: 878},
+		{name: "action & adventure", mediaType: "tv", want: 10759},
+		{name: "Action", mediaType: "tv", wantNotFound: true, wantErr: true},
+		{name: "Action", mediaType: "person", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mediaType+"/"+tt.name, func(t *testing.T) {
+			id, err := client.GenreIDByName(tt.name, tt.mediaType)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GenreIDByName() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if errors.Is(err, ErrNotFound) != tt.wantNotFound {
+				t.Errorf("GenreIDByName() error = %v, want ErrNotFound %v", err, tt.wantNotFound)
+			}
+			if id != tt.want {
+				t.Errorf("GenreIDByName() = %d, want %d", id, tt.want)
+			}
+		})
+	}
+	// Each genre list is only retrieved once
+	if calls := fake.callCount(); calls != 2 {
+		t.Errorf("TMDB called %d times, want 2", calls)
+	}
+}
+
+func TestGenreNamesToIDs(t *testing.T) {
+	fake := &fakeTMDB{movieGenres: testGenres(t, `[{"id": 28, "name": "Action"}, {"id": 878, "name": "Science-Fiction"}]`)}
+	client := newTestClient(fake)
+
+	ids, err := client.GenreNamesToIDs([]string{"science-fiction", "action"}, "movie")
+	if err != nil {
+		t.Fatalf("GenreNamesToIDs() = %v", err)
+	}
+	if want := []int{878, 28}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("GenreNamesToIDs() = %v, want %v", ids, want)
+	}
+	if _, err := client.GenreNamesToIDs([]string{"action", "western"}, "movie"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GenreNamesToIDs() error = %v, want ErrNotFound", err)
+	}
+}
+
+func TestConcurrentCallsDoNotShareOptions(t *testing.T) {
+	fake := &fakeTMDB{
+		movies:      map[int]*tmdb.Movie{},
+		tvShows:     map[int]*tmdb.TV{},
+		movieGenres: testGenres(t, `[{"id": 18, "name": "Drame"}]`),
+		tvGenres:    testGenres(t, `[{"id": 35, "name": "Comédie"}]`),
+	}
+	for i := 0; i < 20; i++ {
+		fake.movies[i] = &tmdb.Movie{ID: i, Title: fmt.Sprintf("Film %d", i), ReleaseDate: "2010-07-16"}
+		fake.tvShows[i] = &tmdb.TV{ID: i, Name: fmt.Sprintf("Série %d", i), FirstAirDate: "2008-01-20"}
+	}
+	client := newTestClient(fake)
+	want := extractOptions(client.options)
+	derived := client.WithOptions(map[string]string{"certification_country": "FR"}).(*mediaClient)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 1000)
+	for i := 0; i < 20; i++ {
+		for _, c := range []*mediaClient{client, derived} {
+			wg.Add(1)
+			go func(c *mediaClient, i int) {
+				defer wg.Done()
+				calls := []func() error{
+					func() error { _, err := c.GetMovie(i); return err },
+					func() error { _, err := c.GetMovieShort(i); return err },
+					func() error { _, err := c.GetTVShow(i); return err },
+					func() error { _, err := c.GetTVShowShort(i); return err },
+					func() error { _, err := c.GetMovieGenre(18); return err },
+					func() error { _, err := c.GetTVGenre(35); return err },
+					func() error { _, err := c.GetMovieGenres(); return err },
+					func() error { _, err := c.GetTVShowGenres(); return err },
+				}
+				for _, call := range calls {
+					if err := call(); err != nil {
+						errs <- err
+					}
+				}
+			}(c, i)
+		}
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatalf("concurrent call failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(client.options, want) {
+		t.Errorf("client options = %v after the calls, want them unchanged (%v)", client.options, want)
+	}
+	if _, ok := derived.options["call"]; ok {
+		t.Errorf("derived client options = %v after the calls, want them unchanged", derived.options)
+	}
+}
+
+func TestWithOptions(t *testing.T) {
+	fake := &fakeTMDB{
+		movies:      map[int]*tmdb.Movie{27205: {ID: 27205, Title: "Inception", ReleaseDate: "2010-07-15"}},
+		movieGenres: testGenres(t, `[{"id": 28, "name": "Action"}]`),
+	}
+	client := newTestClient(fake, WithGenreEnrichment())
+	derived := client.WithOptions(map[string]string{"certification_country": "FR", "language": "en"}).(*mediaClient)
+
+	want := map[string]string{"language": "fr", "region": "fr"}
+	if !reflect.DeepEqual(client.options, want) {
+		t.Errorf("client options = %v, want them unchanged (%v)", client.options, want)
+	}
+	want = map[string]string{"language": "en", "region": "fr", "certification_country": "FR"}
+	if !reflect.DeepEqual(derived.options, want) {
+		t.Errorf("derived client options = %v, want %v", derived.options, want)
+	}
+
+	if _, err := derived.GetMovieShort(27205); err != nil {
+		t.Fatalf("GetMovieShort() = %v", err)
+	}
+	if options := fake.lastOptions(); options["certification_country"] != "FR" || options["language"] != "en" {
+		t.Errorf("derived client sent %v, want its options", options)
+	}
+	if _, err := derived.GenreIDByName("Action", "movie"); err != nil {
+		t.Fatalf("GenreIDByName() = %v", err)
+	}
+
+	// The cache and the genre names are shared with the parent client
+	if _, err := client.GetMovieShort(27205); err != nil {
+		t.Fatalf("GetMovieShort() = %v", err)
+	}
+	if _, err := client.GenreIDByName("Action", "movie"); err != nil {
+		t.Fatalf("GenreIDByName() = %v", err)
+	}
+	if calls := fake.callCount(); calls != 2 {
+		t.Errorf("TMDB called %d times, want 2", calls)
+	}
+	if client.sem != derived.sem {
+		t.Error("derived client has its own concurrency limit, want the parent one")
+	}
+}
+
+func TestIsDateInRange(t *testing.T) {
+	paris, err := time.LoadLocation("Europe/Paris")
+	if err != nil {
+		t.Skip(err)
+	}
+	start := time.Date(2023, 3, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2023, 3, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		date    string
+		loc     *time.Location
+		want    bool
+		wantErr bool
+	}{
+		{"inside", "2023-03-15", time.UTC, true, false},
+		{"start bound", "2023-03-01", time.UTC, true, false},
+		{"end bound", "2023-03-31", time.UTC, true, false},
+		{"before", "2023-02-28", time.UTC, false, false},
+		{"after", "2023-04-01", time.UTC, false, false},
+		{"nil location", "2023-03-01", nil, true, false},
+		{"midnight in Paris is before the UTC start", "2023-03-01", paris, false, false},
+		{"empty date", "", time.UTC, false, true},
+		{"malformed date", "15/03/2023", time.UTC, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := isDateInRange(tt.date, tt.loc, start, end)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("isDateInRange(%q) error = %v, want error %v", tt.date, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("isDateInRange(%q) = %v, want %v", tt.date, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetStudioAndNetworkCached(t *testing.T) {
+	var paths []string
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		w.Write([]byte(`{"id": 49, "name": "HBO", "logo_path": "/hbo.png"}`))
+	})
+	fake := &fakeTMDB{companies: map[int]*tmdb.Company{420: {ID: 420, Name: "Marvel Studios", LogoPath: "/marvel.png"}}}
+	client := newTestClient(fake)
+
+	for i := 0; i < 2; i++ {
+		studio, err := client.GetStudio(420)
+		if err != nil {
+			t.Fatalf("GetStudio() = %v", err)
+		}
+		if want := (&Studio{ID: 420, Name: "Marvel Studios", LogoURL: imageBaseURL + "/marvel.png"}); !reflect.DeepEqual(studio, want) {
+			t.Errorf("GetStudio() = %+v, want %+v", studio, want)
+		}
+		network, err := client.GetNetwork(49)
+		if err != nil {
+			t.Fatalf("GetNetwork() = %v", err)
+		}
+		if want := (&Studio{ID: 49, Name: "HBO", LogoURL: imageBaseURL + "/hbo.png"}); !reflect.DeepEqual(network, want) {
+			t.Errorf("GetNetwork() = %+v, want %+v", network, want)
+		}
+	}
+	if calls := fake.callCount(); calls != 1 {
+		t.Errorf("GetCompanyInfo called %d times for 2 lookups, want 1", calls)
+	}
+	if len(paths) != 1 || paths[0] != "/network/49" {
+		t.Errorf("requested %v, want /network/49 once", paths)
+	}
+}
+
+func TestGetNetworkNotFound(t *testing.T) {
+	newTestAPI(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"status_code": 34, "status_message": "The resource you requested could not be found."}`))
+	})
+	client := newTestClient(&fakeTMDB{})
+
+	if _, err := client.GetNetwork(1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetNetwork() = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,40 @@
+package transcoder
+
+import (
+	"sort"
+)
+
+// defaultWideAspectThreshold is the aspect ratio above which the 21:9 scale and intro are used.
+const defaultWideAspectThreshold = 1.8
+
+// aspectBucket is a scale and intro used for videos wider than minRatio.
+type aspectBucket struct {
+	minRatio   float64
+	videoScale string
+	introPath  string
+}
+
+// aspectBuckets returns the 16:9 and 21:9 buckets given to ProcessFileTranscode along with the buckets
+// added with WithAspectBucket, sorted by increasing minimum ratio.
+func (o *transcodeOptions) aspectBuckets(videoScale, introPath, videoScale219, intro219Path string) []aspectBucket {
+	buckets := []aspectBucket{
+		{minRatio: 0, videoScale: videoScale, introPath: introPath},
+		{minRatio: o.wideAspectThreshold, videoScale: videoScale219, introPath: intro219Path},
+	}
+	buckets = append(buckets, o.extraAspectBuckets...)
+	sort.SliceStable(buckets, func(i, j int) bool {
+		return buckets[i].minRatio < buckets[j].minRatio
+	})
+	return buckets
+}
+
+// selectAspectBucket returns the bucket with the highest minimum ratio below the video aspect ratio.
+func selectAspectBucket(buckets []aspectBucket, ratio float64) aspectBucket {
+	selected := buckets[0]
+	for _, bucket := range buckets {
+		if ratio > bucket.minRatio {
+			selected = bucket
+		}
+	}
+	return selected
+}
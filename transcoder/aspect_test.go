@@ -0,0 +1,65 @@
+package transcoder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSelectAspectBucket(t *testing.T) {
+	options := newTranscodeOptions([]TranscodeOption{
+		WithAspectBucket(2.3, "1920:800", "intro_scope.mkv"),
+		WithAspectBucket(1.4, "1440:1080", "intro_4-3.mkv"),
+	})
+	buckets := options.aspectBuckets("1280:720", "intro.mkv", "1920:816", "intro_21-9.mkv")
+	tests := []struct {
+		name  string
+		ratio float64
+		want  string
+	}{
+		{name: "4:3", ratio: 4.0 / 3.0, want: "intro.mkv"},
+		{name: "3:2", ratio: 1.5, want: "intro_4-3.mkv"},
+		{name: "16:9", ratio: 16.0 / 9.0, want: "intro_4-3.mkv"},
+		{name: "threshold", ratio: 1.8, want: "intro_4-3.mkv"},
+		{name: "21:9", ratio: 2.33, want: "intro_scope.mkv"},
+		{name: "1.85:1", ratio: 1.85, want: "intro_21-9.mkv"},
+		{name: "2.39:1", ratio: 2.39, want: "intro_scope.mkv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := selectAspectBucket(buckets, tt.ratio); got.introPath != tt.want {
+				t.Errorf("selectAspectBucket(%v) = %s, want %s", tt.ratio, got.introPath, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeAspectThreshold(t *testing.T) {
+	intro1610 := filepath.Join(t.TempDir(), "intro_16-10.mkv")
+	if err := os.WriteFile(intro1610, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name      string
+		opts      []TranscodeOption
+		wantIntro string
+		wantScale string
+	}{
+		{name: "default", wantIntro: "intro.mkv", wantScale: "scale=1280:720"},
+		{name: "lower threshold", opts: []TranscodeOption{WithAspectThreshold(1.7)}, wantIntro: "intro_21-9.mkv", wantScale: "scale=1920:816"},
+		{name: "extra bucket", opts: []TranscodeOption{WithAspectBucket(1.5, "1600:900", intro1610)}, wantIntro: "intro_16-10.mkv", wantScale: "scale=1600:900"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := newFakeTools(t, sampleMovieProbes()...)
+			if _, _, err := transcodeSample(t, tt.opts...); err != nil {
+				t.Fatalf("ProcessFileTranscode() = %v", err)
+			}
+			call := ft.ffmpegCall(t, "index.m3u8")
+			if !strings.Contains(call, tt.wantIntro+" -i ") || !strings.Contains(call, "[1:v:0]"+tt.wantScale+",") {
+				t.Errorf("video ffmpeg run = %q, want %s with %s", call, tt.wantIntro, tt.wantScale)
+			}
+		})
+	}
+}
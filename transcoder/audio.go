@@ -0,0 +1,139 @@
+package transcoder
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ProcessAudioOnlyTranscode transcodes the audio streams of an audio-only input (soundtracks, commentaries,
+// podcasts) to HLS into outputFolder, one playlist per audio stream. There is no intro and no video
+// pipeline: the VideoIndex of the response is left empty. The output is published atomically like
+// ProcessFileTranscode does.
+func ProcessAudioOnlyTranscode(inputFile, outputFolder, chunkDuration string, opts ...TranscodeOption) (TranscodeResponse, error) {
+	options := newTranscodeOptions(opts)
+	start := time.Now()
+	logger.Infof("Début du transcodage audio du fichier : %s", inputFile)
+
+	if err := CheckBinaries(); err != nil {
+		return TranscodeResponse{}, err
+	}
+	if err := checkEncoders(audioEncoder); err != nil {
+		return TranscodeResponse{}, err
+	}
+
+	audioStreams, _, _, _, err := extractStreamsInfo(inputFile)
+	if err != nil {
+		return TranscodeResponse{}, err
+	}
+	if len(audioStreams) == 0 {
+		return TranscodeResponse{}, fmt.Errorf("%w in %s", ErrNoAudioStream, inputFile)
+	}
+	audioStreams = selectAudioStreams(inputFile, audioStreams, options.audioTracks)
+
+	workFolder, err := prepareOutputFolder(filepath.Dir(outputFolder), filepath.Base(outputFolder), options.tempDir)
+	if err != nil {
+		return TranscodeResponse{}, err
+	}
+	// Once published, the work folder no longer exists: this only removes the output of a failed run.
+	defer os.RemoveAll(workFolder)
+
+	if err := transcodeAudioOnlyStreams(inputFile, workFolder, chunkDuration, audioStreams); err != nil {
+		return TranscodeResponse{}, err
+	}
+
+	response := TranscodeResponse{SchemaVersion: ResponseSchemaVersion}
+	for _, stream := range audioStreams {
+		response.Audios = append(response.Audios, AudioTranscodeResponse{
+			AudioIndex: fmt.Sprintf("audio_%s.m3u8", stream),
+		})
+	}
+	if response.OutputSize, err = folderSize(workFolder); err != nil {
+		logger.Warnf("Impossible de mesurer la taille de la sortie : %v", err)
+	}
+
+	if options.writeSidecar {
+		if err := writeResponseSidecar(workFolder, response); err != nil {
+			return TranscodeResponse{}, err
+		}
+	}
+
+	if err := publishOutputFolder(workFolder, outputFolder); err != nil {
+		return TranscodeResponse{}, err
+	}
+
+	if options.outputPermissions == 0 {
+		logger.Debugf("Permissions du dossier inchangées : %s", outputFolder)
+	} else if err := os.Chmod(outputFolder, options.outputPermissions); err != nil {
+		logger.Warnf("Failed to set folder permissions to %o : %v", options.outputPermissions, err)
+	}
+
+	logger.Infof("Temps de transcodage audio : %s", time.Since(start))
+	return response, nil
+}
+
+// transcodeAudioOnlyStreams transcodes each audio stream to its own HLS playlist, without intro.
+func transcodeAudioOnlyStreams(inputFile, outputFolder, chunkDuration string, audioStreams []string) error {
+	logger.Infof("Transcodage des pistes audio...")
+
+	semaphore := make(chan struct{}, 2) // Limit to 2 concurrent ffmpeg processes
+	wg := sync.WaitGroup{}
+	errs := make([]error, len(audioStreams))
+
+	for i, stream := range audioStreams {
+		wg.Add(1)
+
+		go func(i int, stream string) {
+			defer wg.Done()
+			semaphore <- struct{}{}        // Wait for a free slot
+			defer func() { <-semaphore }() // Free slot
+
+			outputFile := filepath.Join(outputFolder, fmt.Sprintf("audio_%s.m3u8", stream))
+			cmd := exec.Command(ffmpegPath,
+				"-i", inputFile,
+				"-map", "0:"+stream,
+				"-c:a", audioEncoder,
+				"-b:a", "160k",
+				"-ac", "2",
+				"-hls_time", chunkDuration,
+				"-hls_playlist_type", "vod",
+				"-hls_segment_filename", filepath.Join(outputFolder, fmt.Sprintf("audio_%s_%%03d.ts", stream)),
+				outputFile,
+			)
+			logger.Debugf("Commande ffmpeg : %s", cmd.String())
+			if err := cmd.Run(); err != nil {
+				errs[i] = fmt.Errorf("failed to execute command: %w", err)
+				return
+			}
+			logger.Infof("Piste audio extraite : %s", outputFile)
+		}(i, stream)
+	}
+
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// folderSize returns the total size in bytes of the files in a folder.
+func folderSize(folder string) (int64, error) {
+	files, err := os.ReadDir(folder)
+	if err != nil {
+		return 0, err
+	}
+	var size int64
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil {
+			return 0, err
+		}
+		size += info.Size()
+	}
+	return size, nil
+}
@@ -0,0 +1,93 @@
+package transcoder
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// transcodeSampleAudio runs ProcessAudioOnlyTranscode on a fake soundtrack and returns the output folder.
+func transcodeSampleAudio(t *testing.T, opts ...TranscodeOption) (TranscodeResponse, string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	input := filepath.Join(dir, "soundtrack.mka")
+	if err := os.WriteFile(input, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	outputFolder := filepath.Join(dir, "media", "42")
+	response, err := ProcessAudioOnlyTranscode(input, outputFolder, "10", opts...)
+	return response, outputFolder, err
+}
+
+func TestProcessAudioOnlyTranscode(t *testing.T) {
+	ft := newFakeTools(t, fakeProbe{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,flac,audio\n1,ac3,audio"})
+
+	response, folder, err := transcodeSampleAudio(t, WithResponseSidecar())
+	if err != nil {
+		t.Fatalf("ProcessAudioOnlyTranscode() = %v", err)
+	}
+	wantAudios := []AudioTranscodeResponse{{AudioIndex: "audio_0.m3u8"}, {AudioIndex: "audio_1.m3u8"}}
+	if !reflect.DeepEqual(response.Audios, wantAudios) {
+		t.Errorf("Audios = %+v, want %+v", response.Audios, wantAudios)
+	}
+	if response.VideoIndex != "" {
+		t.Errorf("VideoIndex = %q, want none", response.VideoIndex)
+	}
+	if response.OutputSize == 0 {
+		t.Error("OutputSize = 0, want the size of the playlists")
+	}
+	for _, stream := range []string{"0", "1"} {
+		call := ft.ffmpegCall(t, "audio_"+stream+".m3u8")
+		if !strings.Contains(call, "-map 0:"+stream+" ") || strings.Contains(call, "concat") {
+			t.Errorf("ffmpeg run = %q, want stream %s mapped without intro", call, stream)
+		}
+		if _, err := os.Stat(filepath.Join(folder, "audio_"+stream+".m3u8")); err != nil {
+			t.Errorf("playlist not published: %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(folder, ResponseSidecarFilename)); err != nil {
+		t.Errorf("sidecar not published: %v", err)
+	}
+}
+
+func TestProcessAudioOnlyTranscodeTrackSelection(t *testing.T) {
+	newFakeTools(t,
+		fakeProbe{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,flac,audio\n1,ac3,audio"},
+		fakeProbe{entries: "stream=index,codec_name,codec_type:stream_tags=language", output: `{"streams": [
+			{"index": 0, "codec_name": "flac", "codec_type": "audio", "tags": {"language": "eng"}},
+			{"index": 1, "codec_name": "ac3", "codec_type": "audio", "tags": {"language": "fre"}}
+		]}`},
+	)
+
+	response, _, err := transcodeSampleAudio(t, WithAudioTracks("fre"))
+	if err != nil {
+		t.Fatalf("ProcessAudioOnlyTranscode() = %v", err)
+	}
+	if want := []AudioTranscodeResponse{{AudioIndex: "audio_1.m3u8"}}; !reflect.DeepEqual(response.Audios, want) {
+		t.Errorf("Audios = %+v, want %+v", response.Audios, want)
+	}
+}
+
+func TestProcessAudioOnlyTranscodeErrors(t *testing.T) {
+	t.Run("no audio", func(t *testing.T) {
+		newFakeTools(t, fakeProbe{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,h264,video,16:9"})
+		if _, _, err := transcodeSampleAudio(t); !errors.Is(err, ErrNoAudioStream) {
+			t.Errorf("ProcessAudioOnlyTranscode() = %v, want ErrNoAudioStream", err)
+		}
+	})
+	t.Run("failed stream", func(t *testing.T) {
+		ft := newFakeTools(t, fakeProbe{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,flac,audio\n1,ac3,audio"})
+		ft.writeFFmpeg(t, "*audio_1.m3u8")
+		_, folder, err := transcodeSampleAudio(t)
+		if err == nil {
+			t.Fatal("ProcessAudioOnlyTranscode() = nil error, want the ffmpeg failure")
+		}
+		entries, _ := os.ReadDir(filepath.Dir(folder))
+		if len(entries) != 0 {
+			t.Errorf("media folder holds %d entries after the failure, want none", len(entries))
+		}
+	})
+}
@@ -0,0 +1,217 @@
+package transcoder
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+)
+
+const (
+	videoEncoder = "libx264"
+	audioEncoder = "aac"
+)
+
+// ErrUnsupportedEncoder is returned when the configured ffmpeg build lacks a required encoder.
+var ErrUnsupportedEncoder = errors.New("encoder not supported by ffmpeg")
+
+var (
+	ffmpegPath  = "ffmpeg"
+	ffprobePath = "ffprobe"
+)
+
+// SetFFmpegPath sets the ffmpeg executable used by the transcoder.
+// An empty path restores the default "ffmpeg" looked up on PATH.
+// It is not safe to call while a transcode is running: call it once at startup, before any transcode.
+func SetFFmpegPath(path string) {
+	if path == "" {
+		path = "ffmpeg"
+	}
+	ffmpegPath = path
+}
+
+// SetFFprobePath sets the ffprobe executable used by the transcoder.
+// An empty path restores the default "ffprobe" looked up on PATH.
+// It is not safe to call while a transcode is running: call it once at startup, before any transcode.
+func SetFFprobePath(path string) {
+	if path == "" {
+		path = "ffprobe"
+	}
+	ffprobePath = path
+}
+
+// checkedBinaries holds the ffmpeg and ffprobe paths last found by CheckBinaries.
+var checkedBinaries struct {
+	sync.Mutex
+	ffmpegPath  string
+	ffprobePath string
+}
+
+// CheckBinaries verifies that the configured ffmpeg and ffprobe executables exist.
+// The paths are only looked up once: later calls return immediately until they are changed.
+func CheckBinaries() error {
+	checkedBinaries.Lock()
+	defer checkedBinaries.Unlock()
+	if checkedBinaries.ffmpegPath == ffmpegPath && checkedBinaries.ffprobePath == ffprobePath {
+		return nil
+	}
+	if _, err := exec.LookPath(ffmpegPath); err != nil {
+		return fmt.Errorf("ffmpeg executable not found at %q: %w", ffmpegPath, err)
+	}
+	if _, err := exec.LookPath(ffprobePath); err != nil {
+		return fmt.Errorf("ffprobe executable not found at %q: %w", ffprobePath, err)
+	}
+	checkedBinaries.ffmpegPath, checkedBinaries.ffprobePath = ffmpegPath, ffprobePath
+	return nil
+}
+
+// FFmpegCapabilities describes what the configured ffmpeg build supports.
+type FFmpegCapabilities struct {
+	Version       string   `json:"version"`
+	VideoEncoders []string `json:"video_encoders"`
+	AudioEncoders []string `json:"audio_encoders"`
+	HWAccels      []string `json:"hwaccels"`
+}
+
+// HasEncoder reports whether the given video or audio encoder is available.
+func (c *FFmpegCapabilities) HasEncoder(name string) bool {
+	for _, encoder := range c.VideoEncoders {
+		if encoder == name {
+			return true
+		}
+	}
+	for _, encoder := range c.AudioEncoders {
+		if encoder == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasHWAccel reports whether the given hardware acceleration method is available.
+func (c *FFmpegCapabilities) HasHWAccel(name string) bool {
+	for _, hwaccel := range c.HWAccels {
+		if hwaccel == name {
+			return true
+		}
+	}
+	return false
+}
+
+// capabilitiesCache holds the capabilities detected for each ffmpeg executable, so that the transcodes
+// don't run ffmpeg three more times each just to check its encoders.
+var capabilitiesCache = struct {
+	sync.Mutex
+	byPath map[string]*FFmpegCapabilities
+}{byPath: make(map[string]*FFmpegCapabilities)}
+
+// DetectCapabilities runs ffmpeg to list its version, encoders and hardware accelerations.
+// The result is stored as the cached capabilities of the configured ffmpeg executable.
+func DetectCapabilities() (*FFmpegCapabilities, error) {
+	path := ffmpegPath
+	capabilities, err := detectCapabilities(path)
+	if err != nil {
+		return nil, err
+	}
+	capabilitiesCache.Lock()
+	capabilitiesCache.byPath[path] = capabilities
+	capabilitiesCache.Unlock()
+	return capabilities, nil
+}
+
+// RefreshCapabilities drops the cached capabilities of every ffmpeg executable, to be called when a binary
+// is replaced in place (e.g. upgraded). The next transcode detects them again.
+func RefreshCapabilities() {
+	capabilitiesCache.Lock()
+	capabilitiesCache.byPath = make(map[string]*FFmpegCapabilities)
+	capabilitiesCache.Unlock()
+}
+
+// cachedCapabilities returns the capabilities of the configured ffmpeg executable, detecting them on first use.
+// Detection errors are not cached.
+func cachedCapabilities() (*FFmpegCapabilities, error) {
+	capabilitiesCache.Lock()
+	capabilities, ok := capabilitiesCache.byPath[ffmpegPath]
+	capabilitiesCache.Unlock()
+	if ok {
+		return capabilities, nil
+	}
+	return DetectCapabilities()
+}
+
+func detectCapabilities(ffmpegPath string) (*FFmpegCapabilities, error) {
+	capabilities := &FFmpegCapabilities{}
+
+	output, err := exec.Command(ffmpegPath, "-hide_banner", "-version").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ffmpeg version: %w", err)
+	}
+	if fields := strings.Fields(string(output)); len(fields) >= 3 && fields[0] == "ffmpeg" {
+		capabilities.Version = fields[2]
+	}
+
+	output, err = exec.Command(ffmpegPath, "-hide_banner", "-encoders").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ffmpeg encoders: %w", err)
+	}
+	capabilities.VideoEncoders, capabilities.AudioEncoders = parseEncoders(string(output))
+
+	output, err = exec.Command(ffmpegPath, "-hide_banner", "-hwaccels").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list ffmpeg hardware accelerations: %w", err)
+	}
+	capabilities.HWAccels = parseHWAccels(string(output))
+
+	return capabilities, nil
+}
+
+// parseEncoders parses the output of "ffmpeg -encoders".
+// Each encoder line starts with a flags column (V for video, A for audio) followed by the encoder name.
+func parseEncoders(output string) (videoEncoders, audioEncoders []string) {
+	started := false
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if !started {
+			started = len(fields) == 1 && strings.HasPrefix(fields[0], "---")
+			continue
+		}
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0][0] {
+		case 'V':
+			videoEncoders = append(videoEncoders, fields[1])
+		case 'A':
+			audioEncoders = append(audioEncoders, fields[1])
+		}
+	}
+	return videoEncoders, audioEncoders
+}
+
+// parseHWAccels parses the output of "ffmpeg -hwaccels".
+func parseHWAccels(output string) []string {
+	var hwaccels []string
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasSuffix(line, ":") {
+			continue
+		}
+		hwaccels = append(hwaccels, line)
+	}
+	return hwaccels
+}
+
+// checkEncoders returns an ErrUnsupportedEncoder error for the first encoder not supported by ffmpeg.
+func checkEncoders(encoders ...string) error {
+	capabilities, err := cachedCapabilities()
+	if err != nil {
+		return err
+	}
+	for _, encoder := range encoders {
+		if !capabilities.HasEncoder(encoder) {
+			return fmt.Errorf("%w: %s (ffmpeg %s)", ErrUnsupportedEncoder, encoder, capabilities.Version)
+		}
+	}
+	return nil
+}
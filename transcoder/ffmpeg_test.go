@@ -0,0 +1,143 @@
+package transcoder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeExecutable writes a shell script at path.
+func writeExecutable(t *testing.T, path, script string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckBinaries(t *testing.T) {
+	dir := t.TempDir()
+	ffmpeg, ffprobe := filepath.Join(dir, "ffmpeg"), filepath.Join(dir, "ffprobe")
+	writeExecutable(t, ffprobe, "")
+	SetFFmpegPath(ffmpeg)
+	SetFFprobePath(ffprobe)
+	t.Cleanup(func() {
+		SetFFmpegPath("")
+		SetFFprobePath("")
+	})
+
+	if err := CheckBinaries(); err == nil || !strings.Contains(err.Error(), ffmpeg) {
+		t.Fatalf("CheckBinaries() = %v, want an error naming the missing ffmpeg", err)
+	}
+
+	writeExecutable(t, ffmpeg, "")
+	if err := CheckBinaries(); err != nil {
+		t.Fatalf("CheckBinaries() = %v", err)
+	}
+
+	// The paths found are not looked up again...
+	os.Remove(ffprobe)
+	if err := CheckBinaries(); err != nil {
+		t.Errorf("CheckBinaries() = %v, want the cached result", err)
+	}
+	// ...until they change.
+	SetFFprobePath(filepath.Join(dir, "other-ffprobe"))
+	if err := CheckBinaries(); err == nil {
+		t.Error("CheckBinaries() = nil after changing ffprobe to a missing executable")
+	}
+}
+
+func TestParseEncoders(t *testing.T) {
+	output := `Encoders:
+ V..... = Video
+ A..... = Audio
+ ------
+ V....D libx264              libx264 H.264 / AVC / MPEG-4 AVC / MPEG-4 part 10 (codec h264)
+ V....D h264_nvenc           NVIDIA NVENC H.264 encoder (codec h264)
+ A....D aac                  AAC (Advanced Audio Coding)
+ S..... webvtt               WebVTT subtitle
+`
+	video, audio := parseEncoders(output)
+	if want := []string{"libx264", "h264_nvenc"}; !reflect.DeepEqual(video, want) {
+		t.Errorf("video encoders = %v, want %v", video, want)
+	}
+	if want := []string{"aac"}; !reflect.DeepEqual(audio, want) {
+		t.Errorf("audio encoders = %v, want %v", audio, want)
+	}
+}
+
+func TestParseHWAccels(t *testing.T) {
+	output := "Hardware acceleration methods:\nvdpau\ncuda\n\n"
+	if got, want := parseHWAccels(output), []string{"vdpau", "cuda"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("parseHWAccels() = %v, want %v", got, want)
+	}
+}
+
+// fakeFFmpeg installs a shell script answering -version, -encoders and -hwaccels as the ffmpeg executable,
+// and returns the file in which it logs its invocations.
+func fakeFFmpeg(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := `#!/bin/sh
+echo "$@" >> ` + calls + `
+case "$2" in
+-version) echo "ffmpeg version 6.0 Copyright" ;;
+-encoders) printf ' ------\n V....D libx264  H.264\n A....D aac  AAC\n' ;;
+-hwaccels) printf 'Hardware acceleration methods:\ncuda\n' ;;
+esac
+`
+	path := filepath.Join(dir, "ffmpeg")
+	if err := os.WriteFile(path, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	SetFFmpegPath(path)
+	t.Cleanup(func() {
+		SetFFmpegPath("")
+		RefreshCapabilities()
+	})
+	return calls
+}
+
+func countCalls(t *testing.T, calls string) int {
+	t.Helper()
+	data, err := os.ReadFile(calls)
+	if os.IsNotExist(err) {
+		return 0
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.Count(string(data), "\n")
+}
+
+func TestCheckEncodersCachesCapabilities(t *testing.T) {
+	calls := fakeFFmpeg(t)
+
+	for i := 0; i < 3; i++ {
+		if err := checkEncoders(videoEncoder, audioEncoder); err != nil {
+			t.Fatalf("checkEncoders() = %v", err)
+		}
+	}
+	if got := countCalls(t, calls); got != 3 {
+		t.Errorf("ffmpeg ran %d times for 3 checks, want 3 (a single detection)", got)
+	}
+
+	RefreshCapabilities()
+	if err := checkEncoders(videoEncoder); err != nil {
+		t.Fatalf("checkEncoders() = %v", err)
+	}
+	if got := countCalls(t, calls); got != 6 {
+		t.Errorf("ffmpeg ran %d times after a refresh, want 6", got)
+	}
+}
+
+func TestCheckEncodersUnsupported(t *testing.T) {
+	fakeFFmpeg(t)
+
+	err := checkEncoders("h264_nvenc")
+	if err == nil || !strings.Contains(err.Error(), "h264_nvenc") {
+		t.Fatalf("checkEncoders() = %v, want an ErrUnsupportedEncoder for h264_nvenc", err)
+	}
+}
@@ -0,0 +1,59 @@
+package transcoder
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+)
+
+// fingerprintChunkSize is the number of bytes hashed at the start and at the end of the file.
+const fingerprintChunkSize = 4 << 20
+
+// ContentFingerprint returns a stable fingerprint of a media file, without reading it entirely:
+// its size, its duration (in milliseconds) and a SHA-256 of its first and last 4 MiB.
+//
+// Two files with the same fingerprint are very likely identical, but not certainly: an edit that
+// keeps the size and the duration and only touches bytes in the middle of the file (e.g. a tag
+// rewritten in place, or a remux producing the same size) goes unnoticed. Any change of size,
+// of duration, of headers or of the index at the end of the file changes the fingerprint.
+// The format is versioned ("v1:...") so that it can evolve without clashing with stored values.
+func ContentFingerprint(inputFile string) (string, error) {
+	file, err := os.Open(inputFile)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	size := info.Size()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, io.LimitReader(file, fingerprintChunkSize)); err != nil {
+		return "", fmt.Errorf("failed to read file start: %w", err)
+	}
+	if size > fingerprintChunkSize {
+		// The end chunk starts after the start chunk, so that no byte is hashed twice.
+		tailOffset := size - fingerprintChunkSize
+		if tailOffset < fingerprintChunkSize {
+			tailOffset = fingerprintChunkSize
+		}
+		if _, err := file.Seek(tailOffset, io.SeekStart); err != nil {
+			return "", fmt.Errorf("failed to seek file end: %w", err)
+		}
+		if _, err := io.Copy(hash, file); err != nil {
+			return "", fmt.Errorf("failed to read file end: %w", err)
+		}
+	}
+
+	duration, err := getVideoDuration(inputFile)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("v1:%d:%d:%s", size, duration.Milliseconds(), hex.EncodeToString(hash.Sum(nil))), nil
+}
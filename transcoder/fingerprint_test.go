@@ -0,0 +1,87 @@
+package transcoder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestContentFingerprint(t *testing.T) {
+	newFakeTools(t, fakeProbe{entries: "format=duration", output: "5.000000"})
+	dir := t.TempDir()
+	original := make([]byte, 2*fingerprintChunkSize+1024)
+	for i := range original {
+		original[i] = byte(i % 251)
+	}
+	fingerprint := func(name string, edit func(data []byte)) string {
+		t.Helper()
+		data := append([]byte(nil), original...)
+		if edit != nil {
+			edit(data)
+		}
+		file := filepath.Join(dir, name)
+		if err := os.WriteFile(file, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := ContentFingerprint(file)
+		if err != nil {
+			t.Fatalf("ContentFingerprint() = %v", err)
+		}
+		return got
+	}
+
+	want := fingerprint("original.mkv", nil)
+	if !strings.HasPrefix(want, "v1:8389632:5000:") {
+		t.Errorf("ContentFingerprint() = %q, want the v1 format with the size and duration", want)
+	}
+	tests := []struct {
+		name     string
+		edit     func(data []byte)
+		wantSame bool
+	}{
+		{name: "copy", wantSame: true},
+		// documented limitation: the middle of the file is not read
+		{name: "middle", edit: func(data []byte) { data[fingerprintChunkSize+512] ^= 0xff }, wantSame: true},
+		{name: "start", edit: func(data []byte) { data[0] ^= 0xff }},
+		{name: "end", edit: func(data []byte) { data[len(data)-1] ^= 0xff }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprint(tt.name+".mkv", tt.edit); (got == want) != tt.wantSame {
+				t.Errorf("fingerprint after a %s edit = %q, original %q, want same %v", tt.name, got, want, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestContentFingerprintSmallFile(t *testing.T) {
+	newFakeTools(t, fakeProbe{entries: "format=duration", output: "1.500000"})
+	file := filepath.Join(t.TempDir(), "short.mkv")
+	if err := os.WriteFile(file, []byte("court métrage"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ContentFingerprint(file)
+	if err != nil {
+		t.Fatalf("ContentFingerprint() = %v", err)
+	}
+	// The whole file is hashed once.
+	if want := "v1:14:1500:ad51558a972adad2683cc25d0bb845da39bcbff7ce74893514db15c936da4e6d"; got != want {
+		t.Errorf("ContentFingerprint() = %q, want %q", got, want)
+	}
+}
+
+func TestContentFingerprintErrors(t *testing.T) {
+	newFakeTools(t)
+	if _, err := ContentFingerprint(filepath.Join(t.TempDir(), "missing.mkv")); !os.IsNotExist(err) {
+		t.Errorf("ContentFingerprint() of a missing file = %v, want a not exist error", err)
+	}
+	file := filepath.Join(t.TempDir(), "movie.mkv")
+	if err := os.WriteFile(file, []byte("movie"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ContentFingerprint(file); err == nil {
+		t.Error("ContentFingerprint() = nil error, want the ffprobe failure")
+	}
+}
@@ -0,0 +1,94 @@
+package transcoder
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"sync"
+	"time"
+)
+
+type introCacheKey struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+var (
+	introDurationsLock sync.Mutex
+	introDurations     = map[introCacheKey]time.Duration{}
+)
+
+// getIntroDuration returns the duration of an intro file, probing it only once per path, modification
+// time and size so that successive jobs sharing the same intro do not run ffprobe again.
+func getIntroDuration(introFile string) (time.Duration, error) {
+	info, err := os.Stat(introFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat intro file: %w", err)
+	}
+	key := introCacheKey{path: introFile, modTime: info.ModTime(), size: info.Size()}
+
+	introDurationsLock.Lock()
+	defer introDurationsLock.Unlock()
+	if duration, ok := introDurations[key]; ok {
+		return duration, nil
+	}
+	duration, err := getVideoDuration(introFile)
+	if err != nil {
+		return 0, err
+	}
+	for cached := range introDurations {
+		if cached.path == introFile {
+			delete(introDurations, cached)
+		}
+	}
+	introDurations[key] = duration
+	return duration, nil
+}
+
+// aspectRatioTolerance is the relative difference under which two aspect ratios are considered equal.
+const aspectRatioTolerance = 0.01
+
+// introScaleFilter returns the filter scaling the intro to videoScale ("width:height"). When the intro
+// aspect ratio differs from the target one, the intro is scaled to fit and padded with black bars instead
+// of being stretched, so the cut to the content does not jump between two framings.
+func introScaleFilter(introFile, videoScale string) string {
+	filter := "scale=" + videoScale
+	targetWidth, targetHeight, err := parseVideoScale(videoScale)
+	if err != nil {
+		logger.Warnf("Impossible de lire la résolution cible %s : %v", videoScale, err)
+		return filter
+	}
+	width, height, err := probeVideoSize(introFile)
+	if err != nil {
+		logger.Warnf("Impossible de récupérer la résolution de l'intro : %v", err)
+		return filter
+	}
+	introRatio := float64(width) / float64(height)
+	targetRatio := float64(targetWidth) / float64(targetHeight)
+	if math.Abs(introRatio-targetRatio)/targetRatio <= aspectRatioTolerance {
+		return filter
+	}
+	logger.Warnf("Le ratio de l'intro (%dx%d) ne correspond pas à la résolution cible %s, l'intro sera recadrée avec des bandes noires", width, height, videoScale)
+	return fmt.Sprintf("scale=%d:%d:force_original_aspect_ratio=decrease,pad=%d:%d:(ow-iw)/2:(oh-ih)/2", targetWidth, targetHeight, targetWidth, targetHeight)
+}
+
+// Intro is a pre-roll bumper, with its variant for 21:9 content. Path219 defaults to Path when empty.
+type Intro struct {
+	Path    string
+	Path219 string
+}
+
+// selectIntro returns the intro registered with WithCategoryIntro for the content category,
+// or the default intro given to ProcessFileTranscode when there is none.
+func (o *transcodeOptions) selectIntro(introPath, intro219Path string) (string, string) {
+	intro, ok := o.categoryIntros[o.contentCategory]
+	if o.contentCategory == "" || !ok || intro.Path == "" {
+		return introPath, intro219Path
+	}
+	logger.Infof("Intro de la catégorie %s : %s", o.contentCategory, intro.Path)
+	if intro.Path219 == "" {
+		return intro.Path, intro.Path
+	}
+	return intro.Path, intro.Path219
+}
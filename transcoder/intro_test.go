@@ -0,0 +1,175 @@
+package transcoder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGetIntroDurationCache(t *testing.T) {
+	ft := newFakeTools(t, fakeProbe{entries: "format=duration", output: "5.000000"})
+	intro := filepath.Join(t.TempDir(), "intro.mkv")
+	if err := os.WriteFile(intro, []byte("v1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	getDuration := func() time.Duration {
+		t.Helper()
+		duration, err := getIntroDuration(intro)
+		if err != nil {
+			t.Fatalf("getIntroDuration() = %v", err)
+		}
+		return duration
+	}
+
+	if got := getDuration(); got != 5*time.Second {
+		t.Fatalf("getIntroDuration() = %s, want 5s", got)
+	}
+
+	// The unchanged intro is not probed again
+	ft.writeFFprobe(t, fakeProbe{entries: "format=duration", output: "7.000000"})
+	if got := getDuration(); got != 5*time.Second {
+		t.Errorf("getIntroDuration() = %s, want the cached 5s", got)
+	}
+
+	// A new intro with the same path is
+	if err := os.WriteFile(intro, []byte("version 2"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := getDuration(); got != 7*time.Second {
+		t.Errorf("getIntroDuration() = %s, want 7s once the intro changed", got)
+	}
+	entries := 0
+	introDurationsLock.Lock()
+	for key := range introDurations {
+		if key.path == intro {
+			entries++
+		}
+	}
+	introDurationsLock.Unlock()
+	if entries != 1 {
+		t.Errorf("%d cached intro durations, want the stale one replaced", entries)
+	}
+}
+
+func TestGetIntroDurationMissingFile(t *testing.T) {
+	newFakeTools(t, fakeProbe{entries: "format=duration", output: "5.000000"})
+	if _, err := getIntroDuration(filepath.Join(t.TempDir(), "intro.mkv")); err == nil {
+		t.Error("getIntroDuration() = nil error for a missing intro")
+	}
+}
+
+func TestProcessFileTranscodeIntroPadding(t *testing.T) {
+	tests := []struct {
+		name       string
+		introSize  string
+		wantFilter string
+	}{
+		{name: "same ratio", introSize: `"width": 1920, "height": 1080`, wantFilter: "[0:v:0]scale=1280:720,format=yuv420p"},
+		{name: "within tolerance", introSize: `"width": 1916, "height": 1080`, wantFilter: "[0:v:0]scale=1280:720,format=yuv420p"},
+		{name: "4:3 intro", introSize: `"width": 1440, "height": 1080`,
+			wantFilter: "[0:v:0]scale=1280:720:force_original_aspect_ratio=decrease,pad=1280:720:(ow-iw)/2:(oh-ih)/2,format=yuv420p"},
+		{name: "unknown size", wantFilter: "[0:v:0]scale=1280:720,format=yuv420p"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probes := sampleMovieProbes()
+			if tt.introSize != "" {
+				probes = append(probes, fakeProbe{
+					entries: "stream=index,codec_name,codec_type,width,height,coded_width,coded_height,sample_aspect_ratio",
+					output:  `{"streams": [{"index": 0, "codec_name": "h264", "codec_type": "video", ` + tt.introSize + `}]}`,
+				})
+			}
+			ft := newFakeTools(t, probes...)
+
+			if _, _, err := transcodeSample(t); err != nil {
+				t.Fatalf("ProcessFileTranscode() = %v", err)
+			}
+			if call := ft.ffmpegCall(t, "index.m3u8"); !strings.Contains(call, tt.wantFilter) {
+				t.Errorf("video ffmpeg run = %q, want the intro filter %q", call, tt.wantFilter)
+			}
+		})
+	}
+}
+
+func TestSelectIntro(t *testing.T) {
+	intros := map[string]Intro{
+		"mature": {Path: "mature.mkv", Path219: "mature_21-9.mkv"},
+		"kids":   {Path: "kids.mkv"},
+		"empty":  {},
+	}
+	tests := []struct {
+		name                  string
+		opts                  []TranscodeOption
+		wantIntro, wantIntro2 string
+	}{
+		{name: "no option", wantIntro: "intro.mkv", wantIntro2: "intro_21-9.mkv"},
+		{name: "category", opts: []TranscodeOption{WithCategoryIntro("mature", intros)}, wantIntro: "mature.mkv", wantIntro2: "mature_21-9.mkv"},
+		{name: "no 21:9 variant", opts: []TranscodeOption{WithCategoryIntro("kids", intros)}, wantIntro: "kids.mkv", wantIntro2: "kids.mkv"},
+		{name: "unknown category", opts: []TranscodeOption{WithCategoryIntro("documentary", intros)}, wantIntro: "intro.mkv", wantIntro2: "intro_21-9.mkv"},
+		{name: "intro without path", opts: []TranscodeOption{WithCategoryIntro("empty", intros)}, wantIntro: "intro.mkv", wantIntro2: "intro_21-9.mkv"},
+		{name: "no category", opts: []TranscodeOption{WithCategoryIntro("", intros)}, wantIntro: "intro.mkv", wantIntro2: "intro_21-9.mkv"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			intro, intro219 := newTranscodeOptions(tt.opts).selectIntro("intro.mkv", "intro_21-9.mkv")
+			if intro != tt.wantIntro || intro219 != tt.wantIntro2 {
+				t.Errorf("selectIntro() = %s, %s, want %s, %s", intro, intro219, tt.wantIntro, tt.wantIntro2)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeCategoryIntro(t *testing.T) {
+	ft := newFakeTools(t, sampleMovieProbes()...)
+	dir := t.TempDir()
+	input := writeSampleInputs(t, dir)
+	mature := filepath.Join(dir, "mature.mkv")
+	if err := os.WriteFile(mature, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := ProcessFileTranscode(input, filepath.Join(dir, "intro.mkv"), filepath.Join(dir, "intro_21-9.mkv"),
+		"42", filepath.Join(dir, "media"), "10", "1280:720", "1920:816",
+		WithCategoryIntro("mature", map[string]Intro{"mature": {Path: mature}}))
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	for _, output := range []string{"index.m3u8", "audio_1.m3u8", "audio_2.m3u8"} {
+		if call := ft.ffmpegCall(t, output); !strings.Contains(call, "-i "+mature+" ") {
+			t.Errorf("ffmpeg run of %s = %q, want the mature intro", output, call)
+		}
+	}
+}
+
+func TestProcessFileTranscodeWideIntroOffsets(t *testing.T) {
+	dir := t.TempDir()
+	input := writeSampleInputs(t, dir)
+	intro, intro219 := filepath.Join(dir, "intro.mkv"), filepath.Join(dir, "intro_21-9.mkv")
+	ft := newFakeTools(t,
+		fakeProbe{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,h264,video,64:27\n1,aac,audio\n2,subrip,subtitle"},
+		fakeProbe{entries: "format=duration", input: intro, output: "3.000000"},
+		fakeProbe{entries: "format=duration", input: intro219, output: "7.000000"},
+		fakeProbe{entries: "format=duration", output: "5.000000"},
+	)
+
+	outputFolder := filepath.Join(dir, "media")
+	response, err := ProcessFileTranscode(input, intro, intro219, "42", outputFolder, "10", "1280:720", "1920:816")
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	for _, output := range []string{"index.m3u8", "audio_1.m3u8"} {
+		if call := ft.ffmpegCall(t, output); !strings.Contains(call, "-i "+intro219+" ") {
+			t.Errorf("ffmpeg run of %s = %q, want the 21:9 intro", output, call)
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(outputFolder, "42", response.Subtitles[0].SubtitleIndex))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The fake ffmpeg writes a cue at 1s, shifted by the 7s of the 21:9 intro.
+	if !strings.Contains(string(data), "00:00:08.000 --> 00:00:09.000") {
+		t.Errorf("subtitle not shifted by the 21:9 intro:\n%s", data)
+	}
+}
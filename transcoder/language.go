@@ -0,0 +1,86 @@
+package transcoder
+
+import (
+	"strings"
+)
+
+// undeterminedLanguage is the ISO 639-2 code of an unknown or missing language.
+const undeterminedLanguage = "und"
+
+type language struct {
+	code string
+	name string
+}
+
+// languages maps the ISO 639-1, 639-2/B and 639-2/T codes of common languages to their ISO 639-1 code,
+// the one TMDB uses, and their English name.
+var languages = map[string]language{}
+
+func init() {
+	for _, l := range []struct {
+		codes []string
+		name  string
+	}{
+		{[]string{"ar", "ara"}, "Arabic"},
+		{[]string{"bg", "bul"}, "Bulgarian"},
+		{[]string{"ca", "cat"}, "Catalan"},
+		{[]string{"cs", "cze", "ces"}, "Czech"},
+		{[]string{"da", "dan"}, "Danish"},
+		{[]string{"de", "ger", "deu"}, "German"},
+		{[]string{"el", "gre", "ell"}, "Greek"},
+		{[]string{"en", "eng"}, "English"},
+		{[]string{"es", "spa"}, "Spanish"},
+		{[]string{"et", "est"}, "Estonian"},
+		{[]string{"fa", "per", "fas"}, "Persian"},
+		{[]string{"fi", "fin"}, "Finnish"},
+		{[]string{"fr", "fre", "fra"}, "French"},
+		{[]string{"he", "heb"}, "Hebrew"},
+		{[]string{"hi", "hin"}, "Hindi"},
+		{[]string{"hr", "hrv"}, "Croatian"},
+		{[]string{"hu", "hun"}, "Hungarian"},
+		{[]string{"id", "ind"}, "Indonesian"},
+		{[]string{"is", "ice", "isl"}, "Icelandic"},
+		{[]string{"it", "ita"}, "Italian"},
+		{[]string{"ja", "jpn"}, "Japanese"},
+		{[]string{"ko", "kor"}, "Korean"},
+		{[]string{"lt", "lit"}, "Lithuanian"},
+		{[]string{"lv", "lav"}, "Latvian"},
+		{[]string{"ms", "may", "msa"}, "Malay"},
+		{[]string{"nl", "dut", "nld"}, "Dutch"},
+		{[]string{"no", "nor", "nb", "nob"}, "Norwegian"},
+		{[]string{"pl", "pol"}, "Polish"},
+		{[]string{"pt", "por"}, "Portuguese"},
+		{[]string{"ro", "rum", "ron"}, "Romanian"},
+		{[]string{"ru", "rus"}, "Russian"},
+		{[]string{"sk", "slo", "slk"}, "Slovak"},
+		{[]string{"sl", "slv"}, "Slovenian"},
+		{[]string{"sr", "srp"}, "Serbian"},
+		{[]string{"sv", "swe"}, "Swedish"},
+		{[]string{"th", "tha"}, "Thai"},
+		{[]string{"tr", "tur"}, "Turkish"},
+		{[]string{"uk", "ukr"}, "Ukrainian"},
+		{[]string{"vi", "vie"}, "Vietnamese"},
+		{[]string{"zh", "chi", "zho"}, "Chinese"},
+	} {
+		for _, code := range l.codes {
+			languages[code] = language{code: l.codes[0], name: l.name}
+		}
+	}
+}
+
+// NormalizeLanguage maps a language tag as found in media files ("fre", "fra", "fr", "fr-FR") to its
+// canonical ISO 639-1 code ("fr") and English name ("French"). A missing or undetermined tag gives
+// "und" and "Undetermined"; an unknown tag is returned lowercased, as its own name.
+func NormalizeLanguage(tag string) (code string, displayName string) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	if tag == "" || tag == undeterminedLanguage {
+		return undeterminedLanguage, "Undetermined"
+	}
+	if l, ok := languages[tag]; ok {
+		return l.code, l.name
+	}
+	return tag, tag
+}
@@ -0,0 +1,32 @@
+package transcoder
+
+import (
+	"testing"
+)
+
+func TestNormalizeLanguage(t *testing.T) {
+	tests := []struct {
+		tag      string
+		wantCode string
+		wantName string
+	}{
+		{"fr", "fr", "French"},
+		{"fre", "fr", "French"},
+		{"fra", "fr", "French"},
+		{"fr-FR", "fr", "French"},
+		{" ENG ", "en", "English"},
+		{"pt_BR", "pt", "Portuguese"},
+		{"nob", "no", "Norwegian"},
+		{"", "und", "Undetermined"},
+		{"und", "und", "Undetermined"},
+		{"Klingon", "klingon", "klingon"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			code, name := NormalizeLanguage(tt.tag)
+			if code != tt.wantCode || name != tt.wantName {
+				t.Errorf("NormalizeLanguage(%q) = %q, %q, want %q, %q", tt.tag, code, name, tt.wantCode, tt.wantName)
+			}
+		})
+	}
+}
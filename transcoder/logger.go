@@ -0,0 +1,16 @@
+package transcoder
+
+import (
+	"github.com/bingemate/media-go-pkg/logging"
+)
+
+var logger = logging.Default
+
+// SetLogger sets the logger used by the transcoder, logging.Default if l is nil.
+// It is not safe to call while a transcode is running.
+func SetLogger(l logging.Logger) {
+	if l == nil {
+		l = logging.Default
+	}
+	logger = l
+}
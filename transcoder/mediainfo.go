@@ -0,0 +1,113 @@
+package transcoder
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// MediaInfo holds the technical metadata of a media file, as reported by ffprobe.
+// Bitrates are in bits per second and are 0 when the container does not report them.
+type MediaInfo struct {
+	Container string        `json:"container"`
+	Duration  time.Duration `json:"duration"`
+	Size      int64         `json:"size"`
+	Bitrate   int64         `json:"bitrate"`
+	Streams   []StreamInfo  `json:"streams"`
+}
+
+// StreamInfo holds the technical metadata of a single stream of a media file.
+// Video fields are only set on video streams and audio fields on audio streams.
+type StreamInfo struct {
+	Index         int    `json:"index"`
+	Type          string `json:"type"`
+	Codec         string `json:"codec"`
+	Bitrate       int64  `json:"bitrate"`
+	Language      string `json:"language"`
+	Default       bool   `json:"default"`
+	Forced        bool   `json:"forced"`
+	Width         int    `json:"width,omitempty"`
+	Height        int    `json:"height,omitempty"`
+	PixelFormat   string `json:"pixel_format,omitempty"`
+	SampleRate    int    `json:"sample_rate,omitempty"`
+	Channels      int    `json:"channels,omitempty"`
+	ChannelLayout string `json:"channel_layout,omitempty"`
+}
+
+// IsTextSubtitle reports whether the stream is a text subtitle stream, the only subtitles extracted
+// by ProcessFileTranscode; bitmap subtitles (DVD, PGS) cannot be converted to WebVTT.
+func (s StreamInfo) IsTextSubtitle() bool {
+	return s.Type == "subtitle" && !isBitmapSubtitleCodec(s.Codec)
+}
+
+// isBitmapSubtitleCodec reports whether a subtitle codec stores images rather than text.
+func isBitmapSubtitleCodec(codecName string) bool {
+	return codecName == "dvd_subtitle" || codecName == "hdmv_pgs_subtitle"
+}
+
+// ProbeMedia returns the container, duration, size, overall bitrate and per-stream details of a media file.
+// It only reads the file.
+func ProbeMedia(inputFile string) (*MediaInfo, error) {
+	cmd := exec.Command(ffprobePath,
+		"-v", "error",
+		"-show_format",
+		"-show_streams",
+		"-of", "json",
+		inputFile,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute command: %w", err)
+	}
+	probe, err := parseFFprobeOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	return newMediaInfo(probe), nil
+}
+
+// newMediaInfo maps an ffprobe output to a MediaInfo. Numeric values ffprobe reports as strings
+// are left to 0 when missing or "N/A".
+func newMediaInfo(probe *ffprobeOutput) *MediaInfo {
+	info := &MediaInfo{
+		Container: probe.Format.FormatName,
+		Size:      parseInt(probe.Format.Size),
+		Bitrate:   parseInt(probe.Format.BitRate),
+	}
+	if seconds, err := strconv.ParseFloat(probe.Format.Duration, 64); err == nil {
+		info.Duration = time.Duration(seconds * float64(time.Second))
+	}
+	for _, stream := range probe.Streams {
+		streamInfo := StreamInfo{
+			Index:    stream.Index,
+			Type:     stream.CodecType,
+			Codec:    stream.CodecName,
+			Bitrate:  parseInt(stream.BitRate),
+			Language: stream.Tags["language"],
+			Default:  stream.Disposition.Default == 1,
+			Forced:   stream.Disposition.Forced == 1,
+		}
+		switch stream.CodecType {
+		case "video":
+			streamInfo.Width = stream.Width
+			streamInfo.Height = stream.Height
+			streamInfo.PixelFormat = stream.PixFmt
+		case "audio":
+			streamInfo.SampleRate = int(parseInt(stream.SampleRate))
+			streamInfo.Channels = stream.Channels
+			streamInfo.ChannelLayout = stream.ChannelLayout
+		}
+		info.Streams = append(info.Streams, streamInfo)
+	}
+	return info
+}
+
+// parseInt parses an integer reported as a string by ffprobe, returning 0 when it is missing or invalid.
+func parseInt(value string) int64 {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,89 @@
+package transcoder
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+const sampleMediaProbe = `{
+    "streams": [
+        {
+            "index": 0,
+            "codec_name": "hevc",
+            "codec_type": "video",
+            "width": 3840,
+            "height": 2160,
+            "pix_fmt": "yuv420p10le",
+            "disposition": {"default": 1, "forced": 0}
+        },
+        {
+            "index": 1,
+            "codec_name": "eac3",
+            "codec_type": "audio",
+            "sample_rate": "48000",
+            "channels": 6,
+            "channel_layout": "5.1(side)",
+            "bit_rate": "640000",
+            "disposition": {"default": 1, "forced": 0},
+            "tags": {"language": "fre"}
+        },
+        {
+            "index": 2,
+            "codec_name": "subrip",
+            "codec_type": "subtitle",
+            "bit_rate": "N/A",
+            "disposition": {"default": 0, "forced": 1},
+            "tags": {"language": "eng"}
+        }
+    ],
+    "format": {
+        "format_name": "matroska,webm",
+        "duration": "5423.168000",
+        "size": "12884901888",
+        "bit_rate": "19007386"
+    }
+}`
+
+func TestProbeMedia(t *testing.T) {
+	newFakeTools(t, fakeProbe{output: sampleMediaProbe})
+
+	info, err := ProbeMedia("movie.mkv")
+	if err != nil {
+		t.Fatalf("ProbeMedia() = %v", err)
+	}
+	want := &MediaInfo{
+		Container: "matroska,webm",
+		Duration:  5423168 * time.Millisecond,
+		Size:      12884901888,
+		Bitrate:   19007386,
+		Streams: []StreamInfo{
+			{Index: 0, Type: "video", Codec: "hevc", Default: true, Width: 3840, Height: 2160, PixelFormat: "yuv420p10le"},
+			{Index: 1, Type: "audio", Codec: "eac3", Bitrate: 640000, Language: "fre", Default: true, SampleRate: 48000, Channels: 6, ChannelLayout: "5.1(side)"},
+			{Index: 2, Type: "subtitle", Codec: "subrip", Language: "eng", Forced: true},
+		},
+	}
+	if !reflect.DeepEqual(info, want) {
+		t.Errorf("ProbeMedia() = %+v, want %+v", info, want)
+	}
+}
+
+func TestProbeMediaMissingFormat(t *testing.T) {
+	newFakeTools(t, fakeProbe{output: `{"streams": [], "format": {"format_name": "mpegts", "duration": "N/A", "size": "N/A"}}`})
+
+	info, err := ProbeMedia("live.ts")
+	if err != nil {
+		t.Fatalf("ProbeMedia() = %v", err)
+	}
+	if want := (&MediaInfo{Container: "mpegts"}); !reflect.DeepEqual(info, want) {
+		t.Errorf("ProbeMedia() = %+v, want %+v", info, want)
+	}
+}
+
+func TestProbeMediaError(t *testing.T) {
+	newFakeTools(t)
+
+	if _, err := ProbeMedia("movie.mkv"); err == nil {
+		t.Error("ProbeMedia() = nil error, want the ffprobe failure")
+	}
+}
@@ -0,0 +1,31 @@
+package transcoder
+
+import (
+	"os"
+	"time"
+)
+
+// TranscodeMetrics holds machine-readable measurements of a successful ProcessFileTranscode call.
+// Stage durations are zero for stages that were skipped, e.g. a video playlist reused with WithResume.
+type TranscodeMetrics struct {
+	MediaID           string
+	ProbeDuration     time.Duration
+	VideoDuration     time.Duration
+	AudioDuration     time.Duration
+	SubtitlesDuration time.Duration
+	TotalDuration     time.Duration
+	InputSize         int64
+	OutputSize        int64
+	AudioStreams      int
+	SubtitleStreams   int
+	FailedSubtitles   int
+}
+
+// fileSize returns the size of a file, or 0 when it cannot be read.
+func fileSize(path string) int64 {
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0
+	}
+	return info.Size()
+}
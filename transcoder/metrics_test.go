@@ -0,0 +1,42 @@
+package transcoder
+
+import (
+	"testing"
+)
+
+func TestProcessFileTranscodeMetrics(t *testing.T) {
+	newFakeTools(t, sampleMovieProbes()...)
+	var reported []TranscodeMetrics
+	response, _, err := transcodeSample(t, WithMetricsCallback(func(m TranscodeMetrics) {
+		reported = append(reported, m)
+	}))
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	if len(reported) != 1 {
+		t.Fatalf("callback called %d times, want 1", len(reported))
+	}
+	m := reported[0]
+	if m.MediaID != "42" || m.AudioStreams != 2 || m.SubtitleStreams != 1 || m.FailedSubtitles != 0 {
+		t.Errorf("metrics = %+v, want media 42 with 2 audio streams and 1 subtitle", m)
+	}
+	if m.OutputSize != response.OutputSize {
+		t.Errorf("OutputSize = %d, want %d", m.OutputSize, response.OutputSize)
+	}
+	if m.TotalDuration < m.ProbeDuration+m.VideoDuration+m.AudioDuration+m.SubtitlesDuration {
+		t.Errorf("TotalDuration %s is shorter than the sum of the stages in %+v", m.TotalDuration, m)
+	}
+}
+
+func TestProcessFileTranscodeMetricsOnFailure(t *testing.T) {
+	ft := newFakeTools(t, sampleMovieProbes()...)
+	ft.writeFFmpeg(t, "*index.m3u8")
+	called := false
+	_, _, err := transcodeSample(t, WithMetricsCallback(func(TranscodeMetrics) { called = true }))
+	if err == nil {
+		t.Fatal("ProcessFileTranscode() = nil error, want the ffmpeg failure")
+	}
+	if called {
+		t.Error("callback called for a failed transcode")
+	}
+}
@@ -0,0 +1,181 @@
+package transcoder
+
+import (
+	"os"
+	"time"
+)
+
+const defaultOutputPermissions os.FileMode = 0755
+
+type transcodeOptions struct {
+	outputPermissions   os.FileMode
+	writeSidecar        bool
+	preserveASSStyling  bool
+	requireAudio        bool
+	audioTracks         []string
+	subtitleTracks      []string
+	thumbnail           bool
+	thumbnailOffset     time.Duration
+	toneMapHDR          bool
+	resume              bool
+	tempDir             string
+	metricsCallback     func(TranscodeMetrics)
+	wideAspectThreshold float64
+	extraAspectBuckets  []aspectBucket
+	watermark           *Watermark
+	contentCategory     string
+	categoryIntros      map[string]Intro
+}
+
+// TranscodeOption customizes a ProcessFileTranscode call.
+type TranscodeOption func(*transcodeOptions)
+
+func newTranscodeOptions(opts []TranscodeOption) *transcodeOptions {
+	options := &transcodeOptions{
+		outputPermissions:   defaultOutputPermissions,
+		wideAspectThreshold: defaultWideAspectThreshold,
+	}
+	for _, opt := range opts {
+		opt(options)
+	}
+	return options
+}
+
+// scratchDir returns the directory holding the intermediate files of a transcode.
+func (o *transcodeOptions) scratchDir() string {
+	if o.tempDir != "" {
+		return o.tempDir
+	}
+	return os.TempDir()
+}
+
+// WithOutputPermissions sets the permissions applied to the output folder once the transcode is done.
+// A mode of 0 leaves the folder permissions untouched.
+func WithOutputPermissions(mode os.FileMode) TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.outputPermissions = mode
+	}
+}
+
+// WithResponseSidecar writes the TranscodeResponse as response.json into the output folder.
+func WithResponseSidecar() TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.writeSidecar = true
+	}
+}
+
+// WithASSStyling converts ASS/SSA subtitles through astisub to keep their basic styling
+// (alignment, italics, colors) as WebVTT cue settings instead of flattening them to plain text.
+func WithASSStyling() TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.preserveASSStyling = true
+	}
+}
+
+// WithRequireAudio rejects input files without an audio stream with ErrNoAudioStream.
+// By default such files are transcoded without audio renditions.
+func WithRequireAudio() TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.requireAudio = true
+	}
+}
+
+// WithAudioTracks transcodes only the audio streams matching one of the selectors, given either
+// as a stream index ("1") or as a language tag ("eng", "fre"). Every audio stream is transcoded
+// when no stream matches.
+func WithAudioTracks(selectors ...string) TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.audioTracks = selectors
+	}
+}
+
+// WithSubtitleTracks extracts only the subtitle streams matching one of the selectors, given either
+// as a stream index or as a language tag. Every subtitle stream is extracted when no stream matches.
+func WithSubtitleTracks(selectors ...string) TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.subtitleTracks = selectors
+	}
+}
+
+// WithThumbnail extracts a frame of the source as thumbnail.jpg into the output folder, at the
+// given offset from the start of the source. A zero offset picks the frame at 10% of the duration.
+func WithThumbnail(offset time.Duration) TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.thumbnail = true
+		o.thumbnailOffset = offset
+	}
+}
+
+// WithHDRToneMapping converts HDR10 / HLG sources to SDR through a zscale + tonemap filter chain
+// before encoding, so they do not look washed out once encoded as yuv420p.
+func WithHDRToneMapping() TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.toneMapHDR = true
+	}
+}
+
+// WithResume reuses the complete video and audio playlists of a previously published output of the
+// same media instead of transcoding them again, e.g. when a run is retried after a failed upload.
+// Only whole playlists are reused: the source, the intro and the scale must not have changed.
+func WithResume() TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.resume = true
+	}
+}
+
+// WithTempDir sets the directory where intermediate files are written and where the output is staged
+// before being moved into place, e.g. a fast local disk when the output folder lives on network storage.
+// A staged output is copied next to the output folder before the final rename. Without this option,
+// intermediate files go to os.TempDir and the output is staged next to the output folder.
+func WithTempDir(dir string) TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.tempDir = dir
+	}
+}
+
+// WithMetricsCallback calls fn with the stage durations, sizes and stream counts of the transcode
+// once it has succeeded, e.g. to feed throughput dashboards.
+func WithMetricsCallback(fn func(TranscodeMetrics)) TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.metricsCallback = fn
+	}
+}
+
+// WithAspectThreshold sets the aspect ratio above which the 21:9 scale and intro are used instead of
+// the 16:9 ones. It defaults to 1.8.
+func WithAspectThreshold(ratio float64) TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.wideAspectThreshold = ratio
+	}
+}
+
+// WithAspectBucket adds a dedicated scale and intro for videos whose aspect ratio is above minRatio,
+// e.g. WithAspectBucket(2.3, "1920:800", introScope) for 2.39:1 content. The bucket with the highest
+// minimum ratio below the video aspect ratio wins, the 16:9 and 21:9 ones included.
+func WithAspectBucket(minRatio float64, videoScale, introPath string) TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.extraAspectBuckets = append(o.extraAspectBuckets, aspectBucket{
+			minRatio:   minRatio,
+			videoScale: videoScale,
+			introPath:  introPath,
+		})
+	}
+}
+
+// WithWatermark burns a logo or a text into the whole video, intro included. It is off by default.
+func WithWatermark(watermark Watermark) TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.watermark = &watermark
+	}
+}
+
+// WithCategoryIntro picks the pre-roll bumper of the content category among intros, keyed by categories
+// chosen by the caller (e.g. "mature" for a content warning before R-rated titles). The 16:9 or 21:9
+// variant of the chosen intro is then selected from the aspect ratio as usual. The intro given to
+// ProcessFileTranscode is used when the category has no intro.
+func WithCategoryIntro(category string, intros map[string]Intro) TranscodeOption {
+	return func(o *transcodeOptions) {
+		o.contentCategory = category
+		o.categoryIntros = intros
+	}
+}
@@ -0,0 +1,176 @@
+package transcoder
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// ffprobeOutput is the subset of "ffprobe -of json" output used by the transcoder.
+type ffprobeOutput struct {
+	Streams []ffprobeStream `json:"streams"`
+	Format  ffprobeFormat   `json:"format"`
+}
+
+type ffprobeFormat struct {
+	FormatName string            `json:"format_name"`
+	Duration   string            `json:"duration"`
+	Size       string            `json:"size"`
+	BitRate    string            `json:"bit_rate"`
+	Tags       map[string]string `json:"tags"`
+}
+
+type ffprobeStream struct {
+	Index             int                `json:"index"`
+	CodecName         string             `json:"codec_name"`
+	CodecType         string             `json:"codec_type"`
+	ColorTransfer     string             `json:"color_transfer"`
+	Width             int                `json:"width"`
+	Height            int                `json:"height"`
+	CodedWidth        int                `json:"coded_width"`
+	CodedHeight       int                `json:"coded_height"`
+	SampleAspectRatio string             `json:"sample_aspect_ratio"`
+	PixFmt            string             `json:"pix_fmt"`
+	BitRate           string             `json:"bit_rate"`
+	SampleRate        string             `json:"sample_rate"`
+	Channels          int                `json:"channels"`
+	ChannelLayout     string             `json:"channel_layout"`
+	Disposition       ffprobeDisposition `json:"disposition"`
+	Tags              map[string]string  `json:"tags"`
+}
+
+type ffprobeDisposition struct {
+	Default         int `json:"default"`
+	Forced          int `json:"forced"`
+	HearingImpaired int `json:"hearing_impaired"`
+	AttachedPic     int `json:"attached_pic"`
+}
+
+// parseFFprobeOutput decodes the JSON output of ffprobe.
+func parseFFprobeOutput(data []byte) (*ffprobeOutput, error) {
+	var output ffprobeOutput
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+	return &output, nil
+}
+
+// probeSubtitleMetadata fills the language and the forced / hearing impaired flags of the subtitle streams.
+func probeSubtitleMetadata(inputFile string, subtitleStreams []subtitleStream) error {
+	if len(subtitleStreams) == 0 {
+		return nil
+	}
+	cmd := exec.Command(ffprobePath,
+		"-v", "error",
+		"-select_streams", "s",
+		"-show_entries", "stream=index,codec_name,codec_type:stream_disposition=forced,hearing_impaired:stream_tags=language",
+		"-of", "json",
+		inputFile,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		return fmt.Errorf("failed to execute command: %w", err)
+	}
+	probe, err := parseFFprobeOutput(output)
+	if err != nil {
+		return err
+	}
+	applySubtitleMetadata(probe, subtitleStreams)
+	return nil
+}
+
+// applySubtitleMetadata copies the ffprobe language and disposition flags onto the matching subtitle streams.
+func applySubtitleMetadata(probe *ffprobeOutput, subtitleStreams []subtitleStream) {
+	for _, stream := range probe.Streams {
+		index := strconv.Itoa(stream.Index)
+		for i := range subtitleStreams {
+			if subtitleStreams[i].index != index {
+				continue
+			}
+			subtitleStreams[i].language, _ = NormalizeLanguage(stream.Tags["language"])
+			subtitleStreams[i].forced = stream.Disposition.Forced == 1
+			subtitleStreams[i].hearingImpaired = stream.Disposition.HearingImpaired == 1
+		}
+	}
+}
+
+// probeHDR reports whether the first video stream uses an HDR transfer function (HDR10 / PQ or HLG).
+func probeHDR(inputFile string) (bool, error) {
+	cmd := exec.Command(ffprobePath,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=index,codec_name,codec_type,color_transfer",
+		"-of", "json",
+		inputFile,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to execute command: %w", err)
+	}
+	probe, err := parseFFprobeOutput(output)
+	if err != nil {
+		return false, err
+	}
+	return isHDR(probe), nil
+}
+
+// isHDR reports whether a video stream of the probe output carries HDR color metadata.
+func isHDR(probe *ffprobeOutput) bool {
+	for _, stream := range probe.Streams {
+		if stream.CodecType != "video" {
+			continue
+		}
+		switch stream.ColorTransfer {
+		case "smpte2084", "arib-std-b67":
+			return true
+		}
+	}
+	return false
+}
+
+// findAttachedPicture returns the index of the first video stream flagged as an attached picture
+// (embedded cover art), or -1 when there is none.
+func findAttachedPicture(probe *ffprobeOutput) int {
+	for _, stream := range probe.Streams {
+		if stream.CodecType == "video" && stream.Disposition.AttachedPic == 1 {
+			return stream.Index
+		}
+	}
+	return -1
+}
+
+// probeVideoStream returns the geometry (dimensions and sample aspect ratio) of the first video stream.
+func probeVideoStream(inputFile string) (*ffprobeStream, error) {
+	cmd := exec.Command(ffprobePath,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=index,codec_name,codec_type,width,height,coded_width,coded_height,sample_aspect_ratio",
+		"-of", "json",
+		inputFile,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute command: %w", err)
+	}
+	probe, err := parseFFprobeOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	if len(probe.Streams) == 0 {
+		return nil, ErrNoVideoStream
+	}
+	return &probe.Streams[0], nil
+}
+
+// probeVideoSize returns the width and height of the first video stream.
+func probeVideoSize(inputFile string) (width, height int, err error) {
+	stream, err := probeVideoStream(inputFile)
+	if err != nil {
+		return 0, 0, err
+	}
+	if stream.Width == 0 || stream.Height == 0 {
+		return 0, 0, ErrNoVideoStream
+	}
+	return stream.Width, stream.Height, nil
+}
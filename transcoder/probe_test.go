@@ -0,0 +1,130 @@
+package transcoder
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const sampleSubtitleProbe = `{
+    "programs": [],
+    "streams": [
+        {
+            "index": 3,
+            "codec_name": "subrip",
+            "codec_type": "subtitle",
+            "disposition": {"forced": 0, "hearing_impaired": 0},
+            "tags": {"language": "fre"}
+        },
+        {
+            "index": 4,
+            "codec_name": "subrip",
+            "codec_type": "subtitle",
+            "disposition": {"forced": 1, "hearing_impaired": 0},
+            "tags": {"language": "fre", "title": "Forcés"}
+        },
+        {
+            "index": 5,
+            "codec_name": "subrip",
+            "codec_type": "subtitle",
+            "disposition": {"forced": 0, "hearing_impaired": 1},
+            "tags": {"language": "eng"}
+        },
+        {
+            "index": 6,
+            "codec_name": "subrip",
+            "codec_type": "subtitle",
+            "disposition": {"forced": 0, "hearing_impaired": 0}
+        }
+    ]
+}`
+
+func TestApplySubtitleMetadata(t *testing.T) {
+	probe, err := parseFFprobeOutput([]byte(sampleSubtitleProbe))
+	if err != nil {
+		t.Fatalf("parseFFprobeOutput() = %v", err)
+	}
+	streams := []subtitleStream{
+		{index: "3", codecName: "subrip"},
+		{index: "4", codecName: "subrip"},
+		{index: "5", codecName: "subrip"},
+		{index: "6", codecName: "subrip"},
+	}
+
+	applySubtitleMetadata(probe, streams)
+
+	want := []subtitleStream{
+		{index: "3", codecName: "subrip", language: "fr"},
+		{index: "4", codecName: "subrip", language: "fr", forced: true},
+		{index: "5", codecName: "subrip", language: "en", hearingImpaired: true},
+		{index: "6", codecName: "subrip", language: "und"},
+	}
+	if !reflect.DeepEqual(streams, want) {
+		t.Errorf("streams = %+v, want %+v", streams, want)
+	}
+}
+
+func TestParseFFprobeOutputInvalid(t *testing.T) {
+	if _, err := parseFFprobeOutput([]byte("not json")); err == nil {
+		t.Error("parseFFprobeOutput() = nil error for invalid JSON")
+	}
+}
+
+func TestIsHDR(t *testing.T) {
+	tests := []struct {
+		name  string
+		probe string
+		want  bool
+	}{
+		{name: "HDR10", probe: `{"streams": [{"index": 0, "codec_type": "video", "color_transfer": "smpte2084"}]}`, want: true},
+		{name: "HLG", probe: `{"streams": [{"index": 0, "codec_type": "video", "color_transfer": "arib-std-b67"}]}`, want: true},
+		{name: "SDR", probe: `{"streams": [{"index": 0, "codec_type": "video", "color_transfer": "bt709"}]}`, want: false},
+		{name: "no color metadata", probe: `{"streams": [{"index": 0, "codec_type": "video"}]}`, want: false},
+		{name: "not a video stream", probe: `{"streams": [{"index": 1, "codec_type": "audio", "color_transfer": "smpte2084"}]}`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe, err := parseFFprobeOutput([]byte(tt.probe))
+			if err != nil {
+				t.Fatalf("parseFFprobeOutput() = %v", err)
+			}
+			if got := isHDR(probe); got != tt.want {
+				t.Errorf("isHDR() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeHDRToneMapping(t *testing.T) {
+	tests := []struct {
+		name        string
+		transfer    string
+		opts        []TranscodeOption
+		wantToneMap bool
+	}{
+		{name: "HDR with tone mapping", transfer: "smpte2084", opts: []TranscodeOption{WithHDRToneMapping()}, wantToneMap: true},
+		{name: "HDR without tone mapping", transfer: "smpte2084", wantToneMap: false},
+		{name: "SDR with tone mapping", transfer: "bt709", opts: []TranscodeOption{WithHDRToneMapping()}, wantToneMap: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := newFakeTools(t, append(sampleMovieProbes(), fakeProbe{
+				entries: "stream=index,codec_name,codec_type,color_transfer",
+				output:  `{"streams": [{"index": 0, "codec_name": "hevc", "codec_type": "video", "color_transfer": "` + tt.transfer + `"}]}`,
+			})...)
+
+			if _, _, err := transcodeSample(t, tt.opts...); err != nil {
+				t.Fatalf("ProcessFileTranscode() = %v", err)
+			}
+			call := ft.ffmpegCall(t, "index.m3u8")
+			// Only the source, not the intro, is tone mapped
+			wantFilter := "[1:v:0]" + hdrToneMapFilter + "scale="
+			if got := strings.Contains(call, wantFilter); got != tt.wantToneMap {
+				t.Errorf("video ffmpeg run = %q, want tone mapping %v", call, tt.wantToneMap)
+			}
+			if strings.Contains(call, "[0:v:0]zscale") {
+				t.Errorf("video ffmpeg run = %q, want the intro left unchanged", call)
+			}
+		})
+	}
+}
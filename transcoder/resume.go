@@ -0,0 +1,100 @@
+package transcoder
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Resuming only ever reuses whole playlists from the previously published output folder:
+// individual segments are never re-encoded. A partial re-encode would need the new segments
+// to start on the exact keyframes and timestamps of the existing ones, which ffmpeg does not
+// guarantee across runs (the intro concat and -vsync shift the timestamps, and the encoder picks
+// its own GOP boundaries), so mixing segments of two runs would produce glitches at the seams.
+// A playlist is reused only when it is complete and every segment it lists is present, and it is
+// up to the caller to resume only when the source file, the intro and the scale did not change.
+
+// verifyPlaylist checks that an HLS playlist of the folder is complete (ends with #EXT-X-ENDLIST)
+// and that every segment it references exists and is not empty. It returns the playlist and
+// segment filenames.
+func verifyPlaylist(folder, playlist string) ([]string, error) {
+	file, err := os.Open(filepath.Join(folder, playlist))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	files := []string{playlist}
+	complete := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "#EXT-X-ENDLIST" {
+			complete = true
+		}
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		info, err := os.Stat(filepath.Join(folder, line))
+		if err != nil {
+			return nil, fmt.Errorf("missing segment %s: %w", line, err)
+		}
+		if info.Size() == 0 {
+			return nil, fmt.Errorf("empty segment %s", line)
+		}
+		files = append(files, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read playlist: %w", err)
+	}
+	if !complete {
+		return nil, errors.New("incomplete playlist")
+	}
+	return files, nil
+}
+
+// reusePlaylist copies a complete playlist and its segments from the previous output folder into
+// the work folder. It returns false, leaving the work folder untouched, when the playlist cannot be reused.
+func reusePlaylist(previousFolder, workFolder, playlist string) bool {
+	files, err := verifyPlaylist(previousFolder, playlist)
+	if err != nil {
+		logger.Debugf("Playlist %s non réutilisable : %v", playlist, err)
+		return false
+	}
+	for i, name := range files {
+		if err := linkOrCopyFile(filepath.Join(previousFolder, name), filepath.Join(workFolder, name)); err != nil {
+			logger.Warnf("Impossible de réutiliser %s : %v", name, err)
+			for _, copied := range files[:i+1] {
+				os.Remove(filepath.Join(workFolder, copied))
+			}
+			return false
+		}
+	}
+	logger.Infof("Playlist réutilisée : %s", playlist)
+	return true
+}
+
+// linkOrCopyFile hard links src to dst, falling back to a copy across file systems.
+func linkOrCopyFile(src, dst string) error {
+	if err := os.Link(src, dst); err == nil {
+		return nil
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
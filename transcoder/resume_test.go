@@ -0,0 +1,140 @@
+package transcoder
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestVerifyPlaylist(t *testing.T) {
+	tests := []struct {
+		name     string
+		playlist string
+		segments map[string]string
+		want     []string
+		wantErr  bool
+	}{
+		{
+			name:     "complete",
+			playlist: "#EXTM3U\n#EXTINF:10.0,\nindex_000.ts\n#EXTINF:4.0,\nindex_001.ts\n#EXT-X-ENDLIST\n",
+			segments: map[string]string{"index_000.ts": "data", "index_001.ts": "data"},
+			want:     []string{"index.m3u8", "index_000.ts", "index_001.ts"},
+		},
+		{
+			name:     "incomplete",
+			playlist: "#EXTM3U\n#EXTINF:10.0,\nindex_000.ts\n",
+			segments: map[string]string{"index_000.ts": "data"},
+			wantErr:  true,
+		},
+		{
+			name:     "missing segment",
+			playlist: "#EXTM3U\n#EXTINF:10.0,\nindex_000.ts\n#EXTINF:4.0,\nindex_001.ts\n#EXT-X-ENDLIST\n",
+			segments: map[string]string{"index_000.ts": "data"},
+			wantErr:  true,
+		},
+		{
+			name:     "empty segment",
+			playlist: "#EXTM3U\n#EXTINF:10.0,\nindex_000.ts\n#EXT-X-ENDLIST\n",
+			segments: map[string]string{"index_000.ts": ""},
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			files := map[string]string{"index.m3u8": tt.playlist}
+			for name, content := range tt.segments {
+				files[name] = content
+			}
+			for name, content := range files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			got, err := verifyPlaylist(dir, "index.m3u8")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verifyPlaylist() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("verifyPlaylist() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyPlaylistMissing(t *testing.T) {
+	if _, err := verifyPlaylist(t.TempDir(), "index.m3u8"); !os.IsNotExist(err) {
+		t.Errorf("verifyPlaylist() = %v, want a not exist error", err)
+	}
+}
+
+func TestProcessFileTranscodeResume(t *testing.T) {
+	ft := newFakeTools(t, sampleMovieProbes()...)
+	dir := t.TempDir()
+	input := writeSampleInputs(t, dir)
+	outputFolder := filepath.Join(dir, "media")
+	transcode := func(opts ...TranscodeOption) TranscodeResponse {
+		t.Helper()
+		os.Remove(ft.calls)
+		response, err := ProcessFileTranscode(input, filepath.Join(dir, "intro.mkv"), filepath.Join(dir, "intro_21-9.mkv"),
+			"42", outputFolder, "10", "1280:720", "1920:816", opts...)
+		if err != nil {
+			t.Fatalf("ProcessFileTranscode() = %v", err)
+		}
+		return response
+	}
+	// The fake ffmpeg writes empty segments: give the published ones some content
+	fillSegments := func() {
+		t.Helper()
+		segments, _ := filepath.Glob(filepath.Join(outputFolder, "42", "*.ts"))
+		for _, segment := range segments {
+			if err := os.WriteFile(segment, []byte("data"), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	transcoded := func(playlist string) bool {
+		for _, call := range ft.ffmpegCalls(t) {
+			if strings.HasSuffix(call, "/"+playlist) {
+				return true
+			}
+		}
+		return false
+	}
+
+	want := transcode()
+	fillSegments()
+
+	// Every playlist is reused
+	if response := transcode(WithResume()); !reflect.DeepEqual(response.Audios, want.Audios) {
+		t.Errorf("Audios = %+v, want %+v", response.Audios, want.Audios)
+	}
+	for _, playlist := range []string{"index.m3u8", "audio_1.m3u8", "audio_2.m3u8"} {
+		if transcoded(playlist) {
+			t.Errorf("%s transcoded again, want it reused", playlist)
+		}
+	}
+
+	// An incomplete audio playlist is transcoded again, alone
+	fillSegments()
+	if err := os.WriteFile(filepath.Join(outputFolder, "42", "audio_2.m3u8"), []byte("#EXTM3U\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	transcode(WithResume())
+	for playlist, want := range map[string]bool{"index.m3u8": false, "audio_1.m3u8": false, "audio_2.m3u8": true} {
+		if got := transcoded(playlist); got != want {
+			t.Errorf("%s transcoded = %v, want %v", playlist, got, want)
+		}
+	}
+
+	// Without the option, everything is transcoded
+	transcode()
+	for _, playlist := range []string{"index.m3u8", "audio_1.m3u8", "audio_2.m3u8"} {
+		if !transcoded(playlist) {
+			t.Errorf("%s not transcoded, want it transcoded without WithResume", playlist)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package transcoder
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// parseVideoScale parses a "width:height" scale.
+func parseVideoScale(videoScale string) (width, height int, err error) {
+	w, h, ok := strings.Cut(videoScale, ":")
+	if !ok {
+		return 0, 0, fmt.Errorf("invalid scale %q", videoScale)
+	}
+	if width, err = strconv.Atoi(w); err != nil {
+		return 0, 0, fmt.Errorf("invalid scale %q: %w", videoScale, err)
+	}
+	if height, err = strconv.Atoi(h); err != nil {
+		return 0, 0, fmt.Errorf("invalid scale %q: %w", videoScale, err)
+	}
+	if width <= 0 || height <= 0 {
+		return 0, 0, fmt.Errorf("invalid scale %q", videoScale)
+	}
+	return width, height, nil
+}
+
+// capVideoScale lowers a "width:height" scale so that it never exceeds the source resolution,
+// keeping the aspect ratio of the scale. Upscaling only wastes bits and produces a soft picture.
+func capVideoScale(videoScale string, sourceWidth, sourceHeight int) string {
+	width, height, err := parseVideoScale(videoScale)
+	if err != nil || sourceWidth <= 0 || sourceHeight <= 0 {
+		return videoScale
+	}
+	if width <= sourceWidth && height <= sourceHeight {
+		return videoScale
+	}
+	factor := math.Min(float64(sourceWidth)/float64(width), float64(sourceHeight)/float64(height))
+	capped := fmt.Sprintf("%d:%d", evenDimension(float64(width)*factor), evenDimension(float64(height)*factor))
+	logger.Infof("La source (%dx%d) est plus petite que la résolution %s, la résolution %s sera utilisée", sourceWidth, sourceHeight, videoScale, capped)
+	return capped
+}
+
+// evenDimension rounds a dimension down to an even number of pixels, as required by yuv420p.
+func evenDimension(dimension float64) int {
+	even := int(dimension) &^ 1
+	if even < 2 {
+		return 2
+	}
+	return even
+}
+
+// videoAspectRatio returns the display aspect ratio of the input video. The display_aspect_ratio
+// reported by ffprobe is used when it is valid; otherwise (missing, "N/A" or "0:1", as seen on some
+// anamorphic sources) it is computed from the frame dimensions and the sample aspect ratio.
+// It falls back to 16:9 when neither can be read.
+func videoAspectRatio(inputFile, displayAspectRatio string) float64 {
+	if ratio, ok := parseRatio(displayAspectRatio); ok {
+		return ratio
+	}
+	logger.Warnf("Ratio d'affichage de la vidéo inexploitable (%q), calcul à partir des dimensions", displayAspectRatio)
+	stream, err := probeVideoStream(inputFile)
+	if err != nil {
+		logger.Warnf("Erreur lors de la récupération du ratio de la vidéo : %v", err)
+	} else if ratio, ok := effectiveAspectRatio(stream); ok {
+		return ratio
+	}
+	logger.Warnf("Le ratio par défaut 16:9 sera utilisé")
+	return 16.0 / 9.0
+}
+
+// effectiveAspectRatio computes the display aspect ratio of a video stream from its dimensions and
+// its sample (pixel) aspect ratio, square pixels being assumed when the latter is unknown.
+func effectiveAspectRatio(stream *ffprobeStream) (float64, bool) {
+	width, height := stream.Width, stream.Height
+	if width == 0 || height == 0 {
+		width, height = stream.CodedWidth, stream.CodedHeight
+	}
+	if width == 0 || height == 0 {
+		return 0, false
+	}
+	sampleAspect, ok := parseRatio(stream.SampleAspectRatio)
+	if !ok {
+		sampleAspect = 1
+	}
+	return float64(width) * sampleAspect / float64(height), true
+}
+
+// parseRatio parses a "x:y" ratio as reported by ffprobe, rejecting null or missing ratios.
+func parseRatio(ratio string) (float64, bool) {
+	x, y, ok := strings.Cut(ratio, ":")
+	if !ok {
+		return 0, false
+	}
+	ratioX, err := strconv.ParseFloat(x, 64)
+	if err != nil || ratioX <= 0 {
+		return 0, false
+	}
+	ratioY, err := strconv.ParseFloat(y, 64)
+	if err != nil || ratioY <= 0 {
+		return 0, false
+	}
+	return ratioX / ratioY, true
+}
@@ -0,0 +1,155 @@
+package transcoder
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestParseVideoScale(t *testing.T) {
+	tests := []struct {
+		scale         string
+		width, height int
+		wantErr       bool
+	}{
+		{scale: "1280:720", width: 1280, height: 720},
+		{scale: "1920:816", width: 1920, height: 816},
+		{scale: "1280x720", wantErr: true},
+		{scale: "1280:abc", wantErr: true},
+		{scale: "-1:720", wantErr: true},
+		{scale: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.scale, func(t *testing.T) {
+			width, height, err := parseVideoScale(tt.scale)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseVideoScale(%q) error = %v, wantErr %v", tt.scale, err, tt.wantErr)
+			}
+			if width != tt.width || height != tt.height {
+				t.Errorf("parseVideoScale(%q) = %d, %d, want %d, %d", tt.scale, width, height, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+func TestCapVideoScale(t *testing.T) {
+	tests := []struct {
+		name                      string
+		scale                     string
+		sourceWidth, sourceHeight int
+		want                      string
+	}{
+		{name: "larger source", scale: "1280:720", sourceWidth: 1920, sourceHeight: 1080, want: "1280:720"},
+		{name: "same size", scale: "1280:720", sourceWidth: 1280, sourceHeight: 720, want: "1280:720"},
+		{name: "SD source", scale: "1280:720", sourceWidth: 720, sourceHeight: 404, want: "718:404"},
+		{name: "narrow source", scale: "1920:816", sourceWidth: 960, sourceHeight: 720, want: "960:408"},
+		{name: "odd dimensions", scale: "1280:720", sourceWidth: 853, sourceHeight: 480, want: "852:478"},
+		{name: "unknown source", scale: "1280:720", want: "1280:720"},
+		{name: "invalid scale", scale: "1280x720", sourceWidth: 640, sourceHeight: 360, want: "1280x720"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := capVideoScale(tt.scale, tt.sourceWidth, tt.sourceHeight); got != tt.want {
+				t.Errorf("capVideoScale(%q, %d, %d) = %q, want %q", tt.scale, tt.sourceWidth, tt.sourceHeight, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeCapsScale(t *testing.T) {
+	ft := newFakeTools(t, append(sampleMovieProbes(), fakeProbe{
+		entries: "stream=index,codec_name,codec_type,width,height,coded_width,coded_height,sample_aspect_ratio",
+		output:  `{"streams": [{"index": 0, "codec_name": "h264", "codec_type": "video", "width": 640, "height": 360}]}`,
+	})...)
+
+	if _, _, err := transcodeSample(t); err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	if call := ft.ffmpegCall(t, "index.m3u8"); !strings.Contains(call, "[1:v:0]scale=640:360,") {
+		t.Errorf("video ffmpeg run = %q, want the source scaled to 640:360", call)
+	}
+}
+
+func TestParseRatio(t *testing.T) {
+	tests := []struct {
+		ratio string
+		want  float64
+		ok    bool
+	}{
+		{"16:9", 16.0 / 9.0, true},
+		{"64:27", 64.0 / 27.0, true},
+		{"4:3", 4.0 / 3.0, true},
+		{"", 0, false},
+		{"N/A", 0, false},
+		{"0:1", 0, false},
+		{"16:0", 0, false},
+		{"16", 0, false},
+		{"a:b", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ratio, func(t *testing.T) {
+			got, ok := parseRatio(tt.ratio)
+			if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("parseRatio(%q) = %v, %v, want %v, %v", tt.ratio, got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestEffectiveAspectRatio(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream ffprobeStream
+		want   float64
+		ok     bool
+	}{
+		{"square pixels", ffprobeStream{Width: 1920, Height: 1080}, 16.0 / 9.0, true},
+		{"anamorphic", ffprobeStream{Width: 720, Height: 576, SampleAspectRatio: "64:45"}, 16.0 / 9.0, true},
+		{"null sample ratio", ffprobeStream{Width: 1920, Height: 800, SampleAspectRatio: "0:1"}, 2.4, true},
+		{"coded dimensions", ffprobeStream{CodedWidth: 1280, CodedHeight: 720}, 16.0 / 9.0, true},
+		{"display over coded dimensions", ffprobeStream{Width: 1920, Height: 1080, CodedWidth: 1920, CodedHeight: 1088}, 16.0 / 9.0, true},
+		{"no dimensions", ffprobeStream{}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := effectiveAspectRatio(&tt.stream)
+			if ok != tt.ok || math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("effectiveAspectRatio() = %v, %v, want %v, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeAspectRatioFromDimensions(t *testing.T) {
+	ft := newFakeTools(t,
+		fakeProbe{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,h264,video,0:1\n1,aac,audio"},
+		fakeProbe{entries: "format=duration", output: "5.000000"},
+		fakeProbe{
+			entries: "stream=index,codec_name,codec_type,width,height,coded_width,coded_height,sample_aspect_ratio",
+			output:  `{"streams": [{"index": 0, "codec_name": "h264", "codec_type": "video", "width": 1920, "height": 800, "sample_aspect_ratio": "1:1"}]}`,
+		},
+	)
+
+	if _, _, err := transcodeSample(t); err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	if call := ft.ffmpegCall(t, "index.m3u8"); !strings.Contains(call, "intro_21-9.mkv") {
+		t.Errorf("video ffmpeg run = %q, want the 21:9 intro of a 2.4:1 source", call)
+	}
+}
+
+func TestVideoAspectRatioFallback(t *testing.T) {
+	// ffprobe knows neither the display aspect ratio nor the frame dimensions
+	newFakeTools(t)
+
+	for _, displayAspectRatio := range []string{"", "N/A", "0:1"} {
+		ratio := videoAspectRatio("movie.mkv", displayAspectRatio)
+		if ratio != 16.0/9.0 {
+			t.Errorf("videoAspectRatio(%q) = %v, want the 16:9 fallback", displayAspectRatio, ratio)
+		}
+		bucket := selectAspectBucket(newTranscodeOptions(nil).aspectBuckets("1280:720", "intro.mkv", "1920:816", "intro_21-9.mkv"), ratio)
+		if bucket.videoScale != "1280:720" {
+			t.Errorf("ratio %v selects the %s scale, want the 16:9 one", ratio, bucket.videoScale)
+		}
+	}
+}
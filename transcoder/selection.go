@@ -0,0 +1,90 @@
+package transcoder
+
+import (
+	"fmt"
+	"os/exec"
+	"strconv"
+)
+
+// matchesTrackSelector reports whether a stream matches one of the selectors, either by its index
+// or by its language, any ISO 639 variant of the language matching ("fre", "fra" or "fr").
+func matchesTrackSelector(index, language string, selectors []string) bool {
+	languageCode, _ := NormalizeLanguage(language)
+	for _, selector := range selectors {
+		if selector == index {
+			return true
+		}
+		if selectorCode, _ := NormalizeLanguage(selector); language != "" && selectorCode == languageCode {
+			return true
+		}
+	}
+	return false
+}
+
+// probeAudioLanguages returns the language tag of each audio stream, keyed by stream index.
+func probeAudioLanguages(inputFile string) (map[string]string, error) {
+	cmd := exec.Command(ffprobePath,
+		"-v", "error",
+		"-select_streams", "a",
+		"-show_entries", "stream=index,codec_name,codec_type:stream_tags=language",
+		"-of", "json",
+		inputFile,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute command: %w", err)
+	}
+	probe, err := parseFFprobeOutput(output)
+	if err != nil {
+		return nil, err
+	}
+	languages := make(map[string]string, len(probe.Streams))
+	for _, stream := range probe.Streams {
+		languages[strconv.Itoa(stream.Index)] = stream.Tags["language"]
+	}
+	return languages, nil
+}
+
+// selectAudioStreams keeps the audio streams matching the selectors.
+// All the streams are kept when there is no selector or when none of them matches.
+func selectAudioStreams(inputFile string, audioStreams []string, selectors []string) []string {
+	if len(selectors) == 0 || len(audioStreams) == 0 {
+		return audioStreams
+	}
+	languages, err := probeAudioLanguages(inputFile)
+	if err != nil {
+		logger.Warnf("Impossible de récupérer les langues des pistes audio : %v", err)
+	}
+	var selected []string
+	for _, stream := range audioStreams {
+		if matchesTrackSelector(stream, languages[stream], selectors) {
+			selected = append(selected, stream)
+		}
+	}
+	if len(selected) == 0 {
+		logger.Warnf("Aucune piste audio ne correspond à la sélection %v, toutes les pistes seront transcodées", selectors)
+		return audioStreams
+	}
+	logger.Infof("Pistes audio sélectionnées : %v", selected)
+	return selected
+}
+
+// selectSubtitleStreams keeps the subtitle streams matching the selectors.
+// All the streams are kept when there is no selector or when none of them matches.
+func selectSubtitleStreams(subtitleStreams []subtitleStream, selectors []string) []subtitleStream {
+	if len(selectors) == 0 || len(subtitleStreams) == 0 {
+		return subtitleStreams
+	}
+	var selected []subtitleStream
+	for _, stream := range subtitleStreams {
+		if matchesTrackSelector(stream.index, stream.language, selectors) {
+			selected = append(selected, stream)
+		}
+	}
+	if len(selected) == 0 {
+		logger.Warnf("Aucune piste de sous-titres ne correspond à la sélection %v, toutes les pistes seront extraites", selectors)
+		return subtitleStreams
+	}
+	logger.Infof("Pistes de sous-titres sélectionnées : %v", selected)
+	return selected
+}
@@ -0,0 +1,91 @@
+package transcoder
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMatchesTrackSelector(t *testing.T) {
+	tests := []struct {
+		name            string
+		index, language string
+		selectors       []string
+		want            bool
+	}{
+		{name: "index", index: "2", language: "eng", selectors: []string{"2"}, want: true},
+		{name: "language", index: "2", language: "eng", selectors: []string{"fre", "ENG"}, want: true},
+		{name: "language variant", index: "2", language: "fre", selectors: []string{"fr"}, want: true},
+		{name: "region subtag", index: "2", language: "fr", selectors: []string{"fra-CA"}, want: true},
+		{name: "no match", index: "2", language: "eng", selectors: []string{"1", "fre"}, want: false},
+		{name: "no language", index: "2", selectors: []string{""}, want: false},
+		{name: "no selector", index: "2", language: "eng", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := matchesTrackSelector(tt.index, tt.language, tt.selectors); got != tt.want {
+				t.Errorf("matchesTrackSelector(%q, %q, %v) = %v, want %v", tt.index, tt.language, tt.selectors, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeTrackSelection(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          []TranscodeOption
+		wantAudios    []AudioTranscodeResponse
+		wantSubtitles []string
+	}{
+		{
+			name:          "no selection",
+			wantAudios:    []AudioTranscodeResponse{{AudioIndex: "audio_1.m3u8"}, {AudioIndex: "audio_2.m3u8"}},
+			wantSubtitles: []string{"subtitle_3.vtt", "subtitle_4.vtt", "subtitle_5.vtt", "subtitle_6.vtt"},
+		},
+		{
+			name:          "by language",
+			opts:          []TranscodeOption{WithAudioTracks("fre"), WithSubtitleTracks("eng")},
+			wantAudios:    []AudioTranscodeResponse{{AudioIndex: "audio_1.m3u8"}},
+			wantSubtitles: []string{"subtitle_5.vtt"},
+		},
+		{
+			name:          "by index",
+			opts:          []TranscodeOption{WithAudioTracks("2"), WithSubtitleTracks("3", "6")},
+			wantAudios:    []AudioTranscodeResponse{{AudioIndex: "audio_2.m3u8"}},
+			wantSubtitles: []string{"subtitle_3.vtt", "subtitle_6.vtt"},
+		},
+		{
+			name:          "no match keeps every track",
+			opts:          []TranscodeOption{WithAudioTracks("jpn"), WithSubtitleTracks("jpn")},
+			wantAudios:    []AudioTranscodeResponse{{AudioIndex: "audio_1.m3u8"}, {AudioIndex: "audio_2.m3u8"}},
+			wantSubtitles: []string{"subtitle_3.vtt", "subtitle_4.vtt", "subtitle_5.vtt", "subtitle_6.vtt"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newFakeTools(t,
+				fakeProbe{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,h264,video,16:9\n1,aac,audio\n2,ac3,audio\n3,subrip,subtitle\n4,subrip,subtitle\n5,subrip,subtitle\n6,subrip,subtitle"},
+				fakeProbe{entries: "stream=index,codec_name,codec_type:stream_disposition=forced,hearing_impaired:stream_tags=language", output: sampleSubtitleProbe},
+				fakeProbe{entries: "stream=index,codec_name,codec_type:stream_tags=language", output: `{"streams": [
+					{"index": 1, "codec_name": "aac", "codec_type": "audio", "tags": {"language": "fre"}},
+					{"index": 2, "codec_name": "ac3", "codec_type": "audio", "tags": {"language": "eng"}}
+				]}`},
+				fakeProbe{entries: "format=duration", output: "5.000000"},
+			)
+
+			response, _, err := transcodeSample(t, tt.opts...)
+			if err != nil {
+				t.Fatalf("ProcessFileTranscode() = %v", err)
+			}
+			if !reflect.DeepEqual(response.Audios, tt.wantAudios) {
+				t.Errorf("Audios = %+v, want %+v", response.Audios, tt.wantAudios)
+			}
+			var subtitles []string
+			for _, subtitle := range response.Subtitles {
+				subtitles = append(subtitles, subtitle.SubtitleIndex)
+			}
+			if !reflect.DeepEqual(subtitles, tt.wantSubtitles) {
+				t.Errorf("Subtitles = %v, want %v", subtitles, tt.wantSubtitles)
+			}
+		})
+	}
+}
@@ -0,0 +1,166 @@
+package transcoder
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"github.com/asticode/go-astisub"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ErrInvalidWebVTT is returned when a generated subtitle file is not valid WebVTT.
+var ErrInvalidWebVTT = errors.New("invalid WebVTT file")
+
+var (
+	utf8BOM           = []byte{0xEF, 0xBB, 0xBF}
+	webVTTTimingRegex = regexp.MustCompile(`^(\d+:)?\d{2}:\d{2}\.\d{3} --> (\d+:)?\d{2}:\d{2}\.\d{3}( .*)?$`)
+)
+
+// isASSCodec reports whether the subtitle codec is ASS/SSA, whose styling is flattened by a plain WebVTT extraction.
+func isASSCodec(codecName string) bool {
+	return codecName == "ass" || codecName == "ssa"
+}
+
+// convertASSToWebVTT converts an ASS/SSA subtitle file to WebVTT, shifting its timecodes by the given duration.
+// Alignment, italics and colors are mapped to WebVTT cue settings where WebVTT has an equivalent.
+func convertASSToWebVTT(assFile, vttFile string, duration time.Duration) error {
+	subs, err := astisub.OpenFile(assFile)
+	if err != nil {
+		return fmt.Errorf("failed to open subtitle file: %w", err)
+	}
+
+	for _, item := range subs.Items {
+		applyASSStyling(item)
+	}
+	shiftSubtitles(subs, duration)
+
+	if err := subs.Write(vttFile); err != nil {
+		return fmt.Errorf("failed to write converted subtitle file: %w", err)
+	}
+	if err := os.Remove(assFile); err != nil {
+		return fmt.Errorf("failed to remove intermediate subtitle file: %w", err)
+	}
+	return nil
+}
+
+// shiftSubtitles delays every cue by the intro duration so that cues line up with the content once
+// the intro is prepended. A cue starting before the end of the intro (e.g. a negative timestamp in the
+// source) is clamped to the end of the intro, and a cue ending within the intro is dropped, so that no
+// cue ever shows over the intro. Every subtitle output goes through this function, so the same offset
+// applies whatever the subtitle codec or output layout.
+func shiftSubtitles(subs *astisub.Subtitles, introDuration time.Duration) {
+	subs.Add(introDuration)
+	items := subs.Items[:0]
+	for _, item := range subs.Items {
+		if item.EndAt <= introDuration {
+			continue
+		}
+		if item.StartAt < introDuration {
+			item.StartAt = introDuration
+		}
+		items = append(items, item)
+	}
+	subs.Items = items
+}
+
+// applyASSStyling copies the SSA style of an item into the WebVTT attributes read by the WebVTT writer.
+func applyASSStyling(item *astisub.Item) {
+	if item.Style == nil || item.Style.InlineStyle == nil {
+		return
+	}
+	style := item.Style.InlineStyle
+	if item.InlineStyle == nil {
+		item.InlineStyle = &astisub.StyleAttributes{}
+	}
+
+	if style.SSAAlignment != nil {
+		// SSA alignments follow the numpad layout: 1-3 bottom, 4-6 middle, 7-9 top
+		alignment := *style.SSAAlignment
+		switch alignment % 3 {
+		case 1:
+			item.InlineStyle.WebVTTAlign = "left"
+		case 2:
+			item.InlineStyle.WebVTTAlign = "center"
+		case 0:
+			item.InlineStyle.WebVTTAlign = "right"
+		}
+		switch {
+		case alignment >= 7:
+			item.InlineStyle.WebVTTLine = "0%"
+		case alignment >= 4:
+			item.InlineStyle.WebVTTLine = "50%"
+		}
+	}
+
+	italic := style.SSAItalic != nil && *style.SSAItalic
+	var color *string
+	if style.SSAPrimaryColour != nil {
+		c := "#" + style.SSAPrimaryColour.TTMLString()
+		color = &c
+	}
+	if !italic && color == nil {
+		return
+	}
+	for i := range item.Lines {
+		for j := range item.Lines[i].Items {
+			lineItem := &item.Lines[i].Items[j]
+			if lineItem.InlineStyle == nil {
+				lineItem.InlineStyle = &astisub.StyleAttributes{}
+			}
+			if italic {
+				lineItem.InlineStyle.WebVTTItalics = true
+			}
+			if color != nil && lineItem.InlineStyle.TTMLColor == nil {
+				lineItem.InlineStyle.TTMLColor = color
+			}
+		}
+	}
+}
+
+// normalizeWebVTT rewrites a WebVTT file so that players accept it: the UTF-8 BOM is stripped,
+// line endings are converted to \n and the "WEBVTT" header is added when missing.
+// An ErrInvalidWebVTT error is returned if a cue timing line is still malformed.
+func normalizeWebVTT(subtitleFile string) error {
+	data, err := os.ReadFile(subtitleFile)
+	if err != nil {
+		return fmt.Errorf("failed to read subtitle file: %w", err)
+	}
+
+	data = bytes.TrimPrefix(data, utf8BOM)
+	data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	data = bytes.ReplaceAll(data, []byte("\r"), []byte("\n"))
+	if !hasWebVTTHeader(data) {
+		data = append([]byte("WEBVTT\n\n"), data...)
+	}
+
+	if err := validateWebVTT(data); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(subtitleFile, data, 0644); err != nil {
+		return fmt.Errorf("failed to write subtitle file: %w", err)
+	}
+	return nil
+}
+
+// hasWebVTTHeader reports whether the data starts with the "WEBVTT" signature line.
+func hasWebVTTHeader(data []byte) bool {
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	return firstLine == "WEBVTT" || strings.HasPrefix(firstLine, "WEBVTT ") || strings.HasPrefix(firstLine, "WEBVTT\t")
+}
+
+// validateWebVTT checks the header and the cue timing lines of a WebVTT file.
+func validateWebVTT(data []byte) error {
+	if !hasWebVTTHeader(data) {
+		return fmt.Errorf("%w: missing WEBVTT header", ErrInvalidWebVTT)
+	}
+	for i, line := range strings.Split(string(data), "\n") {
+		if strings.Contains(line, "-->") && !webVTTTimingRegex.MatchString(strings.TrimSpace(line)) {
+			return fmt.Errorf("%w: malformed cue timing on line %d: %q", ErrInvalidWebVTT, i+1, line)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,244 @@
+package transcoder
+
+import (
+	"errors"
+	"github.com/asticode/go-astisub"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleASS = `[Script Info]
+ScriptType: v4.00+
+PlayResX: 1280
+PlayResY: 720
+
+[V4+ Styles]
+Format: Name, Fontname, Fontsize, PrimaryColour, SecondaryColour, OutlineColour, BackColour, Bold, Italic, Underline, StrikeOut, ScaleX, ScaleY, Spacing, Angle, BorderStyle, Outline, Shadow, Alignment, MarginL, MarginR, MarginV, Encoding
+Style: Default,Arial,48,&H00FFFFFF,&H000000FF,&H00000000,&H00000000,0,0,0,0,100,100,0,0,1,2,0,2,10,10,10,1
+Style: Sign,Arial,48,&H0000FFFF,&H000000FF,&H00000000,&H00000000,0,-1,0,0,100,100,0,0,1,2,0,8,10,10,10,1
+
+[Events]
+Format: Layer, Start, End, Style, Name, MarginL, MarginR, MarginV, Effect, Text
+Dialogue: 0,0:00:01.00,0:00:03.50,Default,,0,0,0,,Bonjour tout le monde
+Dialogue: 0,0:00:05.00,0:00:06.00,Sign,,0,0,0,,Panneau
+`
+
+func TestConvertASSToWebVTT(t *testing.T) {
+	dir := t.TempDir()
+	assFile := filepath.Join(dir, "subtitle_3.ass")
+	vttFile := filepath.Join(dir, "subtitle_3.vtt")
+	if err := os.WriteFile(assFile, []byte(sampleASS), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := convertASSToWebVTT(assFile, vttFile, 10*time.Second); err != nil {
+		t.Fatalf("convertASSToWebVTT() = %v", err)
+	}
+
+	data, err := os.ReadFile(vttFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vtt := string(data)
+	for _, want := range []string{
+		"WEBVTT",
+		"00:00:11.000 --> 00:00:13.500",
+		"Bonjour tout le monde",
+		"00:00:15.000 --> 00:00:16.000",
+		"<i>Panneau</i>",
+		"align:center line:0%",
+	} {
+		if !strings.Contains(vtt, want) {
+			t.Errorf("converted subtitle misses %q:\n%s", want, vtt)
+		}
+	}
+	if _, err := os.Stat(assFile); !os.IsNotExist(err) {
+		t.Errorf("intermediate ASS file left behind: %v", err)
+	}
+}
+
+func TestShiftSubtitles(t *testing.T) {
+	const intro = 5 * time.Second
+	type cue struct{ start, end time.Duration }
+	tests := []struct {
+		name string
+		cues []cue
+		want []cue
+	}{
+		{
+			name: "after the intro",
+			cues: []cue{{time.Second, 3 * time.Second}},
+			want: []cue{{6 * time.Second, 8 * time.Second}},
+		},
+		{
+			name: "starts inside the intro",
+			cues: []cue{{-time.Second, 2 * time.Second}},
+			want: []cue{{intro, 7 * time.Second}},
+		},
+		{
+			name: "ends inside the intro",
+			cues: []cue{{-3 * time.Second, -time.Second}, {time.Second, 2 * time.Second}},
+			want: []cue{{6 * time.Second, 7 * time.Second}},
+		},
+		{
+			name: "ends with the intro",
+			cues: []cue{{-2 * time.Second, 0}},
+			want: []cue{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subs := astisub.NewSubtitles()
+			for _, c := range tt.cues {
+				subs.Items = append(subs.Items, &astisub.Item{StartAt: c.start, EndAt: c.end})
+			}
+
+			shiftSubtitles(subs, intro)
+
+			got := []cue{}
+			for _, item := range subs.Items {
+				if item.StartAt < intro {
+					t.Errorf("cue %s --> %s overlaps the %s intro", item.StartAt, item.EndAt, intro)
+				}
+				got = append(got, cue{item.StartAt, item.EndAt})
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("cues = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeASSSubtitles(t *testing.T) {
+	probes := []fakeProbe{
+		{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,h264,video,16:9\n1,aac,audio\n2,ass,subtitle"},
+		{entries: "format=duration", output: "5.000000"},
+	}
+
+	t.Run("preserved", func(t *testing.T) {
+		ft := newFakeTools(t, probes...)
+		if err := os.WriteFile(filepath.Join(ft.dir, "fixture.ass"), []byte(sampleASS), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		response, folder, err := transcodeSample(t, WithASSStyling())
+		if err != nil {
+			t.Fatalf("ProcessFileTranscode() = %v", err)
+		}
+		if call := ft.ffmpegCall(t, "subtitle_2"); !strings.Contains(call, "-map 0:2 -c:s copy") {
+			t.Errorf("ASS stream not copied as-is: %s", call)
+		}
+		data, err := os.ReadFile(filepath.Join(folder, response.Subtitles[0].SubtitleIndex))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), "00:00:06.000 --> 00:00:08.500 align:center") {
+			t.Errorf("converted subtitle not shifted by the intro or unstyled:\n%s", data)
+		}
+	})
+
+	t.Run("flattened", func(t *testing.T) {
+		ft := newFakeTools(t, probes...)
+		if _, _, err := transcodeSample(t); err != nil {
+			t.Fatalf("ProcessFileTranscode() = %v", err)
+		}
+		if call := ft.ffmpegCall(t, "subtitle_2"); strings.Contains(call, "-c:s copy") || !strings.HasSuffix(call, "subtitle_2.vtt") {
+			t.Errorf("ASS stream not extracted to WebVTT by ffmpeg: %s", call)
+		}
+	})
+}
+
+func TestNormalizeWebVTT(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    string
+		wantErr bool
+	}{
+		{
+			name:  "header lost",
+			input: "1\n00:00:06.000 --> 00:00:07.000\nBonjour\n",
+			want:  "WEBVTT\n\n1\n00:00:06.000 --> 00:00:07.000\nBonjour\n",
+		},
+		{
+			name:  "BOM and CRLF",
+			input: "\xEF\xBB\xBFWEBVTT\r\n\r\n00:00:06.000 --> 00:00:07.000\r\nBonjour\r\n",
+			want:  "WEBVTT\n\n00:00:06.000 --> 00:00:07.000\nBonjour\n",
+		},
+		{
+			name:  "hours and cue settings",
+			input: "WEBVTT - sous-titres\n\n01:00:06.000 --> 01:00:07.000 align:center line:0%\nBonjour\n",
+			want:  "WEBVTT - sous-titres\n\n01:00:06.000 --> 01:00:07.000 align:center line:0%\nBonjour\n",
+		},
+		{
+			name:    "malformed timing",
+			input:   "WEBVTT\n\n00:00:06,000 --> 00:00:07,000\nBonjour\n",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "subtitle.vtt")
+			if err := os.WriteFile(file, []byte(tt.input), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			err := normalizeWebVTT(file)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidWebVTT) {
+					t.Errorf("normalizeWebVTT() = %v, want ErrInvalidWebVTT", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("normalizeWebVTT() = %v", err)
+			}
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("normalized file = %q, want %q", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeTempDir(t *testing.T) {
+	ft := newFakeTools(t,
+		fakeProbe{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,h264,video,16:9\n1,aac,audio\n2,ass,subtitle"},
+		fakeProbe{entries: "format=duration", output: "5.000000"},
+	)
+	if err := os.WriteFile(filepath.Join(ft.dir, "fixture.ass"), []byte(sampleASS), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tempDir := filepath.Join(t.TempDir(), "scratch")
+
+	response, folder, err := transcodeSample(t, WithASSStyling(), WithTempDir(tempDir))
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	// The ASS extract goes to the scratch folder and the playlists to the staged work folder
+	for _, substr := range []string{"subtitle_2.ass", "index.m3u8"} {
+		if call := ft.ffmpegCall(t, substr); !strings.Contains(call, " "+tempDir+string(filepath.Separator)) {
+			t.Errorf("ffmpeg run = %q, want its output in %s", call, tempDir)
+		}
+	}
+	for _, name := range []string{"index.m3u8", "audio_1.m3u8", response.Subtitles[0].SubtitleIndex} {
+		if _, err := os.Stat(filepath.Join(folder, name)); err != nil {
+			t.Errorf("%s not published: %v", name, err)
+		}
+	}
+	if matches, _ := filepath.Glob(filepath.Join(folder, "*.ass")); len(matches) != 0 {
+		t.Errorf("intermediate files published: %v", matches)
+	}
+	if entries, err := os.ReadDir(tempDir); err != nil || len(entries) != 0 {
+		t.Errorf("temp dir holds %v (%v) after the transcode, want it empty", entries, err)
+	}
+	if entries, _ := os.ReadDir(filepath.Dir(folder)); len(entries) != 1 {
+		t.Errorf("output folder holds %v, want only the media folder", entries)
+	}
+}
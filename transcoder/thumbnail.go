@@ -0,0 +1,86 @@
+package transcoder
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// ThumbnailFilename is the name of the thumbnail written in the output folder when enabled.
+const ThumbnailFilename = "thumbnail.jpg"
+
+// defaultThumbnailRatio is the position of the thumbnail frame, as a fraction of the video duration,
+// used when no explicit offset is given.
+const defaultThumbnailRatio = 0.1
+
+// extractThumbnail writes a single frame of the input file as a JPEG into the output folder.
+// A zero offset picks the frame at 10% of the video duration.
+func extractThumbnail(inputFile, outputFolder string, offset time.Duration) (string, error) {
+	if offset <= 0 {
+		duration, err := getVideoDuration(inputFile)
+		if err != nil {
+			return "", fmt.Errorf("failed to get video duration: %w", err)
+		}
+		offset = time.Duration(float64(duration) * defaultThumbnailRatio)
+	}
+
+	outputFile := filepath.Join(outputFolder, ThumbnailFilename)
+	cmd := exec.Command(ffmpegPath,
+		"-ss", fmt.Sprintf("%.3f", offset.Seconds()),
+		"-i", inputFile,
+		"-frames:v", "1",
+		"-q:v", "2",
+		"-y",
+		outputFile,
+	)
+	logger.Debugf("Commande ffmpeg : %s", cmd.String())
+	if err := cmd.Run(); err != nil {
+		os.Remove(outputFile)
+		return "", fmt.Errorf("failed to execute command: %w", err)
+	}
+	logger.Infof("Miniature extraite : %s", ThumbnailFilename)
+	return ThumbnailFilename, nil
+}
+
+// ExtractAttachedPicture extracts the cover art embedded in the input file (an attached picture
+// stream, as found in some MKV and MP4 files) into outputImage, the image format being picked
+// from its extension. It returns false when the file has no embedded cover art.
+func ExtractAttachedPicture(inputFile, outputImage string) (bool, error) {
+	cmd := exec.Command(ffprobePath,
+		"-v", "error",
+		"-select_streams", "v",
+		"-show_entries", "stream=index,codec_name,codec_type:stream_disposition=attached_pic",
+		"-of", "json",
+		inputFile,
+	)
+	output, err := cmd.Output()
+	if err != nil {
+		return false, fmt.Errorf("failed to execute command: %w", err)
+	}
+	probe, err := parseFFprobeOutput(output)
+	if err != nil {
+		return false, err
+	}
+	index := findAttachedPicture(probe)
+	if index < 0 {
+		return false, nil
+	}
+
+	cmd = exec.Command(ffmpegPath,
+		"-i", inputFile,
+		"-map", "0:"+strconv.Itoa(index),
+		"-frames:v", "1",
+		"-y",
+		outputImage,
+	)
+	logger.Debugf("Commande ffmpeg : %s", cmd.String())
+	if err := cmd.Run(); err != nil {
+		os.Remove(outputImage)
+		return false, fmt.Errorf("failed to execute command: %w", err)
+	}
+	logger.Infof("Pochette extraite : %s", outputImage)
+	return true, nil
+}
@@ -0,0 +1,143 @@
+package transcoder
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProcessFileTranscodeThumbnail(t *testing.T) {
+	tests := []struct {
+		name   string
+		offset time.Duration
+		wantSS string
+	}{
+		{name: "default offset", wantSS: "-ss 0.500 "},
+		{name: "explicit offset", offset: 3 * time.Second, wantSS: "-ss 3.000 "},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := newFakeTools(t, sampleMovieProbes()...)
+
+			response, folder, err := transcodeSample(t, WithThumbnail(tt.offset))
+			if err != nil {
+				t.Fatalf("ProcessFileTranscode() = %v", err)
+			}
+			if response.Thumbnail != ThumbnailFilename {
+				t.Errorf("Thumbnail = %q, want %q", response.Thumbnail, ThumbnailFilename)
+			}
+			if _, err := os.Stat(filepath.Join(folder, ThumbnailFilename)); err != nil {
+				t.Errorf("thumbnail not published: %v", err)
+			}
+			call := ft.ffmpegCall(t, ThumbnailFilename)
+			if !strings.HasPrefix(call, tt.wantSS) || !strings.Contains(call, "-frames:v 1") {
+				t.Errorf("thumbnail ffmpeg run = %q, want it to start with %q and extract a single frame", call, tt.wantSS)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeThumbnailFailure(t *testing.T) {
+	ft := newFakeTools(t, sampleMovieProbes()...)
+	ft.writeFFmpeg(t, "*"+ThumbnailFilename)
+
+	response, folder, err := transcodeSample(t, WithThumbnail(0))
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v, want the failed thumbnail to be skipped", err)
+	}
+	if response.Thumbnail != "" {
+		t.Errorf("Thumbnail = %q, want none", response.Thumbnail)
+	}
+	if _, err := os.Stat(filepath.Join(folder, ThumbnailFilename)); !os.IsNotExist(err) {
+		t.Errorf("thumbnail stat = %v, want it not to exist", err)
+	}
+}
+
+func TestProcessFileTranscodeWithoutThumbnail(t *testing.T) {
+	ft := newFakeTools(t, sampleMovieProbes()...)
+
+	response, _, err := transcodeSample(t)
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	if response.Thumbnail != "" {
+		t.Errorf("Thumbnail = %q, want none", response.Thumbnail)
+	}
+	for _, call := range ft.ffmpegCalls(t) {
+		if strings.Contains(call, ThumbnailFilename) {
+			t.Errorf("ffmpeg run %q extracts a thumbnail, want none", call)
+		}
+	}
+}
+
+func TestExtractAttachedPicture(t *testing.T) {
+	tests := []struct {
+		name    string
+		probe   string
+		want    bool
+		wantMap string
+	}{
+		{
+			name: "cover art",
+			probe: `{"streams": [
+				{"index": 0, "codec_name": "h264", "codec_type": "video", "disposition": {"attached_pic": 0}},
+				{"index": 3, "codec_name": "mjpeg", "codec_type": "video", "disposition": {"attached_pic": 1}}
+			]}`,
+			want:    true,
+			wantMap: "-map 0:3 ",
+		},
+		{
+			name:  "no cover art",
+			probe: `{"streams": [{"index": 0, "codec_name": "h264", "codec_type": "video", "disposition": {"attached_pic": 0}}]}`,
+			want:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := newFakeTools(t, fakeProbe{
+				entries: "stream=index,codec_name,codec_type:stream_disposition=attached_pic",
+				output:  tt.probe,
+			})
+			output := filepath.Join(t.TempDir(), "cover.jpg")
+
+			got, err := ExtractAttachedPicture("movie.mkv", output)
+			if err != nil {
+				t.Fatalf("ExtractAttachedPicture() = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("ExtractAttachedPicture() = %v, want %v", got, tt.want)
+			}
+			calls := ft.ffmpegCalls(t)
+			if !tt.want {
+				if len(calls) != 0 {
+					t.Errorf("ffmpeg runs = %q, want none", calls)
+				}
+				return
+			}
+			if call := ft.ffmpegCall(t, output); !strings.Contains(call, tt.wantMap) {
+				t.Errorf("ffmpeg run = %q, want it to contain %q", call, tt.wantMap)
+			}
+			if _, err := os.Stat(output); err != nil {
+				t.Errorf("cover not written: %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractAttachedPictureFailure(t *testing.T) {
+	ft := newFakeTools(t, fakeProbe{
+		entries: "stream=index,codec_name,codec_type:stream_disposition=attached_pic",
+		output:  `{"streams": [{"index": 2, "codec_name": "png", "codec_type": "video", "disposition": {"attached_pic": 1}}]}`,
+	})
+	ft.writeFFmpeg(t, "*cover.jpg")
+	output := filepath.Join(t.TempDir(), "cover.jpg")
+
+	if got, err := ExtractAttachedPicture("movie.mkv", output); err == nil || got {
+		t.Errorf("ExtractAttachedPicture() = %v, %v, want an error", got, err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Errorf("cover stat = %v, want it not to exist", err)
+	}
+}
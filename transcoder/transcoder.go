@@ -1,9 +1,11 @@
 package transcoder
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/asticode/go-astisub"
-	"log"
+	"io/fs"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -13,42 +15,229 @@ import (
 	"time"
 )
 
+var (
+	// ErrNoVideoStream is returned when the input file has no video stream, e.g. an audio-only file.
+	ErrNoVideoStream = errors.New("no video stream found")
+	// ErrNoAudioStream is returned when the input file has no audio stream and WithRequireAudio is set.
+	ErrNoAudioStream = errors.New("no audio stream found")
+)
+
 type AudioTranscodeResponse struct {
 	AudioIndex string `json:"audio_index"`
 }
 
 type SubtitleTranscodeResponse struct {
-	SubtitleIndex string `json:"subtitle_index"`
+	SubtitleIndex   string `json:"subtitle_index"`
+	Language        string `json:"language"`
+	LanguageName    string `json:"language_name,omitempty"`
+	Forced          bool   `json:"forced"`
+	HearingImpaired bool   `json:"hearing_impaired"`
+}
+
+// ResponseSchemaVersion is the version of the TranscodeResponse JSON layout written to the sidecar file.
+const ResponseSchemaVersion = 1
+
+// ResponseSidecarFilename is the name of the sidecar file holding the TranscodeResponse in the output folder.
+const ResponseSidecarFilename = "response.json"
+
+type subtitleStream struct {
+	index           string
+	codecName       string
+	language        string
+	forced          bool
+	hearingImpaired bool
 }
 
 type TranscodeResponse struct {
-	VideoIndex string                      `json:"video_index"`
-	Audios     []AudioTranscodeResponse    `json:"audios"`
-	Subtitles  []SubtitleTranscodeResponse `json:"subtitles"`
+	SchemaVersion   int                         `json:"schema_version"`
+	VideoIndex      string                      `json:"video_index"`
+	Audios          []AudioTranscodeResponse    `json:"audios"`
+	Subtitles       []SubtitleTranscodeResponse `json:"subtitles"`
+	FailedSubtitles []string                    `json:"failed_subtitles"`
+	OutputSize      int64                       `json:"output_size"`
+	VideoBitrate    int                         `json:"video_bitrate"`
+	Thumbnail       string                      `json:"thumbnail,omitempty"`
+}
+
+// measureOutput returns the total size in bytes of the files in the output folder and the
+// average video bitrate in bits per second, computed from the video segment sizes and the
+// playlist duration.
+func measureOutput(outputFolder string) (outputSize int64, videoBitrate int, err error) {
+	files, err := os.ReadDir(outputFolder)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var videoSize int64
+	for _, f := range files {
+		info, err := f.Info()
+		if err != nil {
+			return 0, 0, err
+		}
+		outputSize += info.Size()
+		if strings.HasPrefix(f.Name(), "segment_") && strings.HasSuffix(f.Name(), ".ts") {
+			videoSize += info.Size()
+		}
+	}
+
+	duration, err := playlistDuration(filepath.Join(outputFolder, "index.m3u8"))
+	if err != nil {
+		return 0, 0, err
+	}
+	if duration > 0 {
+		videoBitrate = int(float64(videoSize*8) / duration.Seconds())
+	}
+	return outputSize, videoBitrate, nil
+}
+
+// playlistDuration sums the segment durations (#EXTINF tags) of an HLS playlist.
+func playlistDuration(playlistFile string) (time.Duration, error) {
+	data, err := os.ReadFile(playlistFile)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read playlist: %w", err)
+	}
+	var total float64
+	for _, line := range strings.Split(string(data), "\n") {
+		if !strings.HasPrefix(line, "#EXTINF:") {
+			continue
+		}
+		value, _, _ := strings.Cut(strings.TrimPrefix(line, "#EXTINF:"), ",")
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return 0, fmt.Errorf("failed to parse segment duration %q: %w", value, err)
+		}
+		total += seconds
+	}
+	return time.Duration(total * float64(time.Second)), nil
+}
+
+// writeResponseSidecar writes the response as JSON into the output folder.
+func writeResponseSidecar(outputFolder string, response TranscodeResponse) error {
+	data, err := json.MarshalIndent(response, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal transcode response: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(outputFolder, ResponseSidecarFilename), data, 0644); err != nil {
+		return fmt.Errorf("failed to write transcode response: %w", err)
+	}
+	return nil
 }
 
-func prepareOutputFolder(outputFolder string) error {
+// prepareOutputFolder creates a unique work folder next to the final output folder of the media,
+// or in stagingFolder when it is set.
+// Transcoding happens in this work folder so that concurrent or retried runs on the same media
+// never write into each other's files nor into a previously published output.
+func prepareOutputFolder(outputFolder, mediaID, stagingFolder string) (string, error) {
 	if err := os.MkdirAll(outputFolder, os.ModePerm); err != nil {
-		return fmt.Errorf("failed to create directory: %w", err)
+		return "", fmt.Errorf("failed to create directory: %w", err)
+	}
+	parentFolder := outputFolder
+	if stagingFolder != "" {
+		if err := os.MkdirAll(stagingFolder, os.ModePerm); err != nil {
+			return "", fmt.Errorf("failed to create staging directory: %w", err)
+		}
+		parentFolder = stagingFolder
 	}
 
-	files, err := os.ReadDir(outputFolder)
+	workFolder, err := os.MkdirTemp(parentFolder, "."+mediaID+".tmp-")
 	if err != nil {
-		return err
+		return "", fmt.Errorf("failed to create work directory: %w", err)
+	}
+	if err := os.Chmod(workFolder, defaultOutputPermissions); err != nil {
+		os.RemoveAll(workFolder)
+		return "", fmt.Errorf("failed to set work directory permissions: %w", err)
 	}
 
-	for _, f := range files {
-		if err := os.Remove(filepath.Join(outputFolder, f.Name())); err != nil {
+	return workFolder, nil
+}
+
+const (
+	// publishLockRetryDelay is the delay between two attempts to take the publish lock of an output folder.
+	publishLockRetryDelay = 50 * time.Millisecond
+	// publishLockStaleAge is the age after which a publish lock left behind by a crashed run is removed.
+	// Publishing only renames folders, so a live lock is never that old.
+	publishLockStaleAge = time.Minute
+)
+
+// publishOutputFolder moves a completed work folder into place as the final output folder.
+// The runs on the same media publish one at a time, under a lock file next to the output folder:
+// each of them replaces the output folder with its own complete output, so the last run to publish wins
+// and a failed run never leaves a partially written output folder behind.
+// Replacing an existing output folder takes two renames (the previous output is moved aside, then the new
+// one into place), so a reader can briefly find no output folder in between; it never sees a mix of two runs.
+// A work folder staged elsewhere is first copied next to the output folder, since a rename
+// is only atomic within a single file system.
+func publishOutputFolder(workFolder, outputFileFolder string) error {
+	if filepath.Dir(workFolder) != filepath.Dir(outputFileFolder) {
+		localFolder, err := copyNextToOutput(workFolder, outputFileFolder)
+		if err != nil {
+			return err
+		}
+		if err := publishOutputFolder(localFolder, outputFileFolder); err != nil {
+			os.RemoveAll(localFolder)
 			return err
 		}
+		if err := os.RemoveAll(workFolder); err != nil {
+			logger.Warnf("Impossible de supprimer le dossier de travail : %v", err)
+		}
+		return nil
+	}
+
+	unlock, err := lockOutputFolder(outputFileFolder)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	previousFolder := ""
+	if _, err := os.Stat(outputFileFolder); err == nil {
+		previousFolder = workFolder + ".old"
+		if err := os.Rename(outputFileFolder, previousFolder); err != nil {
+			return fmt.Errorf("failed to move previous output directory: %w", err)
+		}
+	}
+
+	if err := os.Rename(workFolder, outputFileFolder); err != nil {
+		if previousFolder != "" {
+			if restoreErr := os.Rename(previousFolder, outputFileFolder); restoreErr != nil {
+				logger.Errorf("Impossible de restaurer l'ancien dossier de sortie, il reste dans %s : %v", previousFolder, restoreErr)
+				return fmt.Errorf("failed to move work directory into place: %w (previous output left in %s: %v)", err, previousFolder, restoreErr)
+			}
+		}
+		return fmt.Errorf("failed to move work directory into place: %w", err)
 	}
 
+	if previousFolder != "" {
+		if err := os.RemoveAll(previousFolder); err != nil {
+			logger.Warnf("Impossible de supprimer l'ancien dossier de sortie : %v", err)
+		}
+	}
 	return nil
 }
 
-func extractStreamsInfo(inputFile string) (audioStreams, subtitleStreams []string, videoCodec string, aspectRatio string, err error) {
-	log.Println("Récupération des informations sur les pistes audio et sous-titres...")
-	cmd := exec.Command("ffprobe",
+// copyNextToOutput copies a staged work folder into a new work folder next to the output folder.
+func copyNextToOutput(workFolder, outputFileFolder string) (string, error) {
+	localFolder, err := prepareOutputFolder(filepath.Dir(outputFileFolder), filepath.Base(outputFileFolder), "")
+	if err != nil {
+		return "", err
+	}
+	files, err := os.ReadDir(workFolder)
+	if err != nil {
+		os.RemoveAll(localFolder)
+		return "", fmt.Errorf("failed to read work directory: %w", err)
+	}
+	for _, f := range files {
+		if err := linkOrCopyFile(filepath.Join(workFolder, f.Name()), filepath.Join(localFolder, f.Name())); err != nil {
+			os.RemoveAll(localFolder)
+			return "", fmt.Errorf("failed to copy %s next to the output directory: %w", f.Name(), err)
+		}
+	}
+	return localFolder, nil
+}
+
+func extractStreamsInfo(inputFile string) (audioStreams []string, subtitleStreams []subtitleStream, videoCodec string, aspectRatio string, err error) {
+	logger.Infof("Récupération des informations sur les pistes audio et sous-titres...")
+	cmd := exec.Command(ffprobePath,
 		"-v", "error",
 		"-show_entries", "stream=index,codec_name,codec_type,display_aspect_ratio",
 		"-of", "csv=p=0",
@@ -63,47 +252,80 @@ func extractStreamsInfo(inputFile string) (audioStreams, subtitleStreams []strin
 
 	for _, line := range ffprobeOutput {
 		fields := strings.Split(line, ",")
+		if len(fields) < 3 {
+			logger.Warnf("Ligne ffprobe ignorée : %q", line)
+			continue
+		}
 		streamIndex, codecName, codecType := fields[0], fields[1], fields[2]
 
 		switch codecType {
 		case "audio":
 			audioStreams = append(audioStreams, streamIndex)
 		case "subtitle":
-			log.Println("Piste de sous-titres trouvée :", streamIndex, codecName)
-			if codecName != "dvd_subtitle" && codecName != "hdmv_pgs_subtitle" {
-				subtitleStreams = append(subtitleStreams, streamIndex)
+			logger.Debugf("Piste de sous-titres trouvée : %s %s", streamIndex, codecName)
+			if !isBitmapSubtitleCodec(codecName) {
+				subtitleStreams = append(subtitleStreams, subtitleStream{index: streamIndex, codecName: codecName})
 			}
 		case "video":
 			if videoCodec != "" {
 				continue
 			}
 			videoCodec = codecName
-			aspectRatio = fields[3]
+			// The display aspect ratio is missing from the line when ffprobe does not report it,
+			// videoAspectRatio then computes it from the frame dimensions or falls back to 16:9.
+			if len(fields) > 3 {
+				aspectRatio = fields[3]
+			}
 		}
 	}
 
-	log.Println("Pistes audio trouvées :", audioStreams)
-	log.Println("Pistes de sous-titres trouvées :", subtitleStreams)
-	log.Println("Codec vidéo :", videoCodec)
+	if err := probeSubtitleMetadata(inputFile, subtitleStreams); err != nil {
+		logger.Warnf("Impossible de récupérer les métadonnées des sous-titres : %v", err)
+	}
+
+	logger.Infof("Pistes audio trouvées : %v", audioStreams)
+	logger.Infof("Pistes de sous-titres trouvées : %v", subtitleStreams)
+	logger.Infof("Codec vidéo : %s", videoCodec)
 
 	return audioStreams, subtitleStreams, videoCodec, aspectRatio, nil
 }
 
-func transcodeVideo(inputFile, outputFolder, chunkDuration, videoScale, introFile string) error {
-	log.Println("Début du transcodage en HLS...")
-	log.Println("Transcodage de la vidéo...")
+// hdrToneMapFilter converts HDR (PQ / HLG) frames to BT.709 SDR.
+const hdrToneMapFilter = "zscale=t=linear:npl=100,format=gbrpf32le,zscale=p=bt709,tonemap=tonemap=hable:desat=0,zscale=t=bt709:m=bt709:r=tv,"
+
+func transcodeVideo(inputFile, outputFolder, chunkDuration, videoScale, introFile string, toneMap bool, watermark *Watermark) error {
+	logger.Infof("Début du transcodage en HLS...")
+	logger.Infof("Transcodage de la vidéo...")
+
+	sourceFilter := ""
+	if toneMap {
+		sourceFilter = hdrToneMapFilter
+	}
+
+	filterComplex := fmt.Sprintf("[0:v:0]%s,format=yuv420p,setsar=sar=1/1[v0]; [1:v:0]%sscale=%s,format=yuv420p,setsar=sar=1/1[v1]; [v0][v1]concat=n=2:v=1", introScaleFilter(introFile, videoScale), sourceFilter, videoScale)
+	var watermarkInput []string
+	if watermark == nil {
+		filterComplex += "[outv]"
+	} else {
+		filterComplex += "[main]; " + watermarkFilter(watermark, "main", "outv", 2)
+		if watermark.ImagePath != "" {
+			watermarkInput = []string{"-i", watermark.ImagePath}
+		}
+	}
 
 	// Initialize common ffmpeg command arguments
-	ffmpegArgs := []string{
+	ffmpegArgs := append([]string{
 		"-fflags", "+genpts",
 		//"-r", "23.976",
 		"-i", introFile,
 		//"-r", "23.976",
 		"-i", inputFile,
-		"-filter_complex", fmt.Sprintf("[0:v:0]scale=%s,format=yuv420p,setsar=sar=1/1[v0]; [1:v:0]scale=%s,format=yuv420p,setsar=sar=1/1[v1]; [v0][v1]concat=n=2:v=1[outv]", videoScale, videoScale),
+	}, watermarkInput...)
+	ffmpegArgs = append(ffmpegArgs,
+		"-filter_complex", filterComplex,
 		"-map", "[outv]",
 		"-vsync", "2",
-		"-c:v", "libx264",
+		"-c:v", videoEncoder,
 		"-profile:v", "high",
 		"-level", "4.0",
 		"-crf", "25",
@@ -115,26 +337,26 @@ func transcodeVideo(inputFile, outputFolder, chunkDuration, videoScale, introFil
 		"-hls_segment_filename", filepath.Join(outputFolder, "segment_%03d.ts"),
 		"-hls_flags", "delete_segments",
 		"-f", "hls", filepath.Join(outputFolder, "index.m3u8"),
-	}
+	)
 
-	cmd := exec.Command("ffmpeg", ffmpegArgs...)
+	cmd := exec.Command(ffmpegPath, ffmpegArgs...)
 	//cmd.Stdout = os.Stdout
 	//cmd.Stderr = os.Stderr
-	log.Println("Commande ffmpeg :", cmd.String())
+	logger.Debugf("Commande ffmpeg : %s", cmd.String())
 	err := cmd.Run()
 	if err != nil {
-		cmd = exec.Command("ffmpeg", ffmpegArgs...)
+		cmd = exec.Command(ffmpegPath, ffmpegArgs...)
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 		err = cmd.Run()
 		return fmt.Errorf("failed to execute command: %w", err)
 	}
-	log.Println("Vidéo extraite :", "index.m3u8")
+	logger.Infof("Vidéo extraite : %s", "index.m3u8")
 	return nil
 }
 
 func extractAudioStreams(inputFile, outputFolder, chunkDuration string, audioStreams []string, introFile string) error {
-	log.Println("Transcodage des pistes audio...")
+	logger.Infof("Transcodage des pistes audio...")
 
 	semaphore := make(chan struct{}, 2) // Limit to 2 concurrent ffmpeg processes
 	wg := sync.WaitGroup{}
@@ -150,12 +372,12 @@ func extractAudioStreams(inputFile, outputFolder, chunkDuration string, audioStr
 			defer func() { <-semaphore }() // Free slot
 
 			outputFile := filepath.Join(outputFolder, fmt.Sprintf("audio_%s.m3u8", stream))
-			cmd := exec.Command("ffmpeg",
+			cmd := exec.Command(ffmpegPath,
 				"-i", introFile,
 				"-i", inputFile,
 				"-filter_complex", "[0:a:0][1:"+stream+"]concat=n=2:v=0:a=1[outa]",
 				"-map", "[outa]",
-				"-c:a", "aac",
+				"-c:a", audioEncoder,
 				"-b:a", "160k",
 				"-ac", "2",
 				"-hls_time", chunkDuration,
@@ -165,16 +387,16 @@ func extractAudioStreams(inputFile, outputFolder, chunkDuration string, audioStr
 			)
 			//cmd.Stdout = os.Stdout
 			//cmd.Stderr = os.Stderr
-			log.Println("Commande ffmpeg :", cmd.String())
+			logger.Debugf("Commande ffmpeg : %s", cmd.String())
 
 			if err := cmd.Run(); err != nil {
 				if err != nil {
-					cmd = exec.Command("ffmpeg",
+					cmd = exec.Command(ffmpegPath,
 						"-i", introFile,
 						"-i", inputFile,
 						"-filter_complex", "[0:a:0][1:"+stream+"]concat=n=2:v=0:a=1[outa]",
 						"-map", "[outa]",
-						"-c:a", "aac",
+						"-c:a", audioEncoder,
 						"-b:a", "160k",
 						"-ac", "2",
 						"-hls_time", chunkDuration,
@@ -184,7 +406,7 @@ func extractAudioStreams(inputFile, outputFolder, chunkDuration string, audioStr
 					)
 					cmd.Stderr = os.Stderr
 					cmd.Stdout = os.Stdout
-					log.Printf("failed to execute command: %v", err)
+					logger.Errorf("failed to execute command: %v", err)
 					err = cmd.Run()
 					errLock.Lock()
 					defer errLock.Unlock()
@@ -192,87 +414,118 @@ func extractAudioStreams(inputFile, outputFolder, chunkDuration string, audioStr
 					return
 				}
 			}
-			log.Println("Piste audio extraite :", outputFile)
+			logger.Infof("Piste audio extraite : %s", outputFile)
 		}(stream)
-		if errS != nil {
-			return errS
-		}
 	}
 
+	// Wait for every process, even after a failure, so none keeps writing into a folder being removed.
 	wg.Wait()
-	return nil
+	return errS
 }
 
-func extractSubtitleStreams(inputFile, outputFolder string, subtitleStreams []string, introFile string) error {
-	log.Println("Transcodage des pistes de sous-titres...")
-
-	// Obtenir la durée de la vidéo "intro"
-	introDuration, err := getVideoDuration(introFile)
-	if err != nil {
-		return fmt.Errorf("failed to get intro video duration: %w", err)
-	}
-
-	log.Println("Durée de la vidéo d'introduction :", introDuration)
+// extractSubtitleStreams extracts the subtitle streams as WebVTT files on a best-effort basis.
+// A track that fails to extract is logged, removed from the output and reported in failed;
+// only errors affecting every track are returned.
+func extractSubtitleStreams(inputFile, outputFolder, scratchFolder string, subtitleStreams []subtitleStream, introDuration time.Duration, preserveASSStyling bool) (extracted []subtitleStream, failed []string, err error) {
+	logger.Infof("Transcodage des pistes de sous-titres...")
+	logger.Debugf("Durée de la vidéo d'introduction : %s", introDuration)
 
 	semaphore := make(chan struct{}, 4) // Limit to 4 concurrent ffmpeg processes
 	wg := sync.WaitGroup{}
-	var errLock sync.Mutex
-	var errS error = nil
+	succeeded := make([]bool, len(subtitleStreams))
 
-	for _, stream := range subtitleStreams {
+	for i, stream := range subtitleStreams {
 		wg.Add(1)
 
-		go func(stream string) {
+		go func(i int, stream subtitleStream) {
 			defer wg.Done()
 			semaphore <- struct{}{}        // Wait for a free slot
 			defer func() { <-semaphore }() // Free slot
 
-			outputFile := filepath.Join(outputFolder, fmt.Sprintf("subtitle_%s.vtt", stream))
-			cmd := exec.Command("ffmpeg",
-				"-i", inputFile,
-				"-map", "0:"+stream,
-				outputFile,
-			)
-			//cmd.Stdout = os.Stdout
-			//cmd.Stderr = os.Stderr
-			if err := cmd.Run(); err != nil {
-				cmd = exec.Command("ffmpeg",
-					"-i", inputFile,
-					"-map", "0:"+stream,
-					outputFile,
-				)
-				cmd.Stderr = os.Stderr
-				cmd.Stdout = os.Stdout
-				log.Printf("failed to execute command: %v", err)
-				err = cmd.Run()
-				errLock.Lock()
-				defer errLock.Unlock()
-				errS = fmt.Errorf("failed to execute command: %w", err)
+			outputFile := filepath.Join(outputFolder, fmt.Sprintf("subtitle_%s.vtt", stream.index))
+			if err := extractSubtitleStream(inputFile, scratchFolder, outputFile, stream, introDuration, preserveASSStyling); err != nil {
+				logger.Warnf("Échec de l'extraction de la piste de sous-titres %s, elle sera ignorée : %v", stream.index, err)
+				os.Remove(outputFile)
 				return
 			}
+			succeeded[i] = true
+			logger.Infof("Piste de sous-titres extraite : %s", outputFile)
+		}(i, stream)
+	}
 
-			if err = shiftSubtitleTimecodes(outputFile, introDuration); err != nil {
-				log.Printf("failed to shift subtitle timestamps: %v", err)
-				errLock.Lock()
-				defer errLock.Unlock()
-				errS = fmt.Errorf("failed to shift subtitle timestamps: %w", err)
-				return
-			}
+	wg.Wait()
 
-			log.Println("Piste de sous-titres extraite :", outputFile)
-		}(stream)
-		if errS != nil {
-			return errS
+	for i, stream := range subtitleStreams {
+		if succeeded[i] {
+			extracted = append(extracted, stream)
+		} else {
+			failed = append(failed, stream.index)
 		}
 	}
+	return extracted, failed, nil
+}
 
-	wg.Wait()
+// extractSubtitleStream extracts a single subtitle stream as WebVTT and shifts it by the intro duration.
+func extractSubtitleStream(inputFile, scratchFolder, outputFile string, stream subtitleStream, introDuration time.Duration, preserveASSStyling bool) error {
+	if isASSCodec(stream.codecName) {
+		if preserveASSStyling {
+			if err := extractASSSubtitle(inputFile, scratchFolder, stream.index, outputFile, introDuration); err != nil {
+				return err
+			}
+			return normalizeWebVTT(outputFile)
+		}
+		logger.Warnf("Piste de sous-titres ASS, la mise en forme risque d'être perdue : %s", stream.index)
+	}
+
+	cmd := exec.Command(ffmpegPath,
+		"-i", inputFile,
+		"-map", "0:"+stream.index,
+		outputFile,
+	)
+	//cmd.Stdout = os.Stdout
+	//cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		cmd = exec.Command(ffmpegPath,
+			"-i", inputFile,
+			"-map", "0:"+stream.index,
+			"-y",
+			outputFile,
+		)
+		cmd.Stderr = os.Stderr
+		cmd.Stdout = os.Stdout
+		logger.Errorf("failed to execute command: %v", err)
+		cmd.Run()
+		return fmt.Errorf("failed to execute command: %w", err)
+	}
 
+	if err := shiftSubtitleTimecodes(outputFile, introDuration); err != nil {
+		return fmt.Errorf("failed to shift subtitle timestamps: %w", err)
+	}
+	return normalizeWebVTT(outputFile)
+}
+
+// extractASSSubtitle extracts an ASS/SSA subtitle stream as-is and converts it to WebVTT keeping its basic styling.
+func extractASSSubtitle(inputFile, scratchFolder, stream, outputFile string, introDuration time.Duration) error {
+	assFile := filepath.Join(scratchFolder, fmt.Sprintf("subtitle_%s.ass", stream))
+	cmd := exec.Command(ffmpegPath,
+		"-i", inputFile,
+		"-map", "0:"+stream,
+		"-c:s", "copy",
+		assFile,
+	)
+	if err := cmd.Run(); err != nil {
+		os.Remove(assFile)
+		return fmt.Errorf("failed to execute command: %w", err)
+	}
+	if err := convertASSToWebVTT(assFile, outputFile, introDuration); err != nil {
+		os.Remove(assFile)
+		return err
+	}
 	return nil
 }
 
 func getVideoDuration(videoFile string) (time.Duration, error) {
-	cmd := exec.Command("ffprobe",
+	cmd := exec.Command(ffprobePath,
 		"-v", "error",
 		"-show_entries", "format=duration",
 		"-of", "default=noprint_wrappers=1:nokey=1",
@@ -300,7 +553,7 @@ func shiftSubtitleTimecodes(subtitleFile string, duration time.Duration) error {
 	}
 
 	// Décaler les timecodes de la durée de la vidéo d'introduction
-	subs.Add(duration)
+	shiftSubtitles(subs, duration)
 
 	// Enregistrer les modifications dans le fichier SRT
 	err = subs.Write(subtitleFile)
@@ -311,70 +564,162 @@ func shiftSubtitleTimecodes(subtitleFile string, duration time.Duration) error {
 	return nil
 }
 
-func ProcessFileTranscode(inputFilePath, introPath, intro219Path, mediaID, outputFolder, chunkDuration, videoScale, videoScale219 string) (TranscodeResponse, error) {
+// lockOutputFolder takes the publish lock of an output folder, a lock file created next to it, waiting for
+// the publish of the other runs on the same media to complete. It returns the function releasing the lock.
+func lockOutputFolder(outputFileFolder string) (func(), error) {
+	lockPath := filepath.Join(filepath.Dir(outputFileFolder), "."+filepath.Base(outputFileFolder)+".lock")
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			lockFile.Close()
+			return func() {
+				if err := os.Remove(lockPath); err != nil {
+					logger.Warnf("Impossible de supprimer le verrou de publication : %v", err)
+				}
+			}, nil
+		}
+		if !errors.Is(err, fs.ErrExist) {
+			return nil, fmt.Errorf("failed to create publish lock: %w", err)
+		}
+		if info, err := os.Stat(lockPath); err == nil && time.Since(info.ModTime()) > publishLockStaleAge {
+			logger.Warnf("Suppression d'un verrou de publication abandonné : %s", lockPath)
+			os.Remove(lockPath)
+			continue
+		}
+		time.Sleep(publishLockRetryDelay)
+	}
+}
+
+// ProcessFileTranscode transcodes the input file to HLS into outputFolder/mediaID.
+// The output is produced in a temporary folder and moved into place only on success:
+// concurrent or retried runs on the same mediaID never clobber each other, the last
+// run to publish wins, and a failed run leaves any previous output untouched.
+func ProcessFileTranscode(inputFilePath, introPath, intro219Path, mediaID, outputFolder, chunkDuration, videoScale, videoScale219 string, opts ...TranscodeOption) (TranscodeResponse, error) {
+	options := newTranscodeOptions(opts)
 	start := time.Now()
-	log.Println("Début du transcodage du fichier :", inputFilePath)
+	logger.Infof("Début du transcodage du fichier : %s", inputFilePath)
+	introPath, intro219Path = options.selectIntro(introPath, intro219Path)
 
-	outputFileFolder := filepath.Join(outputFolder, mediaID)
-	if err := prepareOutputFolder(outputFileFolder); err != nil {
+	if err := CheckBinaries(); err != nil {
+		return TranscodeResponse{}, err
+	}
+	if err := checkEncoders(videoEncoder, audioEncoder); err != nil {
 		return TranscodeResponse{}, err
 	}
 
-	audioStreams, subtitleStreams, _, aspectRatio, err := extractStreamsInfo(inputFilePath)
+	audioStreams, subtitleStreams, videoCodec, aspectRatio, err := extractStreamsInfo(inputFilePath)
 	if err != nil {
 		return TranscodeResponse{}, err
 	}
-
-	beforeTranscode := time.Now()
-	aspectRatioSplit := strings.Split(aspectRatio, ":")
-	if len(aspectRatioSplit) != 2 {
-		log.Println("Erreur lors de la récupération du ratio de la vidéo :", aspectRatioSplit)
-		log.Println("Le ratio par défaut 16:9 sera utilisé")
-		aspectRatioSplit = []string{"16", "9"}
+	if videoCodec == "" {
+		return TranscodeResponse{}, fmt.Errorf("%w in %s", ErrNoVideoStream, inputFilePath)
 	}
-	ratioX, err := strconv.ParseFloat(aspectRatioSplit[0], 64)
+	if len(audioStreams) == 0 {
+		if options.requireAudio {
+			return TranscodeResponse{}, fmt.Errorf("%w in %s", ErrNoAudioStream, inputFilePath)
+		}
+		logger.Warnf("Aucune piste audio trouvée, seule la vidéo sera transcodée : %s", inputFilePath)
+	}
+	audioStreams = selectAudioStreams(inputFilePath, audioStreams, options.audioTracks)
+	subtitleStreams = selectSubtitleStreams(subtitleStreams, options.subtitleTracks)
+
+	hdr, err := probeHDR(inputFilePath)
 	if err != nil {
-		log.Println("Erreur lors de la récupération du ratio de la vidéo :", err)
-		ratioX = 16
+		logger.Warnf("Impossible de détecter si la vidéo est en HDR : %v", err)
+	}
+	toneMap := hdr && options.toneMapHDR
+	if hdr && !toneMap {
+		logger.Warnf("Vidéo HDR détectée sans conversion en SDR, les couleurs risquent d'être délavées")
+	} else if toneMap {
+		logger.Infof("Vidéo HDR détectée, conversion en SDR")
 	}
-	ratioY, err := strconv.ParseFloat(aspectRatioSplit[1], 64)
+
+	outputFileFolder := filepath.Join(outputFolder, mediaID)
+	workFolder, err := prepareOutputFolder(outputFolder, mediaID, options.tempDir)
 	if err != nil {
-		log.Println("Erreur lors de la récupération du ratio de la vidéo :", err)
-		ratioY = 9
+		return TranscodeResponse{}, err
 	}
+	// Once published, the work folder no longer exists: this only removes the output of a failed run.
+	defer os.RemoveAll(workFolder)
 
-	if ratioX/ratioY > 1.8 {
-		log.Println("La vidéo est au format 21:9")
-		if err := transcodeVideo(inputFilePath, outputFileFolder, chunkDuration, videoScale219, intro219Path); err != nil {
-			os.RemoveAll(outputFileFolder)
-			return TranscodeResponse{}, err
-		}
+	metrics := TranscodeMetrics{
+		MediaID:       mediaID,
+		ProbeDuration: time.Since(start),
+		InputSize:     fileSize(inputFilePath),
+	}
+
+	beforeTranscode := time.Now()
+	reusedVideo := options.resume && reusePlaylist(outputFileFolder, workFolder, "index.m3u8")
+	ratio := videoAspectRatio(inputFilePath, aspectRatio)
+
+	bucket := selectAspectBucket(options.aspectBuckets(videoScale, introPath, videoScale219, intro219Path), ratio)
+	if sourceWidth, sourceHeight, err := probeVideoSize(inputFilePath); err != nil {
+		logger.Warnf("Impossible de récupérer la résolution de la vidéo : %v", err)
 	} else {
-		log.Println("La vidéo est au format 16:9")
-		if err := transcodeVideo(inputFilePath, outputFileFolder, chunkDuration, videoScale, introPath); err != nil {
-			os.RemoveAll(outputFileFolder)
+		bucket.videoScale = capVideoScale(bucket.videoScale, sourceWidth, sourceHeight)
+	}
+
+	if reusedVideo {
+		logger.Infof("Transcodage de la vidéo ignoré, la sortie précédente est réutilisée")
+	} else {
+		logger.Infof("Ratio de la vidéo : %.2f, résolution utilisée : %s", ratio, bucket.videoScale)
+		if err := transcodeVideo(inputFilePath, workFolder, chunkDuration, bucket.videoScale, bucket.introPath, toneMap, options.watermark); err != nil {
 			return TranscodeResponse{}, err
 		}
 	}
-	log.Println("Temps de transcodage de la vidéo :", time.Since(beforeTranscode))
+	if !reusedVideo {
+		metrics.VideoDuration = time.Since(beforeTranscode)
+	}
+	logger.Infof("Temps de transcodage de la vidéo : %s", time.Since(beforeTranscode))
+
+	pendingAudioStreams := audioStreams
+	if options.resume {
+		pendingAudioStreams = nil
+		for _, stream := range audioStreams {
+			if !reusePlaylist(outputFileFolder, workFolder, fmt.Sprintf("audio_%s.m3u8", stream)) {
+				pendingAudioStreams = append(pendingAudioStreams, stream)
+			}
+		}
+	}
 
 	beforeAudio := time.Now()
-	if err := extractAudioStreams(inputFilePath, outputFileFolder, chunkDuration, audioStreams, introPath); err != nil {
-		os.RemoveAll(outputFileFolder)
+	if err := extractAudioStreams(inputFilePath, workFolder, chunkDuration, pendingAudioStreams, bucket.introPath); err != nil {
 		return TranscodeResponse{}, err
 	}
-	log.Println("Temps de transcodage des pistes audio :", time.Since(beforeAudio))
+	metrics.AudioDuration = time.Since(beforeAudio)
+	logger.Infof("Temps de transcodage des pistes audio : %s", metrics.AudioDuration)
 
 	beforeSubtitle := time.Now()
-	if err := extractSubtitleStreams(inputFilePath, outputFileFolder, subtitleStreams, introPath); err != nil {
-		os.RemoveAll(outputFileFolder)
+	scratchFolder, err := os.MkdirTemp(options.scratchDir(), "transcode-"+mediaID+"-")
+	if err != nil {
+		return TranscodeResponse{}, fmt.Errorf("failed to create scratch directory: %w", err)
+	}
+	defer os.RemoveAll(scratchFolder)
+	// Subtitles are shifted by the duration of the very intro concatenated before the video and audio.
+	introDuration, err := getIntroDuration(bucket.introPath)
+	if err != nil {
+		return TranscodeResponse{}, fmt.Errorf("failed to get intro video duration: %w", err)
+	}
+	subtitleStreams, failedSubtitles, err := extractSubtitleStreams(inputFilePath, workFolder, scratchFolder, subtitleStreams, introDuration, options.preserveASSStyling)
+	if err != nil {
 		return TranscodeResponse{}, err
 	}
-	log.Println("Temps de transcodage des pistes de sous-titres :", time.Since(beforeSubtitle))
+	metrics.SubtitlesDuration = time.Since(beforeSubtitle)
+	logger.Infof("Temps de transcodage des pistes de sous-titres : %s", metrics.SubtitlesDuration)
+
+	thumbnail := ""
+	if options.thumbnail {
+		if thumbnail, err = extractThumbnail(inputFilePath, workFolder, options.thumbnailOffset); err != nil {
+			logger.Warnf("Impossible d'extraire la miniature : %v", err)
+		}
+	}
 
-	log.Println("Transcodage terminé. Fichiers HLS générés dans :", outputFileFolder)
+	logger.Infof("Transcodage terminé. Fichiers HLS générés dans : %s", outputFileFolder)
 	response := TranscodeResponse{
-		VideoIndex: "index.m3u8",
+		SchemaVersion:   ResponseSchemaVersion,
+		VideoIndex:      "index.m3u8",
+		FailedSubtitles: failedSubtitles,
+		Thumbnail:       thumbnail,
 	}
 	for _, stream := range audioStreams {
 		response.Audios = append(response.Audios, AudioTranscodeResponse{
@@ -382,15 +727,44 @@ func ProcessFileTranscode(inputFilePath, introPath, intro219Path, mediaID, outpu
 		})
 	}
 	for _, stream := range subtitleStreams {
+		language, languageName := NormalizeLanguage(stream.language)
 		response.Subtitles = append(response.Subtitles, SubtitleTranscodeResponse{
-			SubtitleIndex: fmt.Sprintf("subtitle_%s.vtt", stream),
+			SubtitleIndex:   fmt.Sprintf("subtitle_%s.vtt", stream.index),
+			Language:        language,
+			LanguageName:    languageName,
+			Forced:          stream.forced,
+			HearingImpaired: stream.hearingImpaired,
 		})
 	}
-	log.Println("Temps de transcodage :", time.Since(start))
+	logger.Infof("Temps de transcodage : %s", time.Since(start))
+
+	if response.OutputSize, response.VideoBitrate, err = measureOutput(workFolder); err != nil {
+		logger.Warnf("Impossible de mesurer la taille de la sortie : %v", err)
+	}
+
+	if options.writeSidecar {
+		if err := writeResponseSidecar(workFolder, response); err != nil {
+			return TranscodeResponse{}, err
+		}
+	}
+
+	if err := publishOutputFolder(workFolder, outputFileFolder); err != nil {
+		return TranscodeResponse{}, err
+	}
+
+	if options.outputPermissions == 0 {
+		logger.Debugf("Permissions du dossier inchangées : %s", outputFileFolder)
+	} else if err := os.Chmod(outputFileFolder, options.outputPermissions); err != nil {
+		logger.Warnf("Failed to set folder permissions to %o : %v", options.outputPermissions, err)
+	}
 
-	// Set folder permissions to 777
-	if err := os.Chmod(outputFileFolder, 0777); err != nil {
-		log.Println("Failed to set folder permissions to 777 :", err)
+	if options.metricsCallback != nil {
+		metrics.TotalDuration = time.Since(start)
+		metrics.OutputSize = response.OutputSize
+		metrics.AudioStreams = len(response.Audios)
+		metrics.SubtitleStreams = len(response.Subtitles)
+		metrics.FailedSubtitles = len(response.FailedSubtitles)
+		options.metricsCallback(metrics)
 	}
 
 	return response, nil
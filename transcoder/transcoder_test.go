@@ -0,0 +1,558 @@
+package transcoder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeProbe is the answer of the fake ffprobe to a -show_entries query.
+type fakeProbe struct {
+	entries string // value passed to -show_entries
+	input   string // input file, empty to match any
+	output  string
+}
+
+// fakeTools installs fake ffmpeg and ffprobe executables.
+// The fake ffmpeg logs its arguments, one call per line, and writes a minimal output file
+// for the playlists (with one empty segment named after -hls_segment_filename), subtitles and
+// images it is asked to produce. ASS outputs are copied from fixture.ass.
+type fakeTools struct {
+	dir   string
+	calls string
+}
+
+func newFakeTools(t *testing.T, probes ...fakeProbe) *fakeTools {
+	t.Helper()
+	dir := t.TempDir()
+	ft := &fakeTools{dir: dir, calls: filepath.Join(dir, "calls")}
+	ft.writeFFmpeg(t, "")
+	ft.writeFFprobe(t, probes...)
+	SetFFmpegPath(filepath.Join(dir, "ffmpeg"))
+	SetFFprobePath(filepath.Join(dir, "ffprobe"))
+	t.Cleanup(func() {
+		SetFFmpegPath("")
+		SetFFprobePath("")
+		RefreshCapabilities()
+	})
+	return ft
+}
+
+// writeFFmpeg (re)writes the fake ffmpeg. It exits with an error when its arguments match the shell
+// pattern failOn, if not empty.
+func (ft *fakeTools) writeFFmpeg(t *testing.T, failOn string) {
+	t.Helper()
+	fail := ""
+	if failOn != "" {
+		fail = fmt.Sprintf("case \"$*\" in %s) exit 1 ;; esac\n", failOn)
+	}
+	writeExecutable(t, filepath.Join(ft.dir, "ffmpeg"), `echo "$@" >> `+ft.calls+`
+case "$2" in
+-version) echo "ffmpeg version 6.0 Copyright"; exit 0 ;;
+-encoders) printf ' ------\n V....D libx264  H.264\n A....D aac  AAC\n'; exit 0 ;;
+-hwaccels) printf 'Hardware acceleration methods:\ncuda\n'; exit 0 ;;
+esac
+`+fail+`segment=segment_%03d.ts
+for last; do
+	[ "$prev" = "-hls_segment_filename" ] && segment="$last"
+	prev="$last"
+done
+case "$last" in
+*.m3u8) segment="$(basename "$segment" | sed 's/%03d/000/')"
+	printf '#EXTM3U\n#EXTINF:10.0,\n%s\n#EXT-X-ENDLIST\n' "$segment" > "$last"; : > "$(dirname "$last")/$segment" ;;
+*.vtt) printf 'WEBVTT\n\n00:00:01.000 --> 00:00:02.000\nBonjour\n' > "$last" ;;
+*.ass) cp "`+ft.dir+`/fixture.ass" "$last" ;;
+*.jpg) : > "$last" ;;
+esac
+`)
+}
+
+func (ft *fakeTools) writeFFprobe(t *testing.T, probes ...fakeProbe) {
+	t.Helper()
+	var cases strings.Builder
+	for _, probe := range probes {
+		input := "*"
+		if probe.input != "" {
+			input = "'" + probe.input + "'"
+		}
+		fmt.Fprintf(&cases, "'%s '%s) printf '%%s\\n' '%s' ;;\n", probe.entries, input, probe.output)
+	}
+	writeExecutable(t, filepath.Join(ft.dir, "ffprobe"), `prev=""
+for arg; do
+	[ "$prev" = "-show_entries" ] && entries="$arg"
+	prev="$arg"
+done
+case "$entries $prev" in
+`+cases.String()+`*) echo "unexpected probe: $*" >&2; exit 1 ;;
+esac
+`)
+}
+
+// ffmpegCalls returns the arguments of every ffmpeg run, capability checks excluded.
+func (ft *fakeTools) ffmpegCalls(t *testing.T) []string {
+	t.Helper()
+	data, err := os.ReadFile(ft.calls)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	var calls []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		if strings.HasPrefix(line, "-hide_banner") {
+			continue
+		}
+		calls = append(calls, line)
+	}
+	return calls
+}
+
+// ffmpegCall returns the single ffmpeg run whose arguments contain substr.
+func (ft *fakeTools) ffmpegCall(t *testing.T, substr string) string {
+	t.Helper()
+	var found []string
+	for _, call := range ft.ffmpegCalls(t) {
+		if strings.Contains(call, substr) {
+			found = append(found, call)
+		}
+	}
+	if len(found) != 1 {
+		t.Fatalf("found %d ffmpeg runs with %q, want 1: %q", len(found), substr, ft.ffmpegCalls(t))
+	}
+	return found[0]
+}
+
+// sampleMovieProbes describes a 16:9 movie with two audio tracks and a text subtitle, and its intro.
+func sampleMovieProbes() []fakeProbe {
+	return []fakeProbe{
+		{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,h264,video,16:9\n1,aac,audio\n2,ac3,audio\n3,subrip,subtitle"},
+		{entries: "format=duration", output: "5.000000"},
+	}
+}
+
+// writeSampleInputs writes empty movie.mkv, intro.mkv and intro_21-9.mkv files into dir, and returns the movie path.
+func writeSampleInputs(t *testing.T, dir string) string {
+	t.Helper()
+	for _, name := range []string{"movie.mkv", "intro.mkv", "intro_21-9.mkv"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return filepath.Join(dir, "movie.mkv")
+}
+
+// transcodeSample runs ProcessFileTranscode on a fake movie and returns the output folder.
+func transcodeSample(t *testing.T, opts ...TranscodeOption) (TranscodeResponse, string, error) {
+	t.Helper()
+	dir := t.TempDir()
+	input := writeSampleInputs(t, dir)
+	outputFolder := filepath.Join(dir, "media")
+	response, err := ProcessFileTranscode(input, filepath.Join(dir, "intro.mkv"), filepath.Join(dir, "intro_21-9.mkv"),
+		"42", outputFolder, "10", "1280:720", "1920:816", opts...)
+	return response, filepath.Join(outputFolder, "42"), err
+}
+
+func TestProcessFileTranscodeOutputPermissions(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []TranscodeOption
+		want os.FileMode
+	}{
+		{name: "default", want: 0o755},
+		{name: "custom", opts: []TranscodeOption{WithOutputPermissions(0o750)}, want: 0o750},
+		// The published folder keeps the mode of the work folder.
+		{name: "untouched", opts: []TranscodeOption{WithOutputPermissions(0)}, want: defaultOutputPermissions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			newFakeTools(t, sampleMovieProbes()...)
+			_, folder, err := transcodeSample(t, tt.opts...)
+			if err != nil {
+				t.Fatalf("ProcessFileTranscode() = %v", err)
+			}
+			info, err := os.Stat(folder)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := info.Mode().Perm(); got != tt.want {
+				t.Errorf("output folder mode = %o, want %o", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeResponseSidecar(t *testing.T) {
+	newFakeTools(t, sampleMovieProbes()...)
+	response, folder, err := transcodeSample(t, WithResponseSidecar())
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(folder, ResponseSidecarFilename))
+	if err != nil {
+		t.Fatalf("sidecar not written: %v", err)
+	}
+	var sidecar TranscodeResponse
+	if err := json.Unmarshal(data, &sidecar); err != nil {
+		t.Fatalf("sidecar does not parse: %v", err)
+	}
+	if !reflect.DeepEqual(sidecar, response) {
+		t.Errorf("sidecar = %+v, want %+v", sidecar, response)
+	}
+	if sidecar.SchemaVersion != ResponseSchemaVersion {
+		t.Errorf("schema version = %d, want %d", sidecar.SchemaVersion, ResponseSchemaVersion)
+	}
+	if !strings.Contains(string(data), `"video_index": "index.m3u8"`) {
+		t.Errorf("sidecar does not use the struct tags:\n%s", data)
+	}
+}
+
+func TestProcessFileTranscodeWithoutSidecar(t *testing.T) {
+	newFakeTools(t, sampleMovieProbes()...)
+	_, folder, err := transcodeSample(t)
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(folder, ResponseSidecarFilename)); !os.IsNotExist(err) {
+		t.Errorf("sidecar written without WithResponseSidecar: %v", err)
+	}
+}
+
+func TestProcessFileTranscodeSkipsFailedSubtitle(t *testing.T) {
+	ft := newFakeTools(t,
+		fakeProbe{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,h264,video,16:9\n1,aac,audio\n2,subrip,subtitle\n3,subrip,subtitle"},
+		fakeProbe{entries: "format=duration", output: "5.000000"},
+	)
+	ft.writeFFmpeg(t, "*subtitle_3.vtt")
+
+	response, folder, err := transcodeSample(t)
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v, want the failed subtitle skipped", err)
+	}
+	want := []SubtitleTranscodeResponse{{SubtitleIndex: "subtitle_2.vtt", Language: "und", LanguageName: "Undetermined"}}
+	if !reflect.DeepEqual(response.Subtitles, want) {
+		t.Errorf("Subtitles = %+v, want %+v", response.Subtitles, want)
+	}
+	if !reflect.DeepEqual(response.FailedSubtitles, []string{"3"}) {
+		t.Errorf("FailedSubtitles = %v, want [3]", response.FailedSubtitles)
+	}
+	for _, name := range []string{"index.m3u8", "audio_1.m3u8", "subtitle_2.vtt"} {
+		if _, err := os.Stat(filepath.Join(folder, name)); err != nil {
+			t.Errorf("%s missing from the output: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(folder, "subtitle_3.vtt")); !os.IsNotExist(err) {
+		t.Errorf("failed subtitle left in the output: %v", err)
+	}
+}
+
+func TestProcessFileTranscodeSubtitleFlags(t *testing.T) {
+	newFakeTools(t,
+		fakeProbe{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,h264,video,16:9\n1,aac,audio\n3,subrip,subtitle\n4,subrip,subtitle\n5,subrip,subtitle\n6,subrip,subtitle"},
+		fakeProbe{entries: "stream=index,codec_name,codec_type:stream_disposition=forced,hearing_impaired:stream_tags=language", output: sampleSubtitleProbe},
+		fakeProbe{entries: "format=duration", output: "5.000000"},
+	)
+
+	response, _, err := transcodeSample(t)
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	want := []SubtitleTranscodeResponse{
+		{SubtitleIndex: "subtitle_3.vtt", Language: "fr", LanguageName: "French"},
+		{SubtitleIndex: "subtitle_4.vtt", Language: "fr", LanguageName: "French", Forced: true},
+		{SubtitleIndex: "subtitle_5.vtt", Language: "en", LanguageName: "English", HearingImpaired: true},
+		{SubtitleIndex: "subtitle_6.vtt", Language: "und", LanguageName: "Undetermined"},
+	}
+	if !reflect.DeepEqual(response.Subtitles, want) {
+		t.Errorf("Subtitles = %+v, want %+v", response.Subtitles, want)
+	}
+}
+
+// simulateRun prepares a work folder for the media, writes the files of a complete output tagged with run,
+// then publishes it, like ProcessFileTranscode does around the ffmpeg steps.
+func simulateRun(outputFolder, mediaID, run string) error {
+	workFolder, err := prepareOutputFolder(outputFolder, mediaID, "")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(workFolder)
+	for _, name := range []string{"index.m3u8", "segment_000.ts", "segment_001.ts"} {
+		if err := os.WriteFile(filepath.Join(workFolder, name), []byte(run), 0644); err != nil {
+			return err
+		}
+	}
+	return publishOutputFolder(workFolder, filepath.Join(outputFolder, mediaID))
+}
+
+func TestConcurrentRunsOnSameMedia(t *testing.T) {
+	outputFolder := t.TempDir()
+	for iteration := 0; iteration < 50; iteration++ {
+		var wg sync.WaitGroup
+		errs := make([]error, 4)
+		for run := range errs {
+			wg.Add(1)
+			go func(run int) {
+				defer wg.Done()
+				errs[run] = simulateRun(outputFolder, "42", fmt.Sprintf("run-%d-%d", iteration, run))
+			}(run)
+		}
+		wg.Wait()
+		for run, err := range errs {
+			if err != nil {
+				t.Fatalf("run %d of iteration %d failed: %v", run, iteration, err)
+			}
+		}
+		assertSingleRunOutput(t, filepath.Join(outputFolder, "42"))
+		entries, err := os.ReadDir(outputFolder)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 1 {
+			var names []string
+			for _, entry := range entries {
+				names = append(names, entry.Name())
+			}
+			t.Fatalf("output folder contains %v, want only the published media folder", names)
+		}
+	}
+}
+
+// assertSingleRunOutput checks that every file of the output folder was written by the same run.
+func assertSingleRunOutput(t *testing.T, folder string) {
+	t.Helper()
+	entries, err := os.ReadDir(folder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("published folder has %d files, want 3", len(entries))
+	}
+	runs := make(map[string]bool)
+	for _, entry := range entries {
+		data, err := os.ReadFile(filepath.Join(folder, entry.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs[string(data)] = true
+	}
+	if len(runs) != 1 {
+		t.Fatalf("published folder mixes the files of runs %v", runs)
+	}
+}
+
+func TestLockOutputFolderRemovesStaleLock(t *testing.T) {
+	outputFileFolder := filepath.Join(t.TempDir(), "42")
+	lockPath := filepath.Join(filepath.Dir(outputFileFolder), ".42.lock")
+	if err := os.WriteFile(lockPath, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	old := time.Now().Add(-2 * publishLockStaleAge)
+	if err := os.Chtimes(lockPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	unlock, err := lockOutputFolder(outputFileFolder)
+	if err != nil {
+		t.Fatalf("lockOutputFolder() = %v", err)
+	}
+	unlock()
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("lock file still present after unlock: %v", err)
+	}
+}
+
+func TestProcessFileTranscodeFailureKeepsPreviousOutput(t *testing.T) {
+	ft := newFakeTools(t, sampleMovieProbes()...)
+	_, folder, err := transcodeSample(t)
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	marker := filepath.Join(folder, "index.m3u8")
+	if err := os.WriteFile(marker, []byte("previous"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	ft.writeFFmpeg(t, "*index.m3u8")
+	input := filepath.Join(filepath.Dir(filepath.Dir(folder)), "movie.mkv")
+	_, err = ProcessFileTranscode(input, "intro.mkv", "intro_21-9.mkv", "42", filepath.Dir(folder), "10", "1280:720", "1920:816")
+	if err == nil {
+		t.Fatal("ProcessFileTranscode() = nil, want the video error")
+	}
+	if data, err := os.ReadFile(marker); err != nil || string(data) != "previous" {
+		t.Errorf("previous output = %q, %v, want it untouched", data, err)
+	}
+	entries, err := os.ReadDir(filepath.Dir(folder))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("media folder holds %d entries after the failed run, want only the previous output", len(entries))
+	}
+}
+
+func TestProcessFileTranscodeMissingStreams(t *testing.T) {
+	const probeEntries = "stream=index,codec_name,codec_type,display_aspect_ratio"
+
+	t.Run("audio only", func(t *testing.T) {
+		ft := newFakeTools(t, fakeProbe{entries: probeEntries, output: "0,mp3,audio"})
+		_, folder, err := transcodeSample(t)
+		if !errors.Is(err, ErrNoVideoStream) {
+			t.Fatalf("ProcessFileTranscode() = %v, want ErrNoVideoStream", err)
+		}
+		if calls := ft.ffmpegCalls(t); len(calls) != 0 {
+			t.Errorf("ffmpeg ran for an audio-only file: %q", calls)
+		}
+		if _, err := os.Stat(filepath.Dir(folder)); !os.IsNotExist(err) {
+			t.Errorf("output folder created for an audio-only file: %v", err)
+		}
+	})
+
+	t.Run("silent video", func(t *testing.T) {
+		probes := []fakeProbe{
+			{entries: probeEntries, output: "0,h264,video,16:9\n1,subrip,subtitle"},
+			{entries: "format=duration", output: "5.000000"},
+		}
+		ft := newFakeTools(t, probes...)
+		response, _, err := transcodeSample(t)
+		if err != nil {
+			t.Fatalf("ProcessFileTranscode() = %v, want the video transcoded without audio", err)
+		}
+		if response.VideoIndex != "index.m3u8" || len(response.Audios) != 0 {
+			t.Errorf("response = %+v, want a video without audio renditions", response)
+		}
+		for _, call := range ft.ffmpegCalls(t) {
+			if strings.Contains(call, "audio_") {
+				t.Errorf("audio rendition requested for a silent video: %s", call)
+			}
+		}
+
+		_, _, err = transcodeSample(t, WithRequireAudio())
+		if !errors.Is(err, ErrNoAudioStream) {
+			t.Errorf("ProcessFileTranscode(WithRequireAudio) = %v, want ErrNoAudioStream", err)
+		}
+	})
+}
+
+func TestMeasureOutput(t *testing.T) {
+	folder := t.TempDir()
+	files := map[string]string{
+		"index.m3u8":      "#EXTM3U\n#EXTINF:10.000000,\nsegment_000.ts\n#EXTINF:5.000000,\nsegment_001.ts\n#EXT-X-ENDLIST\n",
+		"segment_000.ts":  strings.Repeat("v", 2000),
+		"segment_001.ts":  strings.Repeat("v", 1000),
+		"audio_1_000.ts":  strings.Repeat("a", 500),
+		"subtitle_2.vtt":  "WEBVTT\n",
+		"audio_1.m3u8":    "#EXTM3U\n",
+		"not_segment.txt": "",
+	}
+	var wantSize int64
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(folder, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		wantSize += int64(len(content))
+	}
+
+	size, bitrate, err := measureOutput(folder)
+	if err != nil {
+		t.Fatalf("measureOutput() = %v", err)
+	}
+	if size != wantSize {
+		t.Errorf("output size = %d, want %d", size, wantSize)
+	}
+	// 3000 bytes of video segments over 15 seconds.
+	if bitrate != 1600 {
+		t.Errorf("video bitrate = %d, want 1600", bitrate)
+	}
+}
+
+func TestPlaylistDurationInvalid(t *testing.T) {
+	playlist := filepath.Join(t.TempDir(), "index.m3u8")
+	if err := os.WriteFile(playlist, []byte("#EXTM3U\n#EXTINF:abc,\nsegment_000.ts\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := playlistDuration(playlist); err == nil {
+		t.Error("playlistDuration() = nil error for an invalid #EXTINF")
+	}
+}
+
+func TestProcessFileTranscodeReportsOutputSize(t *testing.T) {
+	newFakeTools(t, sampleMovieProbes()...)
+	response, _, err := transcodeSample(t)
+	if err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	if response.OutputSize == 0 {
+		t.Error("OutputSize = 0, want the size of the written playlists and subtitles")
+	}
+}
+
+func TestProcessFileTranscodeFailureCleansUp(t *testing.T) {
+	tests := []struct {
+		name   string
+		failOn string
+	}{
+		{name: "video", failOn: "*index.m3u8"},
+		{name: "audio", failOn: "*audio_2.m3u8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := newFakeTools(t, sampleMovieProbes()...)
+			ft.writeFFmpeg(t, tt.failOn)
+
+			_, folder, err := transcodeSample(t, WithResponseSidecar())
+			if err == nil {
+				t.Fatal("ProcessFileTranscode() = nil error, want the ffmpeg failure")
+			}
+			entries, err := os.ReadDir(filepath.Dir(folder))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 0 {
+				t.Errorf("media folder holds %v after the failed run, want it empty", entries)
+			}
+		})
+	}
+}
+
+func TestExtractAudioStreamsWaitsForEveryProcess(t *testing.T) {
+	ft := newFakeTools(t)
+	done := filepath.Join(ft.dir, "done")
+	// audio_1 fails at once while audio_2 is still running
+	writeExecutable(t, filepath.Join(ft.dir, "ffmpeg"), `for last; do :; done
+case "$last" in
+*audio_1.m3u8) exit 1 ;;
+*audio_2.m3u8) sleep 0.2; : > "$last"; : > `+done+` ;;
+esac
+`)
+
+	err := extractAudioStreams("movie.mkv", t.TempDir(), "10", []string{"1", "2"}, "intro.mkv")
+	if err == nil {
+		t.Fatal("extractAudioStreams() = nil error, want the audio_1 failure")
+	}
+	if _, err := os.Stat(done); err != nil {
+		t.Errorf("extractAudioStreams() returned before audio_2 completed: %v", err)
+	}
+}
+
+func TestExtractStreamsInfoWithoutAspectRatio(t *testing.T) {
+	newFakeTools(t, fakeProbe{entries: "stream=index,codec_name,codec_type,display_aspect_ratio", output: "0,h264,video\n1,aac\n\n2,aac,audio"})
+
+	audioStreams, _, videoCodec, aspectRatio, err := extractStreamsInfo("movie.mkv")
+	if err != nil {
+		t.Fatalf("extractStreamsInfo() = %v", err)
+	}
+	if videoCodec != "h264" || aspectRatio != "" {
+		t.Errorf("video stream = %q, %q, want h264 without an aspect ratio", videoCodec, aspectRatio)
+	}
+	if !reflect.DeepEqual(audioStreams, []string{"2"}) {
+		t.Errorf("audio streams = %v, want [2], the short lines being skipped", audioStreams)
+	}
+}
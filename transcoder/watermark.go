@@ -0,0 +1,107 @@
+package transcoder
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WatermarkPosition is the corner of the frame where a watermark is drawn.
+type WatermarkPosition int
+
+const (
+	WatermarkTopLeft WatermarkPosition = iota
+	WatermarkTopRight
+	WatermarkBottomLeft
+	WatermarkBottomRight
+)
+
+// watermarkMargin is the distance in pixels between the watermark and the edges of the frame.
+const watermarkMargin = 10
+
+// Watermark describes a logo or a text burned into the video, e.g. a station logo or "PREVIEW" on screeners.
+// Either ImagePath or Text is set; an image takes precedence. Scale is the height of the watermark
+// relative to the frame height (0.1 when zero) and Opacity its opacity from 0 to 1 (1 when zero).
+type Watermark struct {
+	ImagePath string
+	Text      string
+	Position  WatermarkPosition
+	Scale     float64
+	Opacity   float64
+}
+
+func (w *Watermark) scale() float64 {
+	if w.Scale <= 0 {
+		return 0.1
+	}
+	return w.Scale
+}
+
+func (w *Watermark) opacity() float64 {
+	if w.Opacity <= 0 || w.Opacity > 1 {
+		return 1
+	}
+	return w.Opacity
+}
+
+// overlayPosition returns the overlay filter x and y expressions of the position.
+func (p WatermarkPosition) overlayPosition() (x, y string) {
+	left, top := fmt.Sprint(watermarkMargin), fmt.Sprint(watermarkMargin)
+	right, bottom := fmt.Sprintf("W-w-%d", watermarkMargin), fmt.Sprintf("H-h-%d", watermarkMargin)
+	switch p {
+	case WatermarkTopRight:
+		return right, top
+	case WatermarkBottomLeft:
+		return left, bottom
+	case WatermarkBottomRight:
+		return right, bottom
+	default:
+		return left, top
+	}
+}
+
+// drawtextPosition returns the drawtext filter x and y expressions of the position.
+func (p WatermarkPosition) drawtextPosition() (x, y string) {
+	left, top := fmt.Sprint(watermarkMargin), fmt.Sprint(watermarkMargin)
+	right, bottom := fmt.Sprintf("w-tw-%d", watermarkMargin), fmt.Sprintf("h-th-%d", watermarkMargin)
+	switch p {
+	case WatermarkTopRight:
+		return right, top
+	case WatermarkBottomLeft:
+		return left, bottom
+	case WatermarkBottomRight:
+		return right, bottom
+	default:
+		return left, top
+	}
+}
+
+// watermarkFilter returns the filtergraph drawing the watermark over the [input] video into [output].
+// An image watermark is read from the ffmpeg input imageInput.
+func watermarkFilter(w *Watermark, input, output string, imageInput int) string {
+	if w.ImagePath != "" {
+		x, y := w.Position.overlayPosition()
+		return fmt.Sprintf("[%d:v]format=rgba,colorchannelmixer=aa=%.2f[wm]; [wm][%s]scale2ref=h=main_h*%.3f:w=oh*dar[wms][base]; [base][wms]overlay=%s:%s[%s]",
+			imageInput, w.opacity(), input, w.scale(), x, y, output)
+	}
+	x, y := w.Position.drawtextPosition()
+	return fmt.Sprintf("[%s]drawtext=expansion=none:text=%s:fontsize=h*%.3f:fontcolor=white@%.2f:x=%s:y=%s[%s]",
+		input, escapeDrawtext(w.Text), w.scale(), w.opacity(), x, y, output)
+}
+
+// escapeDrawtext escapes a text for use as a drawtext option value inside a filtergraph: once for the
+// filter options parser, then once more for the filtergraph parser.
+func escapeDrawtext(text string) string {
+	return escapeChars(escapeChars(text, `\':`), `\'[],;`)
+}
+
+// escapeChars prefixes with a backslash every occurrence in text of the given characters.
+func escapeChars(text, chars string) string {
+	var b strings.Builder
+	for _, r := range text {
+		if strings.ContainsRune(chars, r) {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
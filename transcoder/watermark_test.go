@@ -0,0 +1,111 @@
+package transcoder
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWatermarkFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		watermark Watermark
+		want      string
+	}{
+		{
+			name:      "image with defaults",
+			watermark: Watermark{ImagePath: "logo.png"},
+			want:      "[2:v]format=rgba,colorchannelmixer=aa=1.00[wm]; [wm][main]scale2ref=h=main_h*0.100:w=oh*dar[wms][base]; [base][wms]overlay=10:10[outv]",
+		},
+		{
+			name:      "image bottom right",
+			watermark: Watermark{ImagePath: "logo.png", Text: "ignored", Position: WatermarkBottomRight, Scale: 0.05, Opacity: 0.5},
+			want:      "[2:v]format=rgba,colorchannelmixer=aa=0.50[wm]; [wm][main]scale2ref=h=main_h*0.050:w=oh*dar[wms][base]; [base][wms]overlay=W-w-10:H-h-10[outv]",
+		},
+		{
+			name:      "text top right",
+			watermark: Watermark{Text: "PREVIEW", Position: WatermarkTopRight, Opacity: 0.3},
+			want:      "[main]drawtext=expansion=none:text=PREVIEW:fontsize=h*0.100:fontcolor=white@0.30:x=w-tw-10:y=10[outv]",
+		},
+		{
+			name:      "text bottom left",
+			watermark: Watermark{Text: "BingeMate", Position: WatermarkBottomLeft, Scale: 0.2, Opacity: 2},
+			want:      "[main]drawtext=expansion=none:text=BingeMate:fontsize=h*0.200:fontcolor=white@1.00:x=10:y=h-th-10[outv]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := watermarkFilter(&tt.watermark, "main", "outv", 2); got != tt.want {
+				t.Errorf("watermarkFilter() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeDrawtext(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{text: "PREVIEW", want: "PREVIEW"},
+		{text: "10:30", want: `10\\:30`},
+		{text: "l'intro", want: `l\\\'intro`},
+		{text: "[a,b;c]", want: `\[a\,b\;c\]`},
+		{text: `C:\logo`, want: `C\\:\\\\logo`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := escapeDrawtext(tt.text); got != tt.want {
+				t.Errorf("escapeDrawtext(%q) = %s, want %s", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeWatermark(t *testing.T) {
+	logo := filepath.Join(t.TempDir(), "logo.png")
+	tests := []struct {
+		name      string
+		watermark Watermark
+		wantInput bool
+		wantGraph string
+	}{
+		{
+			name:      "image",
+			watermark: Watermark{ImagePath: logo},
+			wantInput: true,
+			wantGraph: "concat=n=2:v=1[main]; [2:v]format=rgba",
+		},
+		{
+			name:      "text",
+			watermark: Watermark{Text: "PREVIEW"},
+			wantGraph: "concat=n=2:v=1[main]; [main]drawtext=",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ft := newFakeTools(t, sampleMovieProbes()...)
+			if _, _, err := transcodeSample(t, WithWatermark(tt.watermark)); err != nil {
+				t.Fatalf("ProcessFileTranscode() = %v", err)
+			}
+			call := ft.ffmpegCall(t, "index.m3u8")
+			if got := strings.Contains(call, "movie.mkv -i "+logo+" -filter_complex"); got != tt.wantInput {
+				t.Errorf("video ffmpeg run = %q, logo input %v, want %v", call, got, tt.wantInput)
+			}
+			if !strings.Contains(call, tt.wantGraph) || !strings.Contains(call, "[outv] -map [outv]") {
+				t.Errorf("video ffmpeg run = %q, want the %s watermark drawn into [outv]", call, tt.name)
+			}
+		})
+	}
+}
+
+func TestProcessFileTranscodeWithoutWatermark(t *testing.T) {
+	ft := newFakeTools(t, sampleMovieProbes()...)
+	if _, _, err := transcodeSample(t); err != nil {
+		t.Fatalf("ProcessFileTranscode() = %v", err)
+	}
+	call := ft.ffmpegCall(t, "index.m3u8")
+	if strings.Contains(call, "overlay") || strings.Contains(call, "drawtext") || !strings.Contains(call, "concat=n=2:v=1[outv] -map [outv]") {
+		t.Errorf("video ffmpeg run = %q, want no watermark", call)
+	}
+}